@@ -15,25 +15,188 @@ const (
 
 	// Entries is a constant used in HTTP GET query strings
 	Entries = "entries"
+
+	// Level is a constant used in HTTP GET query strings to specify the
+	// minimum level of daemon log entries
+	Level = "level"
+
+	// Project is a constant used in HTTP query strings to specify the project
+	// a request is scoped to
+	Project = "project"
+
+	// Command is a constant used in HTTP GET query strings, and may be
+	// provided multiple times to specify the arguments of a command
+	Command = "cmd"
+)
+
+const (
+	// ExecResize is the type of ExecMessages that resize the exec session's
+	// terminal
+	ExecResize = "resize"
+
+	// ExecExit is the type of the ExecMessage sent when an exec session ends
+	ExecExit = "exit"
 )
 
+// ExecMessage is a control message sent as text over exec websockets, which
+// otherwise carry terminal input and output as binary messages
+type ExecMessage struct {
+	Type string `json:"type"`
+
+	// Rows and Cols are set for resize messages
+	Rows uint `json:"rows,omitempty"`
+	Cols uint `json:"cols,omitempty"`
+
+	// ExitCode and Error are set for exit messages
+	ExitCode int    `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
 // UpRequest is the configurable body of a UP request to the daemon.
 // TODO: unify with configuration definitions
 type UpRequest struct {
-	Stream                 bool       `json:"stream"`
-	Project                string     `json:"project"`
-	BuildType              string     `json:"build_type"`
-	BuildFilePath          string     `json:"build_file_path"`
-	GitOptions             GitOptions `json:"git_options"`
-	WebHookSecret          string     `json:"webhook_secret"`
-	IntermediaryContainers []string   `json:"intermediary_containers"`
-	SlackNotificationURL   string     `json:"slack_notification_url"`
+	Stream                 bool              `json:"stream"`
+	Project                string            `json:"project"`
+	BuildType              string            `json:"build_type"`
+	BuildFilePath          string            `json:"build_file_path"`
+	BuildContext           string            `json:"build_context,omitempty"`
+	Image                  *Image            `json:"image,omitempty"`
+	BuildArgs              map[string]string `json:"build_args,omitempty"`
+	BuildArgsFromEnv       []string          `json:"build_args_from_env,omitempty"`
+	Target                 string            `json:"target,omitempty"`
+	Ports                  []string          `json:"ports,omitempty"`
+	Labels                 map[string]string `json:"labels,omitempty"`
+	Resources              *Resources        `json:"resources,omitempty"`
+	Compose                *Compose          `json:"compose,omitempty"`
+	DeployStrategy         string            `json:"deploy_strategy,omitempty"`
+	HealthCheck            *HealthCheck      `json:"health_check,omitempty"`
+	RestartPolicy          *RestartPolicy    `json:"restart_policy,omitempty"`
+	Hooks                  *Hooks            `json:"hooks,omitempty"`
+	GitOptions             GitOptions        `json:"git_options"`
+	WatchPaths             []string          `json:"watch_paths,omitempty"`
+	Previews               *Previews         `json:"previews,omitempty"`
+	Triggers               *Triggers         `json:"triggers,omitempty"`
+	WebHookSecret          string            `json:"webhook_secret"`
+	IntermediaryContainers []string          `json:"intermediary_containers"`
+	SlackNotificationURL   string            `json:"slack_notification_url"`
+}
+
+// Image declares a prebuilt image for the daemon to pull and deploy, used by
+// the "image" build type. Server, Username, and Password are only required for
+// private images.
+type Image struct {
+	Name     string `json:"name"`
+	Server   string `json:"server,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// Resources declares limits on the resources project containers and build
+// containers may use. Memory and MemorySwap are sizes such as "512m", and CPUs
+// is the number of CPUs containers may use. Zero values are not applied.
+type Resources struct {
+	Memory     string  `json:"memory,omitempty"`
+	MemorySwap string  `json:"memory_swap,omitempty"`
+	CPUShares  int64   `json:"cpu_shares,omitempty"`
+	CPUs       float64 `json:"cpus,omitempty"`
+	PidsLimit  int64   `json:"pids_limit,omitempty"`
+}
+
+// Compose configures docker-compose projects. Files are applied in order, so
+// that later files override earlier ones, and ProjectName overrides the compose
+// project name, which defaults to the project name.
+type Compose struct {
+	Files       []string `json:"files,omitempty"`
+	Profiles    []string `json:"profiles,omitempty"`
+	ProjectName string   `json:"project_name,omitempty"`
+}
+
+// HealthCheck configures how the daemon determines whether a deployment is
+// healthy. Type is one of "container", "http", or "tcp". Timeout and
+// GracePeriod are duration strings.
+type HealthCheck struct {
+	Type           string `json:"type"`
+	URL            string `json:"url,omitempty"`
+	ExpectedStatus int    `json:"expected_status,omitempty"`
+	Port           int    `json:"port,omitempty"`
+	Timeout        string `json:"timeout,omitempty"`
+	GracePeriod    string `json:"grace_period,omitempty"`
+}
+
+// RestartPolicy configures how the daemon handles project containers that stop
+// unexpectedly. Policy is one of "no", "on-failure", or "always". Backoff and
+// CrashLoopWindow are duration strings.
+type RestartPolicy struct {
+	Policy             string `json:"policy"`
+	MaxRetries         int    `json:"max_retries,omitempty"`
+	Backoff            string `json:"backoff,omitempty"`
+	CrashLoopThreshold int    `json:"crash_loop_threshold,omitempty"`
+	CrashLoopWindow    string `json:"crash_loop_window,omitempty"`
+}
+
+// Hook is a command run in a one-off container during a deploy, using the
+// freshly built project image. Service names the docker-compose service to run
+// the command in, and is required for docker-compose projects.
+type Hook struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+	Service string   `json:"service,omitempty"`
+}
+
+// Hooks declares commands to run during a deploy. PreDeploy hooks run after the
+// project is built but before it is started, and abort the deploy if they fail.
+// PostDeploy hooks run after the project is started.
+type Hooks struct {
+	PreDeploy  []Hook `json:"pre_deploy,omitempty"`
+	PostDeploy []Hook `json:"post_deploy,omitempty"`
+}
+
+// Triggers configures which pushes are deployed. Branches and Tags are glob
+// patterns of branch and tag names to deploy, and a push is not deployed if
+// its commit message contains any of the Skip directives.
+type Triggers struct {
+	Branches []string `json:"branches,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Skip     []string `json:"skip,omitempty"`
+}
+
+// Previews configures preview deployments of pull requests opened against a
+// deployment's branch. Previews publish ports on the deployment's host ports
+// offset by PortOffset plus the pull request number, and are given hostnames
+// of the form pr-<number>.<Domain> if Domain is set.
+type Previews struct {
+	Enabled    bool   `json:"enabled"`
+	Domain     string `json:"domain,omitempty"`
+	PortOffset int    `json:"port_offset,omitempty"`
 }
 
 // GitOptions represents GitHub-related deployment options
 type GitOptions struct {
 	RemoteURL string `json:"remote"`
 	Branch    string `json:"branch"`
+
+	// Ref, if set, pins the deployment to the given commit hash, tag, or
+	// branch name instead of tracking the tip of Branch
+	Ref string `json:"ref,omitempty"`
+
+	// Submodules enables recursively cloning and updating submodules, and LFS
+	// enables fetching Git LFS objects
+	Submodules bool `json:"submodules,omitempty"`
+	LFS        bool `json:"lfs,omitempty"`
+
+	// Depth, if set, limits fetched history to the given number of commits,
+	// and PruneTo, if set, prunes checked out files to the given paths
+	Depth   int      `json:"depth,omitempty"`
+	PruneTo []string `json:"prune_to,omitempty"`
+}
+
+// RollbackRequest is the body of a rollback request to the daemon. Either
+// Commit or Steps may be provided - if neither is set, the deployment is rolled
+// back to the previously deployed commit.
+type RollbackRequest struct {
+	Stream bool   `json:"stream"`
+	Commit string `json:"commit,omitempty"`
+	Steps  int    `json:"steps,omitempty"`
 }
 
 // UserRequest is used for logging in or modifying users
@@ -53,3 +216,12 @@ type EnvRequest struct {
 
 	Remove bool `json:"remove,omitempty"`
 }
+
+// GitCredentialsRequest represents a request to manage the credentials used to
+// access HTTPS git remotes
+type GitCredentialsRequest struct {
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	Remove bool `json:"remove,omitempty"`
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 )
 
 // BaseResponse is the underlying response structure to all responses.
@@ -97,6 +98,131 @@ type DeploymentStatus struct {
 	Containers           []string `json:"containers"`
 	BuildContainerActive bool     `json:"build_active"`
 
+	// Pinned is set if the deployment was deployed from a specific Ref, and
+	// does not track the tip of Branch
+	Pinned bool   `json:"pinned"`
+	Ref    string `json:"ref,omitempty"`
+
+	// Resources are the limits applied to project containers, if any
+	Resources *Resources `json:"resources,omitempty"`
+
+	// Failure is set if the deployment has failed since it was deployed, for
+	// example if a container is crash looping
+	Failure string `json:"failure,omitempty"`
+
 	// returns tag of latest version on dockerhub
 	NewVersionAvailable *string `json:"new_version_available"`
 }
+
+// DeploymentRecord describes a past deployment of the project, including
+// deploys that failed
+type DeploymentRecord struct {
+	CommitHash      string    `json:"commit_hash"`
+	Branch          string    `json:"branch"`
+	BuildType       string    `json:"build_type"`
+	ContainerID     string    `json:"container_id,omitempty"`
+	ContainerStatus string    `json:"container_status,omitempty"`
+	StartedAt       string    `json:"started_at,omitempty"`
+	DeployedAt      time.Time `json:"deployed_at"`
+	BuildID         string    `json:"build_id,omitempty"`
+
+	Hooks []HookResult `json:"hooks,omitempty"`
+
+	// Failed is set if the deploy did not complete, in which case Error
+	// describes why
+	Failed bool   `json:"failed,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BuildRecord describes a build of the project. Trigger is one of "up",
+// "webhook", "rollback", or "preview", and Status is one of "running",
+// "succeeded", "failed", or "interrupted" if the daemon stopped mid-build.
+// Size is the size of the saved output in bytes, which is Truncated if it
+// exceeds the daemon's limit.
+type BuildRecord struct {
+	ID         string     `json:"id"`
+	Project    string     `json:"project"`
+	Trigger    string     `json:"trigger"`
+	CommitHash string     `json:"commit_hash,omitempty"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Size       int64      `json:"size"`
+	Truncated  bool       `json:"truncated,omitempty"`
+}
+
+// DeploymentPlan describes what deploying a project would change
+type DeploymentPlan struct {
+	Project string `json:"project"`
+
+	// NewDeployment is set if the project has not been deployed yet, in which
+	// case nothing else is reported
+	NewDeployment bool `json:"new_deployment"`
+
+	CurrentCommit string          `json:"current_commit,omitempty"`
+	TargetRef     string          `json:"target_ref,omitempty"`
+	TargetCommit  string          `json:"target_commit,omitempty"`
+	Commits       []CommitSummary `json:"commits,omitempty"`
+	Files         []FileChange    `json:"files,omitempty"`
+
+	ConfigChanges []ConfigChange `json:"config_changes,omitempty"`
+	EnvAdded      []string       `json:"env_added,omitempty"`
+	EnvRemoved    []string       `json:"env_removed,omitempty"`
+
+	ContainersToStop []string `json:"containers_to_stop,omitempty"`
+}
+
+// CommitSummary describes a commit
+type CommitSummary struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+// FileChange describes a changed file. Action is one of "added", "deleted", or
+// "modified".
+type FileChange struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+}
+
+// ConfigChange describes a change to a deployment configuration field
+type ConfigChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// HookResult describes the outcome of a deploy hook. Stage is either
+// "pre_deploy" or "post_deploy".
+type HookResult struct {
+	Name     string `json:"name"`
+	Stage    string `json:"stage"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// DiskUsage describes the disk space used by Docker assets and persistent
+// project data on the remote. Total and Free describe the disk the persist
+// directory is on.
+type DiskUsage struct {
+	Images             DiskUsageEntry `json:"images"`
+	ArchivedContainers DiskUsageEntry `json:"archived_containers"`
+	Volumes            DiskUsageEntry `json:"volumes"`
+	BuildCache         DiskUsageEntry `json:"build_cache"`
+	Persist            DiskUsageEntry `json:"persist"`
+
+	Total uint64 `json:"total"`
+	Free  uint64 `json:"free"`
+}
+
+// DiskUsageEntry describes the disk space used by a kind of asset, in bytes.
+// Reclaimable is the space that could be freed without affecting active
+// deployments.
+type DiskUsageEntry struct {
+	Count       int   `json:"count"`
+	Size        int64 `json:"size"`
+	Reclaimable int64 `json:"reclaimable"`
+}
@@ -66,7 +66,12 @@ func (p *Project) ValidateVersion(v string) (warning string, err error) {
 type Profile struct {
 	Name      string     `toml:"name"`
 	Branch    string     `toml:"branch"`
+	Watch     []string   `toml:"watch,omitempty"`
 	Build     *Build     `toml:"build"`
+	Git       *Git       `toml:"git,omitempty"`
+	Hooks     *Hooks     `toml:"hooks,omitempty"`
+	Triggers  *Triggers  `toml:"triggers,omitempty"`
+	Previews  *Previews  `toml:"previews,omitempty"`
 	Notifiers *Notifiers `toml:"notifiers"`
 }
 
@@ -82,6 +87,13 @@ const (
 
 	// DockerCompose is used for docker-compose configurations
 	DockerCompose BuildType = "docker-compose"
+
+	// Image is used to deploy prebuilt images pulled from a registry
+	Image BuildType = "image"
+
+	// Buildpack is used for projects without Docker configuration, which are
+	// built using a Dockerfile generated for the project's language
+	Buildpack BuildType = "buildpack"
 )
 
 // AsBuildType casts given string as a BuildType, or returns an error
@@ -91,14 +103,209 @@ func AsBuildType(s string) (BuildType, error) {
 		return DockerCompose, nil
 	case string(Dockerfile):
 		return Dockerfile, nil
+	case string(Image):
+		return Image, nil
+	case string(Buildpack):
+		return Buildpack, nil
 	}
 	return "", fmt.Errorf("type '%s' is not a valid build type", s)
 }
 
+// DeployStrategy represents supported strategies for replacing an active
+// deployment
+type DeployStrategy string
+
+const (
+	// Recreate shuts down the active deployment before the new one is built
+	Recreate DeployStrategy = "recreate"
+
+	// BlueGreen builds and starts the new deployment alongside the active one,
+	// and only retires the active deployment once the new one is ready. This is
+	// only supported for Dockerfile, image, and buildpack projects that don't
+	// publish fixed host ports.
+	BlueGreen DeployStrategy = "blue-green"
+)
+
+// AsDeployStrategy casts given string as a DeployStrategy, or returns an
+// error. An empty string is treated as the default strategy, Recreate.
+func AsDeployStrategy(s string) (DeployStrategy, error) {
+	switch s {
+	case "", string(Recreate):
+		return Recreate, nil
+	case string(BlueGreen):
+		return BlueGreen, nil
+	}
+	return "", fmt.Errorf("'%s' is not a valid deploy strategy", s)
+}
+
+// HealthCheckType represents supported health checks
+type HealthCheckType string
+
+const (
+	// ContainerHealthCheck waits for project containers to report themselves
+	// as healthy, using their HEALTHCHECK configuration
+	ContainerHealthCheck HealthCheckType = "container"
+
+	// HTTPHealthCheck waits for a URL to respond with an expected status
+	HTTPHealthCheck HealthCheckType = "http"
+
+	// TCPHealthCheck waits for a port to accept connections
+	TCPHealthCheck HealthCheckType = "tcp"
+)
+
+// HealthCheck denotes how to determine whether a deployment is healthy. If the
+// check fails, the daemon rolls back to the previously deployed commit.
+type HealthCheck struct {
+	Type HealthCheckType `toml:"type"`
+
+	// URL and ExpectedStatus (default 200) are used by HTTP checks
+	URL            string `toml:"url,omitempty"`
+	ExpectedStatus int    `toml:"expected_status,omitempty"`
+
+	// Port is used by TCP checks
+	Port int `toml:"port,omitempty"`
+
+	// Timeout is a duration string, such as "30s" (default "1m")
+	Timeout string `toml:"timeout,omitempty"`
+
+	// GracePeriod is how long new containers without a HEALTHCHECK must stay
+	// up for during blue-green deploys, as a duration string (default "5s")
+	GracePeriod string `toml:"grace_period,omitempty"`
+}
+
+// RestartPolicyType represents supported restart policies
+type RestartPolicyType string
+
+const (
+	// RestartNo leaves containers stopped when they exit
+	RestartNo RestartPolicyType = "no"
+
+	// RestartOnFailure restarts containers that exit with a non-zero exit code
+	RestartOnFailure RestartPolicyType = "on-failure"
+
+	// RestartAlways restarts containers whenever they exit
+	RestartAlways RestartPolicyType = "always"
+)
+
+// RestartPolicy denotes how to handle project containers that stop
+// unexpectedly. Containers that exit too often are considered to be crash
+// looping, and are no longer restarted.
+type RestartPolicy struct {
+	Policy RestartPolicyType `toml:"policy"`
+
+	// MaxRetries limits how many times each container is restarted by the
+	// on-failure policy (default unlimited)
+	MaxRetries int `toml:"max_retries,omitempty"`
+
+	// Backoff is the delay before the first restart, which doubles with each
+	// subsequent exit, as a duration string such as "5s" (default "5s")
+	Backoff string `toml:"backoff,omitempty"`
+
+	// CrashLoopThreshold is how many times a container may exit within
+	// CrashLoopWindow before it is considered to be crash looping (default 5
+	// within "10m")
+	CrashLoopThreshold int    `toml:"crash_loop_threshold,omitempty"`
+	CrashLoopWindow    string `toml:"crash_loop_window,omitempty"`
+}
+
+// Hook denotes a command to run in a one-off container during a deploy, using
+// the freshly built project image and the project's environment variables
+type Hook struct {
+	Name    string   `toml:"name"`
+	Command []string `toml:"command"`
+
+	// Service is the docker-compose service to run the command in, and is
+	// required for docker-compose projects
+	Service string `toml:"service,omitempty"`
+}
+
+// Hooks denotes commands to run during a deploy
+type Hooks struct {
+	// PreDeploy hooks run after the project is built but before it is started,
+	// and abort the deploy if they fail - for example, database migrations
+	PreDeploy []Hook `toml:"pre_deploy,omitempty"`
+
+	// PostDeploy hooks run after the project is started - for example, cache
+	// invalidations
+	PostDeploy []Hook `toml:"post_deploy,omitempty"`
+}
+
+// Resources denotes limits on the resources project containers and build
+// containers may use. Unset limits are not applied.
+type Resources struct {
+	// Memory and MemorySwap are sizes such as "512m" or "1g". MemorySwap is
+	// the total of memory and swap, or "-1" for unlimited swap.
+	Memory     string `toml:"memory,omitempty"`
+	MemorySwap string `toml:"memory_swap,omitempty"`
+
+	// CPUShares is the relative weight of containers' CPU usage (default
+	// 1024), and CPUs is the number of CPUs containers may use, such as 1.5
+	CPUShares int64   `toml:"cpu_shares,omitempty"`
+	CPUs      float64 `toml:"cpus,omitempty"`
+
+	// PidsLimit is the maximum number of processes containers may run
+	PidsLimit int64 `toml:"pids_limit,omitempty"`
+}
+
+// Compose denotes how docker-compose projects are built and started
+type Compose struct {
+	// Files are the compose files to use, in order - later files override
+	// earlier ones, so environment-specific overrides can be listed after a
+	// shared base file. Defaults to the build file, or "docker-compose.yml".
+	Files []string `toml:"files,omitempty"`
+
+	// Profiles are the compose profiles to enable
+	Profiles []string `toml:"profiles,omitempty"`
+
+	// ProjectName overrides the compose project name, which defaults to the
+	// name of the project
+	ProjectName string `toml:"project_name,omitempty"`
+}
+
+// ImageSource denotes a prebuilt image to deploy, used by image builds
+type ImageSource struct {
+	// Name is the image to pull, such as "ubclaunchpad/inertia:latest"
+	Name string `toml:"name"`
+
+	// Server, Username, and PasswordEnv are used to authenticate with the
+	// registry for private images. PasswordEnv names a local environment
+	// variable containing the password or access token, so that it need not
+	// be committed to your project configuration.
+	Server      string `toml:"server,omitempty"`
+	Username    string `toml:"username,omitempty"`
+	PasswordEnv string `toml:"password_env,omitempty"`
+}
+
 // Build denotes build configuration
 type Build struct {
-	Type          BuildType `toml:"type"`
-	BuildFilePath string    `toml:"buildfile"`
+	Type          BuildType      `toml:"type"`
+	BuildFilePath string         `toml:"buildfile"`
+	Context       string         `toml:"context,omitempty"`
+	Image         *ImageSource   `toml:"image,omitempty"`
+	Strategy      DeployStrategy `toml:"strategy,omitempty"`
+	HealthCheck   *HealthCheck   `toml:"healthcheck,omitempty"`
+	Restart       *RestartPolicy `toml:"restart,omitempty"`
+
+	// BuildArgs are passed to Dockerfile builds. BuildArgsFromEnv names
+	// environment variables set on the remote, including encrypted ones, to
+	// pass as build args as well.
+	BuildArgs        map[string]string `toml:"build_args,omitempty"`
+	BuildArgsFromEnv []string          `toml:"build_args_from_env,omitempty"`
+
+	// Target is the stage of a multi-stage Dockerfile to build
+	Target string `toml:"target,omitempty"`
+
+	// Ports are "[ip:]hostPort:containerPort[/protocol]" mappings to publish
+	// instead of every port exposed by the project image. Ports and Labels
+	// are not used by docker-compose projects.
+	Ports  []string          `toml:"ports,omitempty"`
+	Labels map[string]string `toml:"labels,omitempty"`
+
+	// Resources limits the resources available to project and build containers
+	Resources *Resources `toml:"resources,omitempty"`
+
+	// Compose configures docker-compose projects
+	Compose *Compose `toml:"compose,omitempty"`
 
 	IntermediaryContainers []string `toml:"intermediary_containers"`
 }
@@ -152,6 +359,60 @@ func (p *Project) RemoveProfile(name string) bool {
 	return ok
 }
 
+// Git denotes how the daemon manages a profile's repository
+type Git struct {
+	// Submodules enables recursively cloning and updating submodules, using
+	// the same deploy key as the project repository
+	Submodules bool `toml:"submodules,omitempty"`
+
+	// LFS enables fetching Git LFS objects for the deployed commit
+	LFS bool `toml:"lfs,omitempty"`
+
+	// Depth limits fetched history to the given number of commits - 0 fetches
+	// all history
+	Depth int `toml:"depth,omitempty"`
+
+	// PruneTo removes files outside the given paths from the repository on the
+	// remote after each checkout. Files at the root of the repository are
+	// always kept.
+	PruneTo []string `toml:"prune_to,omitempty"`
+
+	// HTTPS enables cloning the project repository over HTTPS instead of SSH,
+	// using credentials set with 'inertia [remote] git-credentials set'
+	HTTPS bool `toml:"https,omitempty"`
+}
+
+// Triggers denotes which pushes to a profile's repository are deployed
+type Triggers struct {
+	// Branches are patterns of branch names, such as "release/*", that are
+	// deployed when pushed to. Defaults to the profile's branch.
+	Branches []string `toml:"branches,omitempty"`
+
+	// Tags are patterns of tag names, such as "v*.*.*", that are deployed when
+	// pushed
+	Tags []string `toml:"tags,omitempty"`
+
+	// Skip are directives that prevent a push from being deployed if the
+	// pushed commit's message contains one of them. Defaults to "[skip deploy]".
+	Skip []string `toml:"skip,omitempty"`
+}
+
+// Previews denotes how the daemon deploys previews of pull requests opened
+// against a profile's branch
+type Previews struct {
+	// Enabled turns on preview deployments
+	Enabled bool `toml:"enabled"`
+
+	// Domain, if set, gives each preview a hostname of the form
+	// pr-<number>.<domain>, for use with a reverse proxy
+	Domain string `toml:"domain,omitempty"`
+
+	// PortOffset is added to each published host port, along with the pull
+	// request number, to derive the ports previews are published on. Defaults
+	// to 10000.
+	PortOffset int `toml:"port_offset,omitempty"`
+}
+
 // Notifiers defines options for notifications on a profile
 type Notifiers struct {
 	SlackNotificationURL string `toml:"slack_notification_url"`
@@ -181,3 +181,24 @@ func TestProject_ValidateVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestAsDeployStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    DeployStrategy
+		wantErr bool
+	}{
+		{"default", "", Recreate, false},
+		{"recreate", "recreate", Recreate, false},
+		{"blue-green", "blue-green", BlueGreen, false},
+		{"invalid", "rolling", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AsDeployStrategy(tt.arg)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -32,8 +33,9 @@ type Client struct {
 	om  sync.Mutex
 	out io.Writer
 
-	ssh   runner.SSHSession
-	debug bool
+	ssh     runner.SSHSession
+	debug   bool
+	project string
 
 	Remote *cfg.Remote
 }
@@ -76,6 +78,9 @@ func (c *Client) WithWriter(out io.Writer) { c.out = out }
 // WithDebug sets the client's debug mode
 func (c *Client) WithDebug(debug bool) { c.debug = debug }
 
+// WithProject scopes the client's requests to the given project on the remote
+func (c *Client) WithProject(project string) { c.project = project }
+
 // GetSSHClient instantiates an SSH client for Inertia-related commands
 func (c *Client) GetSSHClient() (*SSHClient, error) {
 	if c.ssh == nil {
@@ -99,29 +104,141 @@ type UpRequest struct {
 	Project string
 	URL     string
 	Profile cfg.Profile
+
+	// Ref, if set, pins the deployment to the given commit hash, tag, or
+	// branch name instead of the tip of the profile's branch
+	Ref string
 }
 
-// Up brings the project up on the remote VPS instance specified
-// in the deployment object.
-func (c *Client) Up(ctx context.Context, req UpRequest) error {
+// buildUpRequest assembles the daemon request body for the given deployment
+func (c *Client) buildUpRequest(req UpRequest, stream bool) *api.UpRequest {
 	notif := req.Profile.Notifiers
 	if notif == nil {
 		notif = &cfg.Notifiers{}
 	}
+	strategy := req.Profile.Build.Strategy
+	if strategy == "" {
+		strategy = cfg.Recreate
+	}
+
+	var healthCheck *api.HealthCheck
+	if hc := req.Profile.Build.HealthCheck; hc != nil {
+		healthCheck = &api.HealthCheck{
+			Type:           string(hc.Type),
+			URL:            hc.URL,
+			ExpectedStatus: hc.ExpectedStatus,
+			Port:           hc.Port,
+			Timeout:        hc.Timeout,
+			GracePeriod:    hc.GracePeriod,
+		}
+	}
+
+	var restart *api.RestartPolicy
+	if rp := req.Profile.Build.Restart; rp != nil {
+		restart = &api.RestartPolicy{
+			Policy:             string(rp.Policy),
+			MaxRetries:         rp.MaxRetries,
+			Backoff:            rp.Backoff,
+			CrashLoopThreshold: rp.CrashLoopThreshold,
+			CrashLoopWindow:    rp.CrashLoopWindow,
+		}
+	}
+
+	var image *api.Image
+	if img := req.Profile.Build.Image; img != nil {
+		image = &api.Image{
+			Name:     img.Name,
+			Server:   img.Server,
+			Username: img.Username,
+		}
+		if img.PasswordEnv != "" {
+			image.Password = os.Getenv(img.PasswordEnv)
+		}
+	}
+
+	var resources *api.Resources
+	if r := req.Profile.Build.Resources; r != nil {
+		resources = (*api.Resources)(r)
+	}
+
+	var git cfg.Git
+	if g := req.Profile.Git; g != nil {
+		git = *g
+	}
+	var remoteURL = common.GetSSHRemoteURL(req.URL)
+	if git.HTTPS {
+		remoteURL = common.GetHTTPSRemoteURL(req.URL)
+	}
+
+	var compose *api.Compose
+	if c := req.Profile.Build.Compose; c != nil {
+		compose = (*api.Compose)(c)
+	}
+
+	var triggers *api.Triggers
+	if t := req.Profile.Triggers; t != nil {
+		triggers = (*api.Triggers)(t)
+	}
+
+	var previews *api.Previews
+	if p := req.Profile.Previews; p != nil {
+		previews = (*api.Previews)(p)
+	}
+
+	var hooks *api.Hooks
+	if h := req.Profile.Hooks; h != nil {
+		hooks = &api.Hooks{
+			PreDeploy:  make([]api.Hook, len(h.PreDeploy)),
+			PostDeploy: make([]api.Hook, len(h.PostDeploy)),
+		}
+		for i, hook := range h.PreDeploy {
+			hooks.PreDeploy[i] = api.Hook(hook)
+		}
+		for i, hook := range h.PostDeploy {
+			hooks.PostDeploy[i] = api.Hook(hook)
+		}
+	}
 
-	resp, err := c.post(ctx, "/up", &api.UpRequest{
-		Stream:        false,
-		Project:       req.Project,
-		WebHookSecret: c.Remote.Daemon.WebHookSecret,
-		BuildType:     string(req.Profile.Build.Type),
-		BuildFilePath: req.Profile.Build.BuildFilePath,
+	return &api.UpRequest{
+		Stream:           stream,
+		Project:          req.Project,
+		WebHookSecret:    c.Remote.Daemon.WebHookSecret,
+		BuildType:        string(req.Profile.Build.Type),
+		BuildFilePath:    req.Profile.Build.BuildFilePath,
+		BuildContext:     req.Profile.Build.Context,
+		Image:            image,
+		BuildArgs:        req.Profile.Build.BuildArgs,
+		BuildArgsFromEnv: req.Profile.Build.BuildArgsFromEnv,
+		Target:           req.Profile.Build.Target,
+		Ports:            req.Profile.Build.Ports,
+		Labels:           req.Profile.Build.Labels,
+		Resources:        resources,
+		Compose:          compose,
+		DeployStrategy:   string(strategy),
+		HealthCheck:      healthCheck,
+		RestartPolicy:    restart,
+		Hooks:            hooks,
+		WatchPaths:       req.Profile.Watch,
+		Triggers:         triggers,
+		Previews:         previews,
 		GitOptions: api.GitOptions{
-			RemoteURL: common.GetSSHRemoteURL(req.URL),
-			Branch:    req.Profile.Branch,
+			RemoteURL:  remoteURL,
+			Branch:     req.Profile.Branch,
+			Ref:        req.Ref,
+			Submodules: git.Submodules,
+			LFS:        git.LFS,
+			Depth:      git.Depth,
+			PruneTo:    git.PruneTo,
 		},
 		IntermediaryContainers: req.Profile.Build.IntermediaryContainers,
 		SlackNotificationURL:   notif.SlackNotificationURL,
-	})
+	}
+}
+
+// Up brings the project up on the remote VPS instance specified
+// in the deployment object.
+func (c *Client) Up(ctx context.Context, req UpRequest) error {
+	resp, err := c.post(ctx, "/up", c.buildUpRequest(req, false))
 	if err != nil {
 		return fmt.Errorf("failed to make request: %s", err.Error())
 	}
@@ -135,24 +252,110 @@ func (c *Client) Up(ctx context.Context, req UpRequest) error {
 
 // UpWithOutput blocks and streams 'up' output to the client's io.Writer
 func (c *Client) UpWithOutput(ctx context.Context, req UpRequest) error {
-	resp, err := c.post(ctx, "/up", &api.UpRequest{
-		Stream:        true,
-		Project:       req.Project,
-		WebHookSecret: c.Remote.Daemon.WebHookSecret,
-		BuildType:     string(req.Profile.Build.Type),
-		BuildFilePath: req.Profile.Build.BuildFilePath,
-		GitOptions: api.GitOptions{
-			RemoteURL: common.GetSSHRemoteURL(req.URL),
-			Branch:    req.Profile.Branch,
-		},
+	resp, err := c.post(ctx, "/up", c.buildUpRequest(req, true))
+	if err != nil {
+		return fmt.Errorf("failed to make request: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	return c.streamOutput(ctx, resp.Body)
+}
+
+// Plan describes what deploying the project with the given request would
+// change, without deploying anything
+func (c *Client) Plan(ctx context.Context, req UpRequest) (*api.DeploymentPlan, error) {
+	resp, err := c.post(ctx, "/up/plan", c.buildUpRequest(req, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %s", err.Error())
+	}
+
+	var plan api.DeploymentPlan
+	base, err := c.unmarshal(resp.Body, api.KV{Key: "plan", Value: &plan})
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err.Error())
+	}
+
+	return &plan, base.Error()
+}
+
+// History retrieves records of past deployments on the remote, most recent
+// first
+func (c *Client) History(ctx context.Context) ([]api.DeploymentRecord, error) {
+	resp, err := c.get(ctx, "/history", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %s", err.Error())
+	}
+
+	var history = make([]api.DeploymentRecord, 0)
+	base, err := c.unmarshal(resp.Body, api.KV{Key: "history", Value: &history})
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err.Error())
+	}
+
+	return history, base.Error()
+}
+
+// RollbackRequest declares parameters for rolling back a deployment. If
+// Commit is not provided, the deployment is rolled back by Steps deployments.
+type RollbackRequest struct {
+	Commit string
+	Steps  int
+}
+
+// Rollback redeploys a previously deployed commit on the remote
+func (c *Client) Rollback(ctx context.Context, req RollbackRequest) error {
+	resp, err := c.post(ctx, "/rollback", &api.RollbackRequest{
+		Stream: false,
+		Commit: req.Commit,
+		Steps:  req.Steps,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make request: %s", err.Error())
+	}
+	base, err := c.unmarshal(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response: %s", err.Error())
+	}
+	return base.Error()
+}
+
+// RollbackWithOutput blocks and streams 'rollback' output to the client's
+// io.Writer
+func (c *Client) RollbackWithOutput(ctx context.Context, req RollbackRequest) error {
+	resp, err := c.post(ctx, "/rollback", &api.RollbackRequest{
+		Stream: true,
+		Commit: req.Commit,
+		Steps:  req.Steps,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to make request: %s", err.Error())
 	}
 	defer resp.Body.Close()
+	return c.streamOutput(ctx, resp.Body)
+}
 
+// CancelDeploy aborts the in-progress deploy on the remote, as well as any
+// deploy waiting to start
+func (c *Client) CancelDeploy(ctx context.Context) error {
+	resp, err := c.post(ctx, "/deploy/cancel", nil)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %s", err.Error())
+	}
+	base, err := c.unmarshal(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response: %s", err.Error())
+	}
+	return base.Error()
+}
+
+// streamOutput blocks and writes lines from the given reader to the client's
+// io.Writer until an error occurs or the context is cancelled
+func (c *Client) streamOutput(ctx context.Context, r io.Reader) error {
 	// read until error
-	var scan = bufio.NewScanner(resp.Body)
+	var scan = bufio.NewScanner(r)
 	var errC = make(chan error, 1)
 	go func() {
 		for scan.Scan() {
@@ -211,6 +414,79 @@ func (c *Client) Prune(ctx context.Context) error {
 	return base.Error()
 }
 
+// DiskUsage retrieves the disk space used by Docker assets and persistent
+// project data on the remote
+func (c *Client) DiskUsage(ctx context.Context) (*api.DiskUsage, error) {
+	resp, err := c.get(ctx, "/disk", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %s", err.Error())
+	}
+
+	var usage = &api.DiskUsage{}
+	base, err := c.unmarshal(resp.Body, api.KV{Key: "disk", Value: usage})
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err.Error())
+	}
+
+	return usage, base.Error()
+}
+
+// Builds lists recorded builds of the project on the remote, most recent first
+func (c *Client) Builds(ctx context.Context) ([]api.BuildRecord, error) {
+	resp, err := c.get(ctx, "/builds", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %s", err.Error())
+	}
+
+	var builds = make([]api.BuildRecord, 0)
+	base, err := c.unmarshal(resp.Body, api.KV{Key: "builds", Value: &builds})
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err.Error())
+	}
+
+	return builds, base.Error()
+}
+
+// BuildLogs writes the output of the given build to the client's io.Writer.
+// If follow is set, the output of a running build is written as it happens,
+// until the build finishes or the context is cancelled.
+func (c *Client) BuildLogs(ctx context.Context, id string, follow bool) error {
+	resp, err := c.get(ctx, "/builds/"+url.PathEscape(id)+"/logs", map[string]string{
+		api.Stream: strconv.FormatBool(follow),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make request: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		base, err := c.unmarshal(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %s", err.Error())
+		}
+		return base.Error()
+	}
+
+	var errC = make(chan error, 1)
+	go func() {
+		c.om.Lock()
+		_, err := io.Copy(c.out, resp.Body)
+		c.om.Unlock()
+		errC <- err
+	}()
+	select {
+	case <-ctx.Done():
+		c.debugf("context cancelled, closing connection")
+		return nil
+	case err := <-errC:
+		if err != nil {
+			return fmt.Errorf("error occured while reading output: %s", err.Error())
+		}
+		return nil
+	}
+}
+
 // Down brings the project down on the remote VPS instance specified
 // in the configuration object.
 func (c *Client) Down(ctx context.Context) error {
@@ -228,6 +504,37 @@ func (c *Client) Down(ctx context.Context) error {
 	return base.Error()
 }
 
+// RestartContainer restarts the named project container on the remote
+func (c *Client) RestartContainer(ctx context.Context, container string) error {
+	return c.controlContainer(ctx, container, "restart")
+}
+
+// StopContainer stops the named project container on the remote. The container
+// is not restarted by the project's restart policy.
+func (c *Client) StopContainer(ctx context.Context, container string) error {
+	return c.controlContainer(ctx, container, "stop")
+}
+
+// StartContainer starts the named stopped project container on the remote
+func (c *Client) StartContainer(ctx context.Context, container string) error {
+	return c.controlContainer(ctx, container, "start")
+}
+
+// controlContainer performs the given action on the named project container
+func (c *Client) controlContainer(ctx context.Context, container, action string) error {
+	resp, err := c.post(ctx, "/containers/"+
+		url.PathEscape(strings.TrimPrefix(container, "/"))+"/"+action, nil)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %s", err.Error())
+	}
+	base, err := c.unmarshal(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response: %s", err.Error())
+	}
+	return base.Error()
+}
+
 // Status lists the currently active containers on the remote VPS instance
 func (c *Client) Status(ctx context.Context) (*api.DeploymentStatus, error) {
 	resp, err := c.get(ctx, "/status", nil)
@@ -267,6 +574,9 @@ func (c *Client) Reset(ctx context.Context) error {
 type LogsRequest struct {
 	Container string
 	Entries   int
+	// Level is the minimum level of entries to retrieve, and is only
+	// supported for daemon logs
+	Level string
 }
 
 // Logs get logs of given container
@@ -275,6 +585,9 @@ func (c *Client) Logs(ctx context.Context, req LogsRequest) ([]string, error) {
 	if req.Entries > 0 {
 		reqContent[api.Entries] = strconv.Itoa(req.Entries)
 	}
+	if req.Level != "" {
+		reqContent[api.Level] = req.Level
+	}
 
 	resp, err := c.get(ctx, "/logs", reqContent)
 	if err != nil {
@@ -312,6 +625,12 @@ func (c *Client) LogsWithOutput(ctx context.Context, req LogsRequest) error {
 	if req.Entries > 0 {
 		params[api.Entries] = strconv.Itoa(req.Entries)
 	}
+	if req.Level != "" {
+		params[api.Level] = req.Level
+	}
+	if c.project != "" {
+		params[api.Project] = c.project
+	}
 	encodeQuery(url, params)
 
 	// Set up authorization
@@ -360,6 +679,137 @@ func (c *Client) LogsWithOutput(ctx context.Context, req LogsRequest) error {
 	}
 }
 
+// ExecRequest denotes parameters for running a command in a container
+type ExecRequest struct {
+	Container string
+	Command   []string
+}
+
+// TerminalSize denotes the dimensions of a terminal
+type TerminalSize struct {
+	Rows uint
+	Cols uint
+}
+
+// Exec runs a command in a project container with a TTY attached, forwarding
+// input from in and terminal size changes from resize, and writing output to
+// out. It blocks until the command exits, and returns its exit code.
+func (c *Client) Exec(
+	ctx context.Context,
+	req ExecRequest,
+	in io.Reader,
+	out io.Writer,
+	resize <-chan TerminalSize,
+) (int, error) {
+	addr, err := c.Remote.DaemonAddr()
+	if err != nil {
+		return 0, err
+	}
+	host, err := url.Parse(addr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid daemon address: %s", err.Error())
+	}
+
+	// Set up request - commands may have multiple arguments
+	var url = &url.URL{Scheme: "wss", Host: host.Host, Path: "/exec"}
+	var params = url.Query()
+	params.Set(api.Container, req.Container)
+	for _, arg := range req.Command {
+		params.Add(api.Command, arg)
+	}
+	if c.project != "" {
+		params.Set(api.Project, c.project)
+	}
+	url.RawQuery = params.Encode()
+
+	// Set up authorization
+	var header = http.Header{}
+	header.Set("Authorization", "Bearer "+c.Remote.Daemon.Token)
+
+	// set up websocket connection
+	c.debugf("request constructed: %s (authorized: %v, verified: %v)",
+		url.String(), c.Remote.Daemon.Token != "", c.Remote.Daemon.VerifySSL)
+	socket, resp, err := buildWebSocketDialer(c.Remote.Daemon.VerifySSL).
+		DialContext(ctx, url.String(), header)
+	if err == websocket.ErrBadHandshake {
+		return 0, fmt.Errorf("websocket handshake failed with status %d", resp.StatusCode)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to daemon: %s", err.Error())
+	}
+	defer socket.Close()
+	c.debugf("websocket connection established")
+
+	// forward input and resize events - websocket connections only support
+	// one concurrent writer
+	var wm sync.Mutex
+	go func() {
+		var buf = make([]byte, 1024)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				wm.Lock()
+				werr := socket.WriteMessage(websocket.BinaryMessage, buf[:n])
+				wm.Unlock()
+				if werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		for size := range resize {
+			wm.Lock()
+			err := socket.WriteJSON(api.ExecMessage{Type: api.ExecResize, Rows: size.Rows, Cols: size.Cols})
+			wm.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// read output until the command exits
+	var (
+		exitC = make(chan api.ExecMessage, 1)
+		errC  = make(chan error, 1)
+	)
+	go func() {
+		for {
+			kind, msg, err := socket.ReadMessage()
+			if err != nil {
+				errC <- fmt.Errorf("error occured while reading from socket: %s", err.Error())
+				return
+			}
+			if kind == websocket.BinaryMessage {
+				out.Write(msg)
+				continue
+			}
+			var ctl api.ExecMessage
+			if err := json.Unmarshal(msg, &ctl); err == nil && ctl.Type == api.ExecExit {
+				exitC <- ctl
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		c.debugf("context cancelled, closing connection")
+		return 0, ctx.Err()
+	case err := <-errC:
+		c.debugf("error received: %s", err.Error())
+		return 0, err
+	case exit := <-exitC:
+		if exit.Error != "" {
+			return exit.ExitCode, errors.New(exit.Error)
+		}
+		return exit.ExitCode, nil
+	}
+}
+
 // UpdateEnv updates environment variable
 func (c *Client) UpdateEnv(ctx context.Context, name, value string, encrypt, remove bool) error {
 	resp, err := c.post(ctx, "/env", api.EnvRequest{
@@ -395,6 +845,43 @@ func (c *Client) ListEnv(ctx context.Context) ([]string, error) {
 	return variables, base.Error()
 }
 
+// UpdateGitCredentials updates or removes the credentials used to access HTTPS
+// git remotes on the remote
+func (c *Client) UpdateGitCredentials(ctx context.Context, username, token string, remove bool) error {
+	resp, err := c.post(ctx, "/git-credentials", api.GitCredentialsRequest{
+		Username: username, Token: token, Remove: remove,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to make request: %s", err.Error())
+	}
+
+	base, err := c.unmarshal(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response: %s", err.Error())
+	}
+
+	return base.Error()
+}
+
+// GetGitCredentials retrieves the username of the credentials used to access
+// HTTPS git remotes on the remote. Stored tokens are never returned.
+func (c *Client) GetGitCredentials(ctx context.Context) (string, error) {
+	resp, err := c.get(ctx, "/git-credentials", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %s", err.Error())
+	}
+
+	var username string
+	base, err := c.unmarshal(resp.Body, api.KV{Key: "username", Value: &username})
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %s", err.Error())
+	}
+
+	return username, base.Error()
+}
+
 func (c *Client) do(req *http.Request) (*http.Response, error) {
 	resp, err := buildHTTPSClient(c.Remote.Daemon.VerifySSL).Do(req)
 	if err != nil {
@@ -466,6 +953,9 @@ func (c *Client) buildRequest(
 		return nil, fmt.Errorf("invalid url configuration: %s", err.Error())
 	}
 	url.Path = path.Join(url.Path, endpoint)
+	if c.project != "" {
+		encodeQuery(url, map[string]string{api.Project: c.project})
+	}
 
 	// Assemble request
 	req, err := http.NewRequest(method, url.String(), payload)
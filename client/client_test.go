@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -107,6 +108,8 @@ func TestClient_Up(t *testing.T) {
 		assert.Equal(t, "arjan", upReq.WebHookSecret)
 		assert.Equal(t, "test_project", upReq.Project)
 		assert.Equal(t, "docker-compose", upReq.BuildType)
+		assert.Equal(t, "recreate", upReq.DeployStrategy)
+		assert.Equal(t, "v1.0.0", upReq.GitOptions.Ref)
 
 		// Check correct endpoint called
 		assert.Equal(t, "/up", r.URL.Path)
@@ -123,7 +126,77 @@ func TestClient_Up(t *testing.T) {
 		Build: &cfg.Build{
 			Type: cfg.DockerCompose,
 		},
-	}}))
+	}, "v1.0.0"}))
+}
+
+func TestClient_buildUpRequest(t *testing.T) {
+	os.Setenv("INERTIA_TEST_REGISTRY_PASSWORD", "hunter2")
+	defer os.Unsetenv("INERTIA_TEST_REGISTRY_PASSWORD")
+
+	var d = &Client{Remote: &cfg.Remote{Daemon: &cfg.Daemon{}}}
+	var req = d.buildUpRequest(UpRequest{"test_project", "myremote.git", cfg.Profile{
+		Build: &cfg.Build{
+			Type: cfg.Image,
+			Image: &cfg.ImageSource{
+				Name:        "ubclaunchpad/inertia:latest",
+				Username:    "bob",
+				PasswordEnv: "INERTIA_TEST_REGISTRY_PASSWORD",
+			},
+		},
+	}, ""}, false)
+	assert.Equal(t, "image", req.BuildType)
+	assert.Equal(t, &api.Image{
+		Name:     "ubclaunchpad/inertia:latest",
+		Username: "bob",
+		Password: "hunter2",
+	}, req.Image)
+
+	req = d.buildUpRequest(UpRequest{"test_project", "myremote.git", cfg.Profile{
+		Build: &cfg.Build{
+			Type:             cfg.Dockerfile,
+			Context:          "services/api",
+			BuildArgs:        map[string]string{"VERSION": "1.0"},
+			BuildArgsFromEnv: []string{"NPM_TOKEN"},
+			Target:           "release",
+			Ports:            []string{"80:8080"},
+			Labels:           map[string]string{"team": "launchpad"},
+			Resources:        &cfg.Resources{Memory: "512m", CPUs: 1.5},
+			Restart:          &cfg.RestartPolicy{Policy: cfg.RestartOnFailure, MaxRetries: 3},
+			Compose: &cfg.Compose{
+				Files:    []string{"docker-compose.yml", "docker-compose.staging.yml"},
+				Profiles: []string{"workers"},
+			},
+		},
+		Git:      &cfg.Git{Submodules: true, LFS: true, Depth: 1, PruneTo: []string{"services/api"}},
+		Watch:    []string{"services/api", "libs"},
+		Previews: &cfg.Previews{Enabled: true, Domain: "preview.example.com"},
+		Triggers: &cfg.Triggers{Branches: []string{"master", "release/*"}, Tags: []string{"v*"}},
+	}, ""}, false)
+	assert.Equal(t, map[string]string{"VERSION": "1.0"}, req.BuildArgs)
+	assert.Equal(t, []string{"NPM_TOKEN"}, req.BuildArgsFromEnv)
+	assert.Equal(t, "release", req.Target)
+	assert.Equal(t, []string{"80:8080"}, req.Ports)
+	assert.Equal(t, map[string]string{"team": "launchpad"}, req.Labels)
+	assert.Equal(t, &api.Resources{Memory: "512m", CPUs: 1.5}, req.Resources)
+	assert.Equal(t, &api.RestartPolicy{Policy: "on-failure", MaxRetries: 3}, req.RestartPolicy)
+	assert.Equal(t, &api.Compose{
+		Files:    []string{"docker-compose.yml", "docker-compose.staging.yml"},
+		Profiles: []string{"workers"},
+	}, req.Compose)
+	assert.True(t, req.GitOptions.Submodules)
+	assert.True(t, req.GitOptions.LFS)
+	assert.Equal(t, "services/api", req.BuildContext)
+	assert.Equal(t, &api.Previews{Enabled: true, Domain: "preview.example.com"}, req.Previews)
+	assert.Equal(t, []string{"services/api", "libs"}, req.WatchPaths)
+	assert.Equal(t, &api.Triggers{Branches: []string{"master", "release/*"}, Tags: []string{"v*"}}, req.Triggers)
+	assert.Equal(t, 1, req.GitOptions.Depth)
+	assert.Equal(t, []string{"services/api"}, req.GitOptions.PruneTo)
+
+	req = d.buildUpRequest(UpRequest{"test_project", "git@github.com:ubclaunchpad/inertia.git", cfg.Profile{
+		Build: &cfg.Build{Type: cfg.DockerCompose},
+		Git:   &cfg.Git{HTTPS: true},
+	}, ""}, false)
+	assert.Equal(t, "https://github.com/ubclaunchpad/inertia.git", req.GitOptions.RemoteURL)
 }
 
 func TestClient_UpWithOutput(t *testing.T) {
@@ -150,11 +223,95 @@ func TestClient_UpWithOutput(t *testing.T) {
 		Build: &cfg.Build{
 			Type: cfg.DockerCompose,
 		},
-	}}))
+	}, ""}))
 	assert.Contains(t, buf.String(), "hello\nworld")
 	assert.Contains(t, buf.String(), "chicken rice")
 }
 
+func TestClient_Plan(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "POST", r.Method)
+
+		// Check correct endpoint called
+		assert.Equal(t, "/up/plan", r.URL.Path)
+
+		// Check request body
+		var upReq api.UpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&upReq))
+		assert.Equal(t, "v1.0.0", upReq.GitOptions.Ref)
+		assert.False(t, upReq.Stream)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		render.Render(w, r, res.MsgOK("deployment plan generated",
+			"plan", api.DeploymentPlan{TargetCommit: "abcde"}))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	plan, err := d.Plan(context.Background(), UpRequest{"test_project", "myremote.git", cfg.Profile{
+		Build: &cfg.Build{
+			Type: cfg.DockerCompose,
+		},
+	}, "v1.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "abcde", plan.TargetCommit)
+}
+
+func TestClient_History(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "GET", r.Method)
+
+		// Check correct endpoint called
+		assert.Equal(t, "/history", r.URL.Path)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		render.Render(w, r, res.MsgOK("deployment history retrieved",
+			"history", []api.DeploymentRecord{{CommitHash: "abcde"}}))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	history, err := d.History(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, "abcde", history[0].CommitHash)
+	}
+}
+
+func TestClient_Rollback(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "POST", r.Method)
+
+		// Check correct endpoint called
+		assert.Equal(t, "/rollback", r.URL.Path)
+
+		// Check request body
+		var rollbackReq api.RollbackRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&rollbackReq))
+		assert.Equal(t, "abcde", rollbackReq.Commit)
+		assert.False(t, rollbackReq.Stream)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		render.Render(w, r, res.Msg("Project rollback initiated!", http.StatusCreated))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	assert.NoError(t, d.Rollback(context.Background(), RollbackRequest{Commit: "abcde"}))
+}
+
 func TestClient_Prune(t *testing.T) {
 	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -175,6 +332,134 @@ func TestClient_Prune(t *testing.T) {
 	assert.NoError(t, d.Prune(context.Background()))
 }
 
+func TestClient_DiskUsage(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "GET", r.Method)
+
+		// Check correct endpoint called
+		assert.Equal(t, "/disk", r.URL.Path)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		render.Render(w, r, res.MsgOK("disk usage retrieved",
+			"disk", api.DiskUsage{
+				Images: api.DiskUsageEntry{Count: 3, Size: 1024, Reclaimable: 512},
+				Free:   2048,
+			}))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	usage, err := d.DiskUsage(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, api.DiskUsageEntry{Count: 3, Size: 1024, Reclaimable: 512}, usage.Images)
+	assert.Equal(t, uint64(2048), usage.Free)
+}
+
+func TestClient_Builds(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "GET", r.Method)
+
+		// Check correct endpoint called
+		assert.Equal(t, "/builds", r.URL.Path)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		render.Render(w, r, res.MsgOK("builds retrieved",
+			"builds", []api.BuildRecord{{ID: "20200101-000000-abcd", Status: "succeeded"}}))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	builds, err := d.Builds(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, builds, 1) {
+		assert.Equal(t, "20200101-000000-abcd", builds[0].ID)
+	}
+}
+
+func TestClient_BuildLogs(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "GET", r.Method)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/builds/20200101-000000-abcd/logs":
+			assert.Equal(t, "true", r.URL.Query().Get(api.Stream))
+			w.Write([]byte("building project\n"))
+		default:
+			render.Render(w, r, res.ErrNotFound("build not found"))
+		}
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	var out bytes.Buffer
+	d.WithWriter(&out)
+	assert.NoError(t, d.BuildLogs(context.Background(), "20200101-000000-abcd", true))
+	assert.Contains(t, out.String(), "building project\n")
+
+	err := d.BuildLogs(context.Background(), "20200101-000000-ffff", false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "build not found")
+	}
+}
+
+func TestClient_CancelDeploy(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Check request method
+		assert.Equal(t, "POST", r.Method)
+
+		// Check correct endpoint called
+		assert.Equal(t, "/deploy/cancel", r.URL.Path)
+
+		// Check auth
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+		render.Render(w, r, res.MsgOK("deploy cancelled"))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	assert.NoError(t, d.CancelDeploy(context.Background()))
+}
+
+func TestClient_ControlContainer(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   func(d *Client) error
+		wantPath string
+	}{
+		{"restart", func(d *Client) error { return d.RestartContainer(context.Background(), "/web") }, "/containers/web/restart"},
+		{"stop", func(d *Client) error { return d.StopContainer(context.Background(), "web") }, "/containers/web/stop"},
+		{"start", func(d *Client) error { return d.StartContainer(context.Background(), "web") }, "/containers/web/start"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "POST", r.Method)
+				assert.Equal(t, tt.wantPath, r.URL.Path)
+				assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+				render.Render(w, r, res.MsgOK("container "+tt.name+" succeeded"))
+			}))
+			defer testServer.Close()
+
+			var d = newMockClient(t, testServer)
+			assert.NoError(t, tt.action(d))
+		})
+	}
+}
+
 func TestClient_Down(t *testing.T) {
 	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -227,6 +512,28 @@ func TestClient_Status(t *testing.T) {
 	})
 }
 
+func TestClient_WithProject(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Check project scope is added alongside other queries
+		assert.Equal(t, "wow", r.URL.Query().Get(api.Project))
+		if r.URL.Query().Get("badge") != "" {
+			assert.Equal(t, "true", r.URL.Query().Get("badge"))
+		}
+		render.Render(w, r, res.MsgOK("status retrieved",
+			"status", api.DeploymentStatus{Branch: "amazing_test"}))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	d.WithProject("wow")
+	resp, err := d.get(context.Background(), "/status", map[string]string{"badge": "true"})
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = d.Status(context.Background())
+	assert.NoError(t, err)
+}
+
 func TestClient_Reset(t *testing.T) {
 	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -261,6 +568,7 @@ func TestClient_Logs(t *testing.T) {
 		q := r.URL.Query()
 		assert.Equal(t, "docker-compose", q.Get(api.Container))
 		assert.Equal(t, "10", q.Get(api.Entries))
+		assert.Equal(t, "warn", q.Get(api.Level))
 
 		// Check auth
 		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
@@ -272,7 +580,7 @@ func TestClient_Logs(t *testing.T) {
 	defer testServer.Close()
 
 	var d = newMockClient(t, testServer)
-	logs, err := d.Logs(context.Background(), LogsRequest{"docker-compose", 10})
+	logs, err := d.Logs(context.Background(), LogsRequest{"docker-compose", 10, "warn"})
 	assert.NoError(t, err)
 	assert.Equal(t, []string{"hello", "world"}, logs)
 }
@@ -293,6 +601,7 @@ func TestClient_LogsWithOutput(t *testing.T) {
 			assert.Equal(t, "docker-compose", q.Get(api.Container))
 			assert.Equal(t, "true", q.Get(api.Stream))
 			assert.Equal(t, "10", q.Get(api.Entries))
+			assert.Equal(t, "warn", q.Get(api.Level))
 
 			// Check auth
 			assert.Equal(t, "Bearer "+fakeAuth, req.Header.Get("Authorization"))
@@ -315,7 +624,7 @@ func TestClient_LogsWithOutput(t *testing.T) {
 			time.Sleep(1 * time.Second)
 			cancel()
 		}()
-		assert.NoError(t, d.LogsWithOutput(ctx, LogsRequest{"docker-compose", 10}))
+		assert.NoError(t, d.LogsWithOutput(ctx, LogsRequest{"docker-compose", 10, "warn"}))
 		assert.Contains(t, buf.String(), "hello world")
 	})
 
@@ -325,7 +634,7 @@ func TestClient_LogsWithOutput(t *testing.T) {
 		testServer.Close()
 
 		var d = newMockClient(t, testServer)
-		var err = d.LogsWithOutput(context.Background(), LogsRequest{"docker-compose", 10})
+		var err = d.LogsWithOutput(context.Background(), LogsRequest{"docker-compose", 10, "warn"})
 		assert.Error(t, err)
 		assert.True(t,
 			strings.Contains(err.Error(), "connect: connection refused") ||
@@ -333,6 +642,55 @@ func TestClient_LogsWithOutput(t *testing.T) {
 	})
 }
 
+func TestClient_Exec(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, "/exec", req.URL.Path)
+		var q = req.URL.Query()
+		assert.Equal(t, "web", q.Get(api.Container))
+		assert.Equal(t, []string{"ls", "-la"}, q[api.Command])
+		assert.Equal(t, "Bearer "+fakeAuth, req.Header.Get("Authorization"))
+
+		var socketUpgrader = websocket.Upgrader{}
+		socket, err := socketUpgrader.Upgrade(rw, req, nil)
+		assert.NoError(t, err)
+		defer socket.Close()
+
+		// expect a resize, then input
+		var resize api.ExecMessage
+		assert.NoError(t, socket.ReadJSON(&resize))
+		assert.Equal(t, api.ExecMessage{Type: api.ExecResize, Rows: 24, Cols: 80}, resize)
+		kind, input, err := socket.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, websocket.BinaryMessage, kind)
+		assert.Equal(t, "exit\n", string(input))
+
+		assert.NoError(t, socket.WriteMessage(websocket.BinaryMessage, []byte("goodbye")))
+		assert.NoError(t, socket.WriteJSON(api.ExecMessage{Type: api.ExecExit, ExitCode: 3}))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	var (
+		out    = &bytes.Buffer{}
+		resize = make(chan TerminalSize, 1)
+	)
+	resize <- TerminalSize{Rows: 24, Cols: 80}
+	// wait for the resize to be sent before sending input
+	var in, inW = io.Pipe()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		inW.Write([]byte("exit\n"))
+	}()
+	code, err := d.Exec(context.Background(), ExecRequest{
+		Container: "web",
+		Command:   []string{"ls", "-la"},
+	}, in, out, resize)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "goodbye", out.String())
+}
+
 func TestClient_UpdateEnv(t *testing.T) {
 	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -376,6 +734,39 @@ func TestClient_ListEnv(t *testing.T) {
 	assert.Equal(t, []string{"hello", "world"}, envs)
 }
 
+func TestClient_UpdateGitCredentials(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/git-credentials", r.URL.Path)
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+
+		var req api.GitCredentialsRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, api.GitCredentialsRequest{Username: "bob", Token: "sekret"}, req)
+		render.Render(w, r, res.Msg("git credentials updated", http.StatusAccepted))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	assert.NoError(t, d.UpdateGitCredentials(context.Background(), "bob", "sekret", false))
+}
+
+func TestClient_GetGitCredentials(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/git-credentials", r.URL.Path)
+		assert.Equal(t, "Bearer "+fakeAuth, r.Header.Get("Authorization"))
+		render.Render(w, r, res.Msg("git credentials retrieved", http.StatusOK,
+			"username", "bob"))
+	}))
+	defer testServer.Close()
+
+	var d = newMockClient(t, testServer)
+	username, err := d.GetGitCredentials(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", username)
+}
+
 func TestClient_Token(t *testing.T) {
 	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
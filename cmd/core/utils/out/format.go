@@ -2,6 +2,10 @@ package out
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
 
 	"github.com/ubclaunchpad/inertia/api"
 	"github.com/ubclaunchpad/inertia/cfg"
@@ -11,6 +15,10 @@ const (
 	msgBuildInProgress    = "It appears that your build is still in progress."
 	msgNoContainersActive = "No containers are active."
 	msgNoDeployment       = "No deployment found - try running 'inertia [remote] up'"
+	msgNoHistory          = "No deployments recorded yet."
+	msgNoBuilds           = "No builds recorded yet."
+	msgNewDeployment      = "No deployment found - the project will be cloned, built, and started from scratch."
+	msgNoChanges          = "No changes - the deployment is up to date."
 )
 
 // FormatStatus prints the given deployment status
@@ -26,6 +34,15 @@ func FormatStatus(remoteName string, s *api.DeploymentStatus) string {
 	// If no branch/commit, then it's likely the deployment has not
 	// been instantiated on the remote yet
 	var statusString = branchStatus + commitStatus + commitMessage + buildTypeStatus
+	if s.Pinned {
+		statusString += " - Pinned:     " + s.Ref + "\n"
+	}
+	if s.Failure != "" {
+		statusString += " - Failed:     " + s.Failure + "\n"
+	}
+	if limits := formatResources(s.Resources); limits != "" {
+		statusString += " - Limits:     " + limits + "\n"
+	}
 	if s.Branch == "" && s.CommitHash == "" && s.CommitMessage == "" {
 		statusString += msgNoDeployment
 	}
@@ -63,6 +80,165 @@ func FormatStatus(remoteName string, s *api.DeploymentStatus) string {
 	return statusString
 }
 
+// formatResources lists the given resource limits, if any are set
+func formatResources(r *api.Resources) string {
+	if r == nil {
+		return ""
+	}
+	var limits []string
+	if r.Memory != "" {
+		limits = append(limits, "memory "+r.Memory)
+	}
+	if r.MemorySwap != "" {
+		limits = append(limits, "memory+swap "+r.MemorySwap)
+	}
+	if r.CPUs > 0 {
+		limits = append(limits, fmt.Sprintf("cpus %g", r.CPUs))
+	}
+	if r.CPUShares > 0 {
+		limits = append(limits, fmt.Sprintf("cpu shares %d", r.CPUShares))
+	}
+	if r.PidsLimit > 0 {
+		limits = append(limits, fmt.Sprintf("pids %d", r.PidsLimit))
+	}
+	return strings.Join(limits, ", ")
+}
+
+// FormatHistory prints the given deployment records
+func FormatHistory(records []api.DeploymentRecord) string {
+	if len(records) == 0 {
+		return msgNoHistory + "\n"
+	}
+	var historyString string
+	for _, r := range records {
+		var outcome = "deployed"
+		if r.Failed {
+			outcome = "failed"
+		}
+		historyString += fmt.Sprintf(" - %s (%s) %s %s", shortHash(r.CommitHash), r.Branch,
+			outcome, r.DeployedAt.Local().Format("2006-01-02 15:04:05"))
+		if r.BuildType != "" {
+			historyString += " using " + r.BuildType
+		}
+		if r.ContainerStatus != "" {
+			historyString += fmt.Sprintf(" [%s]", r.ContainerStatus)
+		}
+		if r.BuildID != "" {
+			historyString += " (build " + r.BuildID + ")"
+		}
+		if r.Error != "" {
+			historyString += ": " + r.Error
+		}
+		historyString += "\n"
+		for _, h := range r.Hooks {
+			var result = "ok"
+			if !h.Success {
+				result = "failed: " + h.Error
+			}
+			historyString += fmt.Sprintf("   - %s hook %s (%s): %s\n",
+				strings.Replace(h.Stage, "_", "-", 1), h.Name, h.Duration, result)
+		}
+	}
+	return historyString
+}
+
+// FormatBuilds prints the given build records
+func FormatBuilds(records []api.BuildRecord) string {
+	if len(records) == 0 {
+		return msgNoBuilds + "\n"
+	}
+	var buildsString string
+	for _, r := range records {
+		buildsString += fmt.Sprintf(" - %s (%s) started %s: %s", r.ID, r.Trigger,
+			r.StartedAt.Local().Format("2006-01-02 15:04:05"), r.Status)
+		if r.CommitHash != "" {
+			buildsString += " at " + shortHash(r.CommitHash)
+		}
+		if r.FinishedAt != nil {
+			buildsString += fmt.Sprintf(" in %s", r.FinishedAt.Sub(r.StartedAt).Round(time.Second))
+		}
+		buildsString += fmt.Sprintf(" [%s", units.HumanSize(float64(r.Size)))
+		if r.Truncated {
+			buildsString += ", truncated"
+		}
+		buildsString += "]\n"
+		if r.Error != "" {
+			buildsString += "   - error: " + r.Error + "\n"
+		}
+	}
+	return buildsString
+}
+
+// FormatPlan prints the given deployment plan
+func FormatPlan(p *api.DeploymentPlan) string {
+	if p.NewDeployment {
+		return msgNewDeployment + "\n"
+	}
+	var planString = fmt.Sprintf(" - Commit:     %s -> %s (%s)\n",
+		shortHash(p.CurrentCommit), shortHash(p.TargetCommit), p.TargetRef)
+	if len(p.Commits) == 0 && len(p.ConfigChanges) == 0 &&
+		len(p.EnvAdded) == 0 && len(p.EnvRemoved) == 0 {
+		return planString + msgNoChanges + "\n"
+	}
+
+	if len(p.Commits) > 0 {
+		planString += "Incoming commits:\n"
+		for _, c := range p.Commits {
+			planString += fmt.Sprintf(" - %s %s (%s)\n", shortHash(c.Hash), c.Message, c.Author)
+		}
+	}
+	if len(p.Files) > 0 {
+		planString += "Changed files:\n"
+		for _, f := range p.Files {
+			planString += fmt.Sprintf(" - %-8s %s\n", f.Action, f.Path)
+		}
+	}
+	if len(p.ConfigChanges) > 0 {
+		planString += "Configuration changes:\n"
+		for _, c := range p.ConfigChanges {
+			planString += fmt.Sprintf(" - %s: '%s' -> '%s'\n", c.Field, c.From, c.To)
+		}
+	}
+	if len(p.EnvAdded) > 0 || len(p.EnvRemoved) > 0 {
+		planString += "Environment variables:\n"
+		for _, e := range p.EnvAdded {
+			planString += " + " + e + "\n"
+		}
+		for _, e := range p.EnvRemoved {
+			planString += " - " + e + "\n"
+		}
+	}
+	if len(p.ContainersToStop) > 0 {
+		planString += "Containers to be replaced:\n"
+		for _, c := range p.ContainersToStop {
+			planString += " - " + c + "\n"
+		}
+	}
+	return planString
+}
+
+// FormatDiskUsage prints the given disk usage
+func FormatDiskUsage(u *api.DiskUsage) string {
+	var entry = func(label string, e api.DiskUsageEntry) string {
+		return fmt.Sprintf(" - %-20s %d (%s, %s reclaimable)\n", label+":", e.Count,
+			units.HumanSize(float64(e.Size)), units.HumanSize(float64(e.Reclaimable)))
+	}
+	var usageString = "Disk usage:\n"
+	usageString += entry("Images", u.Images)
+	usageString += entry("Archived containers", u.ArchivedContainers)
+	usageString += entry("Volumes", u.Volumes)
+	usageString += fmt.Sprintf(" - %-20s %s (%s reclaimable)\n", "Build cache:",
+		units.HumanSize(float64(u.BuildCache.Size)), units.HumanSize(float64(u.BuildCache.Reclaimable)))
+	usageString += fmt.Sprintf(" - %-20s %d projects (%s)\n", "Persisted data:",
+		u.Persist.Count, units.HumanSize(float64(u.Persist.Size)))
+	if u.Total > 0 {
+		usageString += fmt.Sprintf("Free space: %s of %s (%.1f%%)\n",
+			units.HumanSize(float64(u.Free)), units.HumanSize(float64(u.Total)),
+			float64(u.Free)/float64(u.Total)*100)
+	}
+	return usageString
+}
+
 // FormatRemoteDetails prints the given remote configuration
 func FormatRemoteDetails(remote cfg.Remote) string {
 	var remoteString string
@@ -83,3 +259,11 @@ func FormatRemoteDetails(remote cfg.Remote) string {
 	}
 	return remoteString
 }
+
+// shortHash abbreviates the given commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
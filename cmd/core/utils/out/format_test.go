@@ -2,6 +2,7 @@ package out
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ubclaunchpad/inertia/api"
@@ -33,6 +34,43 @@ func TestFormatStatus(t *testing.T) {
 		assert.Contains(t, out, msgBuildInProgress)
 	})
 
+	t.Run("with pinned deployment", func(t *testing.T) {
+		out := FormatStatus("robert", &api.DeploymentStatus{
+			InertiaVersion: "9000",
+			Branch:         "call",
+			CommitHash:     "me",
+			CommitMessage:  "maybe",
+			Containers:     []string{"wow"},
+			Pinned:         true,
+			Ref:            "v1.0.0",
+		})
+		assert.Contains(t, out, "Pinned:     v1.0.0")
+	})
+
+	t.Run("with failed deployment", func(t *testing.T) {
+		out := FormatStatus("robert", &api.DeploymentStatus{
+			InertiaVersion: "9000",
+			Branch:         "call",
+			CommitHash:     "me",
+			CommitMessage:  "maybe",
+			Containers:     []string{"wow"},
+			Failure:        "container web exited 5 times within 10m0s",
+		})
+		assert.Contains(t, out, "Failed:     container web exited 5 times")
+	})
+
+	t.Run("with resource limits", func(t *testing.T) {
+		out := FormatStatus("robert", &api.DeploymentStatus{
+			InertiaVersion: "9000",
+			Branch:         "call",
+			CommitHash:     "me",
+			CommitMessage:  "maybe",
+			Containers:     []string{"wow"},
+			Resources:      &api.Resources{Memory: "512m", CPUs: 1.5, PidsLimit: 100},
+		})
+		assert.Contains(t, out, "Limits:     memory 512m, cpus 1.5, pids 100")
+	})
+
 	t.Run("with new version available", func(t *testing.T) {
 		version := "v0.6.0"
 		out := FormatStatus("robert", &api.DeploymentStatus{
@@ -63,6 +101,98 @@ func TestFormatStatusNoDeployment(t *testing.T) {
 	assert.Contains(t, out, msgNoDeployment)
 }
 
+func TestFormatHistory(t *testing.T) {
+	out := FormatHistory([]api.DeploymentRecord{
+		{CommitHash: "abcdef0123456789", Branch: "master", BuildType: "dockerfile", ContainerStatus: "running",
+			BuildID: "20200101-000000-abcd"},
+		{CommitHash: "1234", Branch: "dev", Hooks: []api.HookResult{
+			{Name: "migrate", Stage: "pre_deploy", Success: true, Duration: "1s"},
+			{Name: "purge", Stage: "post_deploy", Success: false, Error: "oh no", Duration: "2s"},
+		}},
+		{CommitHash: "5678", Branch: "dev", BuildID: "20200102-000000-ef01", Failed: true, Error: "build failed",
+			Hooks: []api.HookResult{
+				{Name: "migrate", Stage: "pre_deploy", Success: false, Error: "exit status 1", Duration: "1s"},
+			}},
+	})
+	assert.Contains(t, out, "abcdef0 (master)")
+	assert.NotContains(t, out, "abcdef01")
+	assert.Contains(t, out, "using dockerfile [running] (build 20200101-000000-abcd)")
+	assert.Contains(t, out, "1234 (dev)")
+	assert.Contains(t, out, "pre-deploy hook migrate (1s): ok")
+	assert.Contains(t, out, "post-deploy hook purge (2s): failed: oh no")
+	assert.Contains(t, out, "5678 (dev) failed")
+	assert.Contains(t, out, "(build 20200102-000000-ef01): build failed")
+	assert.Contains(t, out, "pre-deploy hook migrate (1s): failed: exit status 1")
+
+	t.Run("with no history", func(t *testing.T) {
+		assert.Contains(t, FormatHistory(nil), msgNoHistory)
+	})
+}
+
+func TestFormatBuilds(t *testing.T) {
+	var started = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var finished = started.Add(90 * time.Second)
+	out := FormatBuilds([]api.BuildRecord{
+		{ID: "20200101-000000-abcd", Trigger: "webhook", Status: "failed", CommitHash: "abcdef0123456789",
+			StartedAt: started, FinishedAt: &finished, Size: 2048, Truncated: true, Error: "oh no"},
+		{ID: "20191231-000000-ef01", Trigger: "up", Status: "running", StartedAt: started},
+	})
+	assert.Contains(t, out, "20200101-000000-abcd (webhook)")
+	assert.Contains(t, out, "failed at abcdef0 in 1m30s [2.048kB, truncated]")
+	assert.Contains(t, out, "error: oh no")
+	assert.Contains(t, out, "20191231-000000-ef01 (up)")
+	assert.Contains(t, out, "running [0B]")
+
+	t.Run("with no builds", func(t *testing.T) {
+		assert.Contains(t, FormatBuilds(nil), msgNoBuilds)
+	})
+}
+
+func TestFormatPlan(t *testing.T) {
+	out := FormatPlan(&api.DeploymentPlan{
+		CurrentCommit:    "abcdef0123456789",
+		TargetRef:        "master",
+		TargetCommit:     "0123456789abcdef",
+		Commits:          []api.CommitSummary{{Hash: "0123456789abcdef", Author: "bob", Message: "add server"}},
+		Files:            []api.FileChange{{Action: "added", Path: "main.go"}},
+		ConfigChanges:    []api.ConfigChange{{Field: "build type", From: "dockerfile", To: "docker-compose"}},
+		EnvAdded:         []string{"NEW_KEY"},
+		EnvRemoved:       []string{"OLD_KEY"},
+		ContainersToStop: []string{"/web"},
+	})
+	assert.Contains(t, out, "abcdef0 -> 0123456 (master)")
+	assert.Contains(t, out, "0123456 add server (bob)")
+	assert.Contains(t, out, "added    main.go")
+	assert.Contains(t, out, "build type: 'dockerfile' -> 'docker-compose'")
+	assert.Contains(t, out, " + NEW_KEY")
+	assert.Contains(t, out, " - OLD_KEY")
+	assert.Contains(t, out, " - /web")
+
+	t.Run("with no changes", func(t *testing.T) {
+		out := FormatPlan(&api.DeploymentPlan{CurrentCommit: "1234", TargetCommit: "1234"})
+		assert.Contains(t, out, msgNoChanges)
+	})
+	t.Run("with new deployment", func(t *testing.T) {
+		assert.Contains(t, FormatPlan(&api.DeploymentPlan{NewDeployment: true}), msgNewDeployment)
+	})
+}
+
+func TestFormatDiskUsage(t *testing.T) {
+	out := FormatDiskUsage(&api.DiskUsage{
+		Images:             api.DiskUsageEntry{Count: 3, Size: 2000000000, Reclaimable: 500000000},
+		ArchivedContainers: api.DiskUsageEntry{Count: 5, Size: 20000000, Reclaimable: 20000000},
+		BuildCache:         api.DiskUsageEntry{Size: 300000000, Reclaimable: 300000000},
+		Persist:            api.DiskUsageEntry{Count: 2, Size: 1000},
+		Total:              40000000000,
+		Free:               10000000000,
+	})
+	assert.Contains(t, out, "Images:              3 (2GB, 500MB reclaimable)")
+	assert.Contains(t, out, "Archived containers: 5 (20MB, 20MB reclaimable)")
+	assert.Contains(t, out, "Build cache:         300MB (300MB reclaimable)")
+	assert.Contains(t, out, "Persisted data:      2 projects (1kB)")
+	assert.Contains(t, out, "Free space: 10GB of 40GB (25.0%)")
+}
+
 func TestFormatRemoteDetails(t *testing.T) {
 	var out = FormatRemoteDetails(cfg.Remote{
 		Name: "bob",
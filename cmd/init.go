@@ -156,6 +156,24 @@ See https://inertia.ubclaunchpad.com/#project-configuration for more details.`,
 				out.Println("Dockerfile project detected :whale:")
 				buildType = cfg.Dockerfile
 				buildFilePath = "Dockerfile"
+			} else if language, found := common.DetectLanguage("."); found {
+				// projects without Docker configuration can still be built using
+				// a generated Dockerfile
+				useBuildpack, err := input.NewPrompt(nil).
+					Prompt(highlight.Sf(":mag: %s project detected without a build file - would you like "+
+						"Inertia to build it using a generated Dockerfile? (y/N)", language)).
+					GetBool()
+				if err != nil {
+					out.Fatal(err)
+				}
+				if useBuildpack {
+					buildType = cfg.Buildpack
+				} else {
+					buildType, buildFilePath, err = addProjectWalkthrough()
+					if err != nil {
+						out.Fatal(err)
+					}
+				}
 			} else {
 				out.Println(":question: no build file detected")
 				var err error
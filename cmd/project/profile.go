@@ -38,6 +38,8 @@ func (p *ProfileCmd) attachSetCmd() {
 		flagBranch        = "branch"
 		flagBuildType     = "build.type"
 		flagBuildFilePath = "build.file"
+		flagBuildImage    = "build.image"
+		flagBuildStrategy = "build.strategy"
 	)
 	var configure = &cobra.Command{
 		Use:   "configure [profile]",
@@ -45,16 +47,24 @@ func (p *ProfileCmd) attachSetCmd() {
 		Long: `Configures project profiles - if the given profile does not exist,
 a new one is created, otherwise the existing one is overwritten.
 
-Provide profile values via the available flags.`,
+Provide profile values via the available flags. Profiles with build type
+'image' deploy a prebuilt image, provided with --build.image, instead of
+building your project on your remote - credentials for private images can be
+added to the profile's [profile.build.image] section in your project
+configuration. Profiles with build type 'buildpack' are built using a
+Dockerfile generated for your project's language, and do not need a build file.`,
 		Aliases: []string{"add", "set"},
 		Args:    cobra.ExactArgs(1),
-		Example: "inertia project profile configure my_profile --build.type dockerfile --build.file Dockerfile.dev",
+		Example: "inertia project profile configure my_profile --build.type dockerfile --build.file Dockerfile.dev\n" +
+			"inertia project profile configure my_profile --build.type image --build.image ubclaunchpad/inertia:latest",
 		Run: func(cmd *cobra.Command, args []string) {
 			var (
-				err       error
-				branch, _ = cmd.Flags().GetString(flagBranch)
-				bTypeS, _ = cmd.Flags().GetString(flagBuildType)
-				bPath, _  = cmd.Flags().GetString(flagBuildFilePath)
+				err        error
+				branch, _  = cmd.Flags().GetString(flagBranch)
+				bTypeS, _  = cmd.Flags().GetString(flagBuildType)
+				bPath, _   = cmd.Flags().GetString(flagBuildFilePath)
+				bImage, _  = cmd.Flags().GetString(flagBuildImage)
+				bStratS, _ = cmd.Flags().GetString(flagBuildStrategy)
 			)
 
 			if branch == "" {
@@ -68,6 +78,19 @@ Provide profile values via the available flags.`,
 			if err != nil {
 				out.Fatal(err)
 			}
+			bStrat, err := cfg.AsDeployStrategy(bStratS)
+			if err != nil {
+				out.Fatal(err)
+			}
+			var image *cfg.ImageSource
+			if bType == cfg.Image {
+				if bImage == "" {
+					out.Fatalf("flag '%s' is required for build type '%s'", flagBuildImage, bType)
+				}
+				image = &cfg.ImageSource{Name: bImage}
+			} else if bPath == "" && bType != cfg.Buildpack {
+				out.Fatalf("flag '%s' is required for build type '%s'", flagBuildFilePath, bType)
+			}
 
 			p.root.config.SetProfile(cfg.Profile{
 				Name:   args[0],
@@ -75,6 +98,8 @@ Provide profile values via the available flags.`,
 				Build: &cfg.Build{
 					Type:          bType,
 					BuildFilePath: bPath,
+					Image:         image,
+					Strategy:      bStrat,
 				},
 			})
 
@@ -88,7 +113,8 @@ Provide profile values via the available flags.`,
 	configure.Flags().String(flagBuildType, "", "build type for profile")
 	configure.MarkFlagRequired(flagBuildType)
 	configure.Flags().String(flagBuildFilePath, "", "relative path to build config file (e.g. 'Dockerfile')")
-	configure.MarkFlagRequired(flagBuildFilePath)
+	configure.Flags().String(flagBuildImage, "", "image to deploy for build type 'image' (e.g. 'ubclaunchpad/inertia:latest')")
+	configure.Flags().String(flagBuildStrategy, "", "deploy strategy for profile, either 'recreate' or 'blue-green' (default: recreate)")
 	p.AddCommand(configure)
 }
 
@@ -143,7 +169,8 @@ func (p *ProfileCmd) attachListCmd() {
 					out.Printf(`:christmas_tree: Branch:              %s
 :hammer: Build.Type:          %s
 :ledger: Build.BuildFile:     %s
-`, pf.Branch, pf.Build.Type, pf.Build.BuildFilePath)
+:twisted_rightwards_arrows: Build.Strategy:      %s
+`, pf.Branch, pf.Build.Type, pf.Build.BuildFilePath, pf.Build.Strategy)
 				} else {
 					out.Println(pf.Name)
 				}
@@ -170,7 +197,8 @@ func (p *ProfileCmd) attachShowCmd() {
 			out.Printf(`:christmas_tree: Branch:              %s
 :hammer: Build.Type:          %s
 :ledger: Build.BuildFile:     %s
-`, pf.Branch, pf.Build.Type, pf.Build.BuildFilePath)
+:twisted_rightwards_arrows: Build.Strategy:      %s
+`, pf.Branch, pf.Build.Type, pf.Build.BuildFilePath, pf.Build.Strategy)
 		},
 	}
 	p.AddCommand(show)
@@ -0,0 +1,96 @@
+package remotescmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/ubclaunchpad/inertia/cmd/core/utils/out"
+)
+
+// BuildsCmd is the parent class for the 'builds' subcommands
+type BuildsCmd struct {
+	*cobra.Command
+	host *HostCmd
+}
+
+// AttachBuildsCmd attaches the 'builds' subcommands to the given host
+func AttachBuildsCmd(host *HostCmd) {
+	var builds = &BuildsCmd{
+		Command: &cobra.Command{
+			Use:   "builds",
+			Short: "Browse output of builds on your remote",
+			Long: `Lists and prints the recorded output of builds of your project on your remote.
+
+Every deploy, whether from 'inertia [remote] up', a webhook, or a rollback, is
+recorded as a build. Logs of the most recent builds of each project are kept,
+and are linked from the deployments listed by 'inertia [remote] history'.`,
+		},
+		host: host,
+	}
+
+	// attach children
+	builds.attachListCmd()
+	builds.attachLogsCmd()
+
+	// attach to parent
+	host.AddCommand(builds.Command)
+}
+
+// Context returns the root host command's context
+func (root *BuildsCmd) Context() context.Context { return root.host.ctx }
+
+func (root *BuildsCmd) attachListCmd() {
+	var list = &cobra.Command{
+		Use:   "ls",
+		Short: "List builds of your project",
+		Long:  `Lists recorded builds of your project on your remote, most recent first.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			builds, err := root.host.client.Builds(root.Context())
+			if err != nil {
+				out.Fatal(err)
+			}
+			out.Print(out.FormatBuilds(builds))
+		},
+	}
+	root.AddCommand(list)
+}
+
+func (root *BuildsCmd) attachLogsCmd() {
+	const flagFollow = "follow"
+	var logs = &cobra.Command{
+		Use:   "logs [id]",
+		Short: "Print the output of a build of your project",
+		Long: `Prints the recorded output of a build of your project. By default, the output
+of the most recent build is printed - use 'inertia [remote] builds ls' to find
+the IDs of other builds.
+
+Use the --follow flag to keep printing the output of a build that is still
+running until it finishes.`,
+		Example: "inertia staging builds logs --follow",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var follow, _ = cmd.Flags().GetBool(flagFollow)
+			var id string
+			if len(args) > 0 {
+				id = args[0]
+			} else {
+				builds, err := root.host.client.Builds(root.Context())
+				if err != nil {
+					out.Fatal(err)
+				}
+				if len(builds) == 0 {
+					out.Fatal(errors.New("no builds found"))
+				}
+				id = builds[0].ID
+			}
+
+			if err := root.host.client.BuildLogs(root.Context(), id, follow); err != nil {
+				out.Fatal(err)
+			}
+		},
+	}
+	logs.Flags().BoolP(flagFollow, "f", false, "keep printing output of running builds")
+	root.AddCommand(logs)
+}
@@ -0,0 +1,151 @@
+package remotescmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/ubclaunchpad/inertia/cmd/core/utils/out"
+)
+
+// GitCredentialsCmd is the parent class for the 'git-credentials' subcommands
+type GitCredentialsCmd struct {
+	*cobra.Command
+	host *HostCmd
+}
+
+// AttachGitCredentialsCmd attaches the 'git-credentials' subcommands to the
+// given host
+func AttachGitCredentialsCmd(host *HostCmd) {
+	var creds = &GitCredentialsCmd{
+		Command: &cobra.Command{
+			Use:   "git-credentials",
+			Short: "Manage credentials used to access HTTPS git remotes",
+			Long: `Manages the credentials your remote uses to clone and fetch your project
+over HTTPS, for networks where SSH access to your git host is unavailable.
+
+Credentials are encrypted when stored, and are only used when your project is
+configured to use HTTPS:
+
+	[profile.default.git]
+	https = true
+`,
+		},
+		host: host,
+	}
+
+	// attach children
+	creds.attachSetCmd()
+	creds.attachShowCmd()
+	creds.attachRemoveCmd()
+
+	// attach to parent
+	host.AddCommand(creds.Command)
+}
+
+// Context returns the root host command's context
+func (root *GitCredentialsCmd) Context() context.Context { return root.host.ctx }
+
+func (root *GitCredentialsCmd) attachSetCmd() {
+	const flagUsername = "username"
+	var set = &cobra.Command{
+		Use:   "set",
+		Short: "Set the credentials used to access HTTPS git remotes",
+		Long: `Sets the token used to access your project's git remote over HTTPS, replacing
+any existing credentials. Most git hosts accept personal access tokens or
+deploy tokens in place of a password.
+
+You will be prompted for the token, which can also be piped in through stdin
+so that it never appears in your shell history.`,
+		Example: "echo $TOKEN | inertia staging git-credentials set",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var username, _ = cmd.Flags().GetString(flagUsername)
+			token, err := readToken()
+			if err != nil {
+				out.Fatal(err)
+			}
+			if err := root.host.client.UpdateGitCredentials(
+				root.Context(),
+				username,
+				token,
+				false,
+			); err != nil {
+				out.Fatal(err)
+			}
+			out.Println("git credentials successfully updated")
+		},
+	}
+	set.Flags().String(flagUsername, "x-access-token", "username to authenticate with")
+	root.AddCommand(set)
+}
+
+// readToken prompts for a token if stdin is a terminal, and otherwise reads
+// the first line of stdin
+func readToken() (string, error) {
+	var token string
+	if terminal.IsTerminal(int(syscall.Stdin)) {
+		out.Print(out.C(":key: Enter a token: ", out.CY))
+		tokenBytes, err := terminal.ReadPassword(int(syscall.Stdin))
+		out.Print("\n")
+		if err != nil {
+			return "", errors.New("invalid token")
+		}
+		token = string(tokenBytes)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("no token provided through stdin")
+		}
+		token = line
+	}
+	if token = strings.TrimSpace(token); token == "" {
+		return "", errors.New("invalid token")
+	}
+	return token, nil
+}
+
+func (root *GitCredentialsCmd) attachRemoveCmd() {
+	var remove = &cobra.Command{
+		Use:   "rm",
+		Short: "Remove the credentials used to access HTTPS git remotes",
+		Long:  `Removes stored credentials used to access HTTPS git remotes from your remote.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := root.host.client.UpdateGitCredentials(
+				root.Context(),
+				"",
+				"",
+				true,
+			); err != nil {
+				out.Fatal(err)
+			}
+			out.Println("git credentials successfully removed")
+		},
+	}
+	root.AddCommand(remove)
+}
+
+func (root *GitCredentialsCmd) attachShowCmd() {
+	var show = &cobra.Command{
+		Use:   "show",
+		Short: "Show the credentials used to access HTTPS git remotes",
+		Long: `Shows the username of the credentials used to access HTTPS git remotes. Stored
+tokens are never displayed.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			username, err := root.host.client.GetGitCredentials(root.Context())
+			if err != nil {
+				out.Fatal(err)
+			}
+			out.Println("git credentials configured for user " + username)
+		},
+	}
+	root.AddCommand(show)
+}
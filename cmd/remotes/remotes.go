@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/ubclaunchpad/inertia/cfg"
 	"github.com/ubclaunchpad/inertia/client"
@@ -154,6 +157,7 @@ Run 'inertia [remote] init' to gather this information.`,
 			}
 			var debug, _ = cmd.Flags().GetBool(flagDebug)
 			host.client.WithDebug(debug)
+			host.client.WithProject(host.project.Name)
 		},
 	}
 	host.PersistentFlags().BoolP(flagShort, "s", false,
@@ -165,12 +169,20 @@ Run 'inertia [remote] init' to gather this information.`,
 	host.attachInitCmd()
 	host.attachUpCmd()
 	host.attachDownCmd()
+	host.attachRestartCmd()
 	host.attachStatusCmd()
 	host.attachLogsCmd()
+	host.attachExecCmd()
+	host.attachHistoryCmd()
+	host.attachRollbackCmd()
+	host.attachCancelCmd()
+	AttachBuildsCmd(host)
 	AttachUserCmd(host)
 	AttachEnvCmd(host)
+	AttachGitCredentialsCmd(host)
 	host.attachSendFileCmd()
 	host.attachSSHCmd()
+	host.attachDfCmd()
 	host.attachPruneCmd()
 	host.attachTokenCmd()
 	host.attachUpgradeCmd()
@@ -185,6 +197,8 @@ func (root *HostCmd) getRemote() *cfg.Remote { return root.client.Remote }
 func (root *HostCmd) attachUpCmd() {
 	const (
 		flagProfile = "profile"
+		flagRef     = "ref"
+		flagDryRun  = "dry-run"
 	)
 	var up = &cobra.Command{
 		Use:   "up",
@@ -192,24 +206,50 @@ func (root *HostCmd) attachUpCmd() {
 		Long: `Builds and deploy your project on your remote using your project's
 default profile, or a profile you have applied using 'inertia project profile apply'.
 
+Use the --ref flag to deploy a specific commit, tag, or branch instead of the
+tip of your profile's branch. The deployment stays pinned to that ref, and is not
+updated by webhooks, until you run 'inertia [remote] up' without --ref.
+
+Use the --dry-run flag to see what a deploy would change - incoming commits and
+files, configuration and environment changes, and containers that would be
+replaced - without deploying anything.
+
 This requires an Inertia daemon to be active on your remote - do this by running
 'inertia [remote] init'.`,
+		Example: "inertia staging up\ninertia production up --ref v1.2.0\ninertia production up --dry-run",
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get flags and profile
 			var short, _ = cmd.Flags().GetBool(flagShort)
+			var ref, _ = cmd.Flags().GetString(flagRef)
+			var dryRun, _ = cmd.Flags().GetBool(flagDryRun)
 			var profileName = root.getRemote().GetProfile(root.project.Name)
 			profile, found := root.project.GetProfile(profileName)
 			if !found {
 				out.Fatalf("could not find profile '%s'", profileName)
 			}
-			out.Printf("deploying project '%s' using profile '%s'\n", root.project.Name, profileName)
 
 			// Make up request
 			var req = client.UpRequest{
 				Project: root.project.Name,
 				URL:     root.project.URL,
+				Ref:     ref,
 				Profile: *profile}
 
+			if dryRun {
+				plan, err := root.client.Plan(root.ctx, req)
+				if err != nil {
+					out.Fatal(err)
+				}
+				out.Printf("planning deploy of project '%s' using profile '%s'\n", root.project.Name, profileName)
+				out.Print(out.FormatPlan(plan))
+				return
+			}
+
+			out.Printf("deploying project '%s' using profile '%s'\n", root.project.Name, profileName)
+			if ref != "" {
+				out.Printf("deployment will be pinned to ref '%s'\n", ref)
+			}
+
 			var err error
 			if short {
 				err = root.client.Up(root.ctx, req)
@@ -225,6 +265,8 @@ This requires an Inertia daemon to be active on your remote - do this by running
 		},
 	}
 	up.Flags().StringP(flagProfile, "p", "", "specify a profile to deploy")
+	up.Flags().String(flagRef, "", "commit hash, tag, or branch to deploy and pin the deployment to")
+	up.Flags().Bool(flagDryRun, false, "show what would change without deploying")
 	root.AddCommand(up)
 }
 
@@ -245,6 +287,47 @@ Requires project to be online - do this by running 'inertia [remote] up`,
 	root.AddCommand(down)
 }
 
+func (root *HostCmd) attachRestartCmd() {
+	const flagAll = "all"
+	var restart = &cobra.Command{
+		Use:   "restart [container]",
+		Short: "Restart project containers on your remote",
+		Long: `Restarts an individual container of your project on your remote, without
+rebuilding your project. Use 'inertia [remote] status' to see which containers
+are active, or use the --all flag to restart all of them.`,
+		Example: "inertia staging restart web",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var all, _ = cmd.Flags().GetBool(flagAll)
+			var containers []string
+			switch {
+			case all && len(args) == 0:
+				status, err := root.client.Status(root.ctx)
+				if err != nil {
+					out.Fatal(err)
+				}
+				containers = status.Containers
+			case !all && len(args) == 1:
+				containers = args
+			default:
+				out.Fatal("either a container or the --all flag is required")
+			}
+			if len(containers) == 0 {
+				out.Fatal("no active containers to restart")
+			}
+
+			for _, c := range containers {
+				if err := root.client.RestartContainer(root.ctx, c); err != nil {
+					out.Fatalf("failed to restart %s: %s", c, err.Error())
+				}
+				out.Printf("container %s restarted\n", strings.TrimPrefix(c, "/"))
+			}
+		},
+	}
+	restart.Flags().Bool(flagAll, false, "restart all active project containers")
+	root.AddCommand(restart)
+}
+
 func (root *HostCmd) attachStatusCmd() {
 	var stat = &cobra.Command{
 		Use:   "status",
@@ -272,7 +355,10 @@ Requires the Inertia daemon to be active on your remote - do this by running 'in
 }
 
 func (root *HostCmd) attachLogsCmd() {
-	const flagEntries = "entries"
+	const (
+		flagEntries = "entries"
+		flagLevel   = "level"
+	)
 	var log = &cobra.Command{
 		Use:   "logs [container]",
 		Short: "Access logs of containers on your remote host",
@@ -280,10 +366,15 @@ func (root *HostCmd) attachLogsCmd() {
 	
 By default, this command retrieves Inertia daemon logs, but you can provide an
 argument that specifies the name of the container you wish to retrieve logs for.
-Use 'inertia [remote] status' to see which containers are active.`,
+Use 'inertia [remote] status' to see which containers are active.
+
+Daemon logs can be filtered by level with the --level flag, which shows only
+entries of the given level or above - one of debug, info, warn, or error.`,
+		Example: "inertia staging logs --level warn",
 		Run: func(cmd *cobra.Command, args []string) {
 			var short, _ = cmd.Flags().GetBool(flagShort)
 			var entries, _ = cmd.Flags().GetInt(flagEntries)
+			var level, _ = cmd.Flags().GetString(flagLevel)
 
 			// get daemon logs by default
 			var container = "/inertia-daemon"
@@ -293,7 +384,8 @@ Use 'inertia [remote] status' to see which containers are active.`,
 
 			var req = client.LogsRequest{
 				Container: container,
-				Entries:   entries}
+				Entries:   entries,
+				Level:     level}
 
 			if short {
 				// if short, just grab the last x log entries
@@ -311,14 +403,192 @@ Use 'inertia [remote] status' to see which containers are active.`,
 		},
 	}
 	log.Flags().Int(flagEntries, 0, "Number of log entries to fetch")
+	log.Flags().String(flagLevel, "", "Minimum level of daemon log entries to show")
 	root.AddCommand(log)
 }
 
+func (root *HostCmd) attachExecCmd() {
+	var exec = &cobra.Command{
+		Use:   "exec [container] -- [command]",
+		Short: "Run a command in a project container on your remote",
+		Long: `Runs a command in an active project container on your remote, with your
+terminal attached. By default, this starts a shell in the container.
+
+Unlike 'inertia [remote] ssh', this only requires an Inertia user with admin
+privileges, not access to your remote's SSH key. Use 'inertia [remote] status'
+to see which containers are active.`,
+		Example: "inertia staging exec web -- rails console",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var req = client.ExecRequest{Container: args[0], Command: args[1:]}
+
+			// Put the terminal in raw mode so that input is sent as it is typed,
+			// and watch for size changes. The size is polled, since terminal
+			// resize signals are not available on all platforms.
+			var (
+				resize  = make(chan client.TerminalSize, 1)
+				stop    = make(chan struct{})
+				restore = func() { close(stop) }
+				fd      = int(os.Stdin.Fd())
+			)
+			if terminal.IsTerminal(fd) {
+				state, err := terminal.MakeRaw(fd)
+				if err != nil {
+					out.Fatal(err)
+				}
+				restore = func() {
+					close(stop)
+					terminal.Restore(fd, state)
+				}
+				go func() {
+					var last client.TerminalSize
+					var ticker = time.NewTicker(500 * time.Millisecond)
+					defer ticker.Stop()
+					for {
+						if cols, rows, err := terminal.GetSize(fd); err == nil {
+							var size = client.TerminalSize{Rows: uint(rows), Cols: uint(cols)}
+							if size != last {
+								last = size
+								select {
+								case resize <- size:
+								case <-stop:
+									return
+								}
+							}
+						}
+						select {
+						case <-stop:
+							return
+						case <-ticker.C:
+						}
+					}
+				}()
+			}
+
+			code, err := root.client.Exec(root.ctx, req, os.Stdin, os.Stdout, resize)
+			restore()
+			if err != nil {
+				out.Fatal(err)
+			}
+			if code != 0 {
+				os.Exit(code)
+			}
+		},
+	}
+	root.AddCommand(exec)
+}
+
+func (root *HostCmd) attachHistoryCmd() {
+	var history = &cobra.Command{
+		Use:   "history",
+		Short: "List past deployments on your remote",
+		Long: `Lists past deployments of your project on your remote, most recent first.
+
+Use 'inertia [remote] rollback' to redeploy one of the listed commits.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			records, err := root.client.History(root.ctx)
+			if err != nil {
+				out.Fatal(err)
+			}
+			out.Print(out.FormatHistory(records))
+		},
+	}
+	root.AddCommand(history)
+}
+
+func (root *HostCmd) attachRollbackCmd() {
+	var rollback = &cobra.Command{
+		Use:   "rollback [hash|N]",
+		Short: "Roll back your project to a previous deployment",
+		Long: `Rolls back your project to a previously deployed commit.
+
+By default, the project is rolled back to the commit deployed before the current
+one. Provide a number N to go back N deployed commits, or a (partial) commit hash
+from 'inertia [remote] history' to redeploy that commit.
+
+Note that the next 'inertia [remote] up' or webhook-triggered deploy will deploy
+the latest commit of your branch again.`,
+		Example: "inertia staging rollback 2",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var short, _ = cmd.Flags().GetBool(flagShort)
+			var req client.RollbackRequest
+			if len(args) > 0 {
+				// short numeric arguments are treated as a number of deployments,
+				// everything else is treated as a commit hash
+				if n, err := strconv.Atoi(args[0]); err == nil && len(args[0]) < 4 {
+					req.Steps = n
+				} else {
+					req.Commit = args[0]
+				}
+			}
+
+			var err error
+			if short {
+				err = root.client.Rollback(root.ctx, req)
+			} else {
+				err = root.client.RollbackWithOutput(root.ctx, req)
+			}
+			if err != nil {
+				out.Fatal(err)
+			}
+			if !short {
+				out.Println("project rollback successfully started!")
+			}
+		},
+	}
+	root.AddCommand(rollback)
+}
+
+func (root *HostCmd) attachCancelCmd() {
+	var cancel = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel the in-progress deploy on your remote",
+		Long: `Aborts the in-progress build and deploy of your project on your remote,
+as well as any deploy that is waiting to start.
+
+Deploys of your project run one at a time - if several deploys are requested
+while one is in progress, only the most recent request is deployed once it
+completes. Note that a cancelled deploy may leave your project offline, since
+active containers are stopped before the project is built (unless you use the
+blue-green deploy strategy).`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := root.client.CancelDeploy(root.ctx); err != nil {
+				out.Fatal(err)
+			}
+			out.Println("deploy cancelled")
+		},
+	}
+	root.AddCommand(cancel)
+}
+
+func (root *HostCmd) attachDfCmd() {
+	var df = &cobra.Command{
+		Use:   "df",
+		Short: "Report disk usage on your remote",
+		Long: `Reports the disk space used on your remote by Docker images, containers
+archived by past deployments, volumes, the build cache, and persisted project
+data, along with how much of it could be reclaimed.
+
+Use 'inertia [remote] prune' to free up space, or configure a retention policy
+on your remote to clean up old containers and images automatically.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			usage, err := root.client.DiskUsage(root.ctx)
+			if err != nil {
+				out.Fatal(err)
+			}
+			out.Print(out.FormatDiskUsage(usage))
+		},
+	}
+	root.AddCommand(df)
+}
+
 func (root *HostCmd) attachPruneCmd() {
 	var prune = &cobra.Command{
 		Use:   "prune",
-		Short: "Prune Docker assets and images on your remote",
-		Long:  `Prunes Docker assets and images from your remote to free up storage space.`,
+		Short: "Prune Docker assets and images of your project on your remote",
+		Long: `Prunes stopped containers and unused images of your project from your
+remote to free up storage space.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := root.client.Prune(root.ctx); err != nil {
 				out.Fatal(err)
@@ -379,7 +649,7 @@ func (root *HostCmd) attachSendFileCmd() {
 			}
 
 			// Destination path - todo: allow config
-			var projectPath = "$HOME/inertia/project"
+			var projectPath = path.Join("$HOME/inertia/project", root.project.Name)
 			var remotePath = path.Join(projectPath, dest)
 
 			// Initiate copy
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Language denotes a kind of project that Inertia can build without any
+// Docker configuration
+type Language string
+
+const (
+	// LanguageGo denotes Go modules
+	LanguageGo Language = "go"
+	// LanguageNode denotes Node.js projects
+	LanguageNode Language = "node"
+	// LanguagePython denotes Python projects using pip
+	LanguagePython Language = "python"
+	// LanguageRuby denotes Ruby projects using Bundler
+	LanguageRuby Language = "ruby"
+	// LanguageStatic denotes static websites
+	LanguageStatic Language = "static"
+)
+
+// languageMarkers maps files that identify a project's language to that
+// language, in order of precedence
+var languageMarkers = []struct {
+	file     string
+	language Language
+}{
+	{"go.mod", LanguageGo},
+	{"package.json", LanguageNode},
+	{"requirements.txt", LanguagePython},
+	{"Gemfile", LanguageRuby},
+	{"index.html", LanguageStatic},
+}
+
+// DetectLanguage guesses the language of the project in the given directory
+// from the files at its root, and returns false if none is recognized
+func DetectLanguage(dir string) (Language, bool) {
+	for _, m := range languageMarkers {
+		if _, err := os.Stat(filepath.Join(dir, m.file)); err == nil {
+			return m.language, true
+		}
+	}
+	return "", false
+}
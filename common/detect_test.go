@@ -0,0 +1,41 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		want      Language
+		wantFound bool
+	}{
+		{"go", []string{"go.mod", "main.go"}, LanguageGo, true},
+		{"node", []string{"package.json"}, LanguageNode, true},
+		{"python", []string{"requirements.txt", "app.py"}, LanguagePython, true},
+		{"ruby", []string{"Gemfile"}, LanguageRuby, true},
+		{"static", []string{"index.html"}, LanguageStatic, true},
+		{"node with static files", []string{"package.json", "index.html"}, LanguageNode, true},
+		{"unknown", []string{"README.md"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "inertia-detect")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			for _, f := range tt.files {
+				assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, f), nil, 0644))
+			}
+
+			got, found := DetectLanguage(dir)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
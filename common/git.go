@@ -6,9 +6,11 @@ import (
 )
 
 // GetSSHRemoteURL gets the URL of the given remote in the form
-// "git@github.com:[USER]/[REPOSITORY].git"
+// "git@github.com:[USER]/[REPOSITORY].git". Any credentials in the given URL
+// are dropped, so this can also be used to check if two URLs in different forms
+// refer to the same repository.
 func GetSSHRemoteURL(url string) string {
-	re, _ := regexp.Compile("(https|git)://")
+	re, _ := regexp.Compile("(https?|git|ssh)://")
 
 	sshURL := re.ReplaceAllString(url, "git@")
 	if sshURL != url {
@@ -27,6 +29,17 @@ func GetSSHRemoteURL(url string) string {
 	return sshURL
 }
 
+// GetHTTPSRemoteURL gets the URL of the given remote in the form
+// "https://github.com/[USER]/[REPOSITORY].git". HTTP and HTTPS URLs are
+// returned as they are.
+func GetHTTPSRemoteURL(url string) string {
+	if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+		return url
+	}
+	var sshURL = strings.TrimPrefix(GetSSHRemoteURL(url), "git@")
+	return "https://" + strings.Replace(sshURL, ":", "/", 1)
+}
+
 // GetBranchFromRef gets the branch name from a git ref of form refs/...
 func GetBranchFromRef(ref string) string {
 	parts := strings.Split(ref, "/")
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
+)
+
+// requestLog records details of a request that are only known once its
+// permissions have been checked
+type requestLog struct {
+	username string
+}
+
+// logRequests is a middleware that writes an access log entry for every
+// request once it has been served, with the request's ID, the user that made
+// it, and how long it took to serve
+func (h *PermissionsHandler) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			start = time.Now()
+			entry = &requestLog{}
+			ww    = middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		)
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), ctxRequestLog, entry)))
+
+		// Hijacked connections, such as websockets, have no status
+		var status = ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		var level = log.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = log.LevelError
+		case status >= http.StatusBadRequest:
+			level = log.LevelWarn
+		}
+		var fields = []interface{}{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"latency", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+		}
+		if entry.username != "" {
+			fields = append(fields, "user", entry.username)
+		}
+		h.logger.WithRequest(r).Log(level, "request served", fields...)
+	})
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/crypto"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
+)
+
+func TestPermissionsHandler_logRequests(t *testing.T) {
+	dir := "./test_perm_accesslog"
+	assert.NoError(t, os.Mkdir(dir, os.ModePerm))
+	defer os.RemoveAll(dir)
+
+	var logs bytes.Buffer
+	ph, err := NewPermissionsHandler(path.Join(dir, "users.db"), "127.0.0.1", 3000,
+		log.NewLogger(log.LoggerOptions{Writer: &logs}),
+		crypto.GetFakeAPIKey)
+	assert.NoError(t, err)
+	defer ph.Close()
+	ph.AttachUserRestrictedHandlerFunc("/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), http.MethodPost)
+	assert.NoError(t, ph.users.AddUser("bobheadxi", "wowgreat", false))
+
+	// Rejected requests are logged
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set("X-Request-Id", "wow-000001")
+	recorder := httptest.NewRecorder()
+	ph.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, logs.String(), "level=warn")
+	assert.Contains(t, logs.String(), "request_id=wow-000001 method=POST path=/test status=401")
+	assert.NotContains(t, logs.String(), "user=")
+
+	// Requests from users are logged with the user's name
+	body, err := json.Marshal(&api.UserRequest{Username: "bobheadxi", Password: "wowgreat"})
+	assert.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	ph.ServeHTTP(recorder, httptest.NewRequest("POST", "/user/login", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var token string
+	api.Unmarshal(recorder.Body, api.KV{Key: "token", Value: &token})
+
+	logs.Reset()
+	req = httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder = httptest.NewRecorder()
+	ph.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var entry = strings.TrimSpace(logs.String())
+	assert.Contains(t, entry, "level=info")
+	assert.Contains(t, entry, "path=/test status=200")
+	assert.Contains(t, entry, "latency=")
+	assert.Contains(t, entry, "user=bobheadxi")
+}
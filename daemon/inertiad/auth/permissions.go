@@ -18,6 +18,7 @@ import (
 
 	"github.com/ubclaunchpad/inertia/api"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/crypto"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 )
 
 // ctxKey represents keys used in request contexts
@@ -25,6 +26,7 @@ type ctxKey int
 
 const (
 	ctxUsername ctxKey = iota
+	ctxRequestLog
 )
 
 // PermissionsHandler handles users, permissions, and sessions on top
@@ -34,15 +36,20 @@ type PermissionsHandler struct {
 	users      *userManager
 	sessions   *sessionManager
 	mux        *chi.Mux
+	handler    http.Handler
 	userPaths  []string
 	adminPaths []string
+
+	logger *log.Logger
 }
 
 // NewPermissionsHandler returns a new handler for authenticating users and
 // handling user administration. It also serves as the primary server for the
-// Inertia daemon.
+// Inertia daemon, and writes an access log entry for every request to the
+// given logger.
 func NewPermissionsHandler(
 	dbPath, hostDomain string, timeout int,
+	logger *log.Logger,
 	keyLookup ...func(*jwt.Token) (interface{}, error),
 ) (*PermissionsHandler, error) {
 	// Set up user manager
@@ -64,6 +71,7 @@ func NewPermissionsHandler(
 		users:    userManager,
 		sessions: sessionManager,
 		mux:      chi.NewMux(),
+		logger:   logger,
 
 		// paths restricted to users
 		userPaths: []string{
@@ -79,7 +87,10 @@ func NewPermissionsHandler(
 			"/user/list"},
 	}
 
-	// Register useful middleware
+	// Register useful middleware - requests are identified and logged before
+	// permissions are checked, so that rejected requests are logged too
+	h.handler = middleware.RequestID(middleware.RealIP(
+		h.logRequests(http.HandlerFunc(h.serve))))
 	h.mux.Use(
 		cors.New(cors.Options{
 			AllowedOrigins:   []string{"*"},
@@ -87,9 +98,6 @@ func NewPermissionsHandler(
 			AllowedHeaders:   []string{"*"},
 			AllowCredentials: true,
 		}).Handler,
-		middleware.RequestID,
-		middleware.RealIP,
-		// TODO: logging middleware
 		middleware.Recoverer)
 
 	// Register all user-related routes that managed by the permissions handler
@@ -120,8 +128,14 @@ func (h *PermissionsHandler) Close() error {
 	return h.users.Close()
 }
 
-// nolint: gocyclo
 func (h *PermissionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+// serve checks the permissions required by the requested path before serving
+// the request
+// nolint: gocyclo
+func (h *PermissionsHandler) serve(w http.ResponseWriter, r *http.Request) {
 	// http.StripPrefix removes the leading slash, but in the interest of
 	// maintaining similar behaviour to stdlib handler functions, we manually
 	// add a leading "/" here instead of having users not add a leading "/" on
@@ -178,7 +192,11 @@ func (h *PermissionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Attach username to request context so handlers can use it
+	// Attach username to request context so handlers can use it, and record
+	// it in the access log
+	if l, ok := r.Context().Value(ctxRequestLog).(*requestLog); ok {
+		l.username = claims.User
+	}
 	var ctx = context.WithValue(r.Context(), ctxUsername, claims.User)
 
 	// Serve the requested endpoint to token holders
@@ -205,7 +223,7 @@ func (h *PermissionsHandler) AttachUserRestrictedHandlerFunc(
 	handler http.HandlerFunc,
 	methods ...string,
 ) {
-	h.userPaths = append(h.userPaths, path)
+	h.userPaths = append(h.userPaths, restrictedPrefix(path))
 	h.register(path, handler, methods)
 }
 
@@ -215,10 +233,19 @@ func (h *PermissionsHandler) AttachAdminRestrictedHandlerFunc(
 	handler http.HandlerFunc,
 	methods ...string,
 ) {
-	h.adminPaths = append(h.adminPaths, path)
+	h.adminPaths = append(h.adminPaths, restrictedPrefix(path))
 	h.register(path, handler, methods)
 }
 
+// restrictedPrefix returns the portion of the given route pattern before any
+// URL parameters, so that restrictions apply to every path the route matches
+func restrictedPrefix(pattern string) string {
+	if i := strings.Index(pattern, "{"); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
 func (h *PermissionsHandler) register(path string, handler http.HandlerFunc, methods []string) {
 	if len(methods) == 0 {
 		h.mux.HandleFunc(path, handler)
@@ -33,6 +33,7 @@ func getTestPermissionsHandler(dir string) (*PermissionsHandler, error) {
 	return NewPermissionsHandler(
 		path.Join(dir, "users.db"),
 		"127.0.0.1", 3000,
+		nil,
 		crypto.GetFakeAPIKey,
 	)
 }
@@ -80,6 +81,9 @@ func TestServeHTTPWithUserReject(t *testing.T) {
 	ph.AttachUserRestrictedHandlerFunc("/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}), http.MethodPost)
+	ph.AttachAdminRestrictedHandlerFunc("/params/{name}/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), http.MethodPost)
 
 	// Without token
 	req, err := http.NewRequest("POST", ts.URL+"/test", nil)
@@ -89,6 +93,14 @@ func TestServeHTTPWithUserReject(t *testing.T) {
 	defer resp.Body.Close()
 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
+	// Routes with URL parameters should be restricted as well
+	paramReq, err := http.NewRequest("POST", ts.URL+"/params/wow/test", nil)
+	assert.NoError(t, err)
+	paramResp, err := http.DefaultClient.Do(paramReq)
+	assert.NoError(t, err)
+	defer paramResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, paramResp.StatusCode)
+
 	// With malformed token
 	req.Header.Set("Authorization", "Bearer badtoken")
 	resp, err = http.DefaultClient.Do(req)
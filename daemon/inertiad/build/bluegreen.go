@@ -0,0 +1,195 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	docker "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+)
+
+const (
+	// defaultReadyGracePeriod is how long a container without a health check
+	// must stay up for to be considered ready, if Config.ReadyGracePeriod is
+	// not set
+	defaultReadyGracePeriod = 5 * time.Second
+
+	// defaultReadyTimeout is how long to wait for a container with a health
+	// check to report itself as healthy, if Config.ReadyTimeout is not set
+	defaultReadyTimeout = 2 * time.Minute
+)
+
+// ValidateBlueGreenPorts checks that the given port mappings can be used by
+// blue-green deploys. The new container runs alongside the active one until it
+// is ready, so the two can't both hold the same host port - ports may only be
+// published on host ports picked by Docker.
+func ValidateBlueGreenPorts(ports []string) error {
+	_, portMap, err := nat.ParsePortSpecs(ports)
+	if err != nil {
+		return fmt.Errorf("invalid port mapping: %s", err.Error())
+	}
+	var fixed = []string{}
+	for port, bindings := range portMap {
+		for _, binding := range bindings {
+			if binding.HostPort != "" {
+				fixed = append(fixed, binding.HostPort+":"+string(port))
+			}
+		}
+	}
+	if len(fixed) > 0 {
+		sort.Strings(fixed)
+		return fmt.Errorf("blue-green deploys cannot publish fixed host ports, since the new "+
+			"and active containers would both need them (%s)", strings.Join(fixed, ", "))
+	}
+	return nil
+}
+
+// blueGreenDeploy starts a new project container alongside the project's
+// active container, waits for it to become ready, and only then retires the
+// active container and renames the new container to take its place. If the
+// new container fails to become ready, the active container is left
+// untouched. The new container is started with the project's full
+// configuration, so it must not publish fixed host ports - see
+// ValidateBlueGreenPorts.
+func (b *Builder) blueGreenDeploy(
+	ctx context.Context,
+	cli *docker.Client,
+	d Config,
+	conf *container.Config,
+	host *container.HostConfig,
+	out io.Writer,
+) error {
+	var (
+		name       = d.Name
+		removeOpts = types.ContainerRemoveOptions{Force: true}
+	)
+
+	// Look for an active container to swap out - if there is none, there is
+	// nothing to be careful about
+	active, err := cli.ContainerInspect(ctx, name)
+	if err != nil && !docker.IsErrNotFound(err) {
+		return err
+	}
+	if err != nil || active.State == nil || !active.State.Running {
+		if err == nil {
+			cli.ContainerRemove(ctx, active.ID, removeOpts)
+		}
+		fmt.Fprintln(out, "No active container found")
+		return b.createAndRun(ctx, cli, name, conf, host, out)
+	}
+
+	// Start the new container alongside the active one
+	var candidateName = name + "-candidate"
+	cli.ContainerRemove(ctx, candidateName, removeOpts)
+	reportProjectContainerCreateBegin(candidateName, out)
+	resp, err := cli.ContainerCreate(ctx, conf, host, nil, candidateName)
+	if err != nil {
+		return err
+	}
+	if len(resp.Warnings) > 0 {
+		cli.ContainerRemove(ctx, resp.ID, removeOpts)
+		return errors.New(strings.Join(resp.Warnings, "\n"))
+	}
+	reportProjectContainerCreateComplete(candidateName, out)
+	if err := b.run(ctx, cli, candidateName, resp.ID, out); err != nil {
+		cli.ContainerRemove(ctx, resp.ID, removeOpts)
+		return err
+	}
+
+	// Wait for the new container before touching the active container
+	fmt.Fprintf(out, "Waiting for %s to become ready...\n", candidateName)
+	if err := waitUntilReady(ctx, cli, resp.ID, d.ReadyTimeout, d.ReadyGracePeriod); err != nil {
+		fmt.Fprintf(out, "%s failed to become ready - keeping active container %s\n",
+			candidateName, active.Name)
+		cli.ContainerRemove(ctx, resp.ID, removeOpts)
+		return fmt.Errorf("new container failed to become ready: %s", err.Error())
+	}
+	fmt.Fprintf(out, "%s is ready - retiring active container %s\n", candidateName, active.Name)
+
+	// Retire the active container and swap in the new one
+	if err := containers.StopAndArchive(cli, active.ID, active.Name, out); err != nil {
+		cli.ContainerRemove(ctx, resp.ID, removeOpts)
+		return err
+	}
+	return cli.ContainerRename(ctx, resp.ID, name)
+}
+
+// createAndRun creates a container with the given configuration and starts it
+func (b *Builder) createAndRun(
+	ctx context.Context,
+	cli *docker.Client,
+	name string,
+	conf *container.Config,
+	host *container.HostConfig,
+	out io.Writer,
+) error {
+	reportProjectContainerCreateBegin(name, out)
+	resp, err := cli.ContainerCreate(ctx, conf, host, nil, name)
+	if err != nil {
+		return err
+	}
+	if len(resp.Warnings) > 0 {
+		return errors.New(strings.Join(resp.Warnings, "\n"))
+	}
+	reportProjectContainerCreateComplete(name, out)
+	return b.run(ctx, cli, name, resp.ID, out)
+}
+
+// waitUntilReady blocks until the given container is ready. If the container
+// has a HEALTHCHECK configured, it must report itself as healthy within the
+// given timeout - otherwise, it must stay up for the given grace period. Zero
+// durations are replaced by defaults.
+func waitUntilReady(
+	ctx context.Context,
+	cli *docker.Client,
+	id string,
+	timeout, gracePeriod time.Duration,
+) error {
+	if timeout == 0 {
+		timeout = defaultReadyTimeout
+	}
+	if gracePeriod == 0 {
+		gracePeriod = defaultReadyGracePeriod
+	}
+	var start = time.Now()
+	for {
+		c, err := cli.ContainerInspect(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.State == nil {
+			return errors.New("unable to determine container state")
+		}
+		if !c.State.Running {
+			return fmt.Errorf("container exited with status %d", c.State.ExitCode)
+		}
+
+		if c.State.Health != nil {
+			switch c.State.Health.Status {
+			case types.Healthy:
+				return nil
+			case types.Unhealthy:
+				return errors.New("container reported itself as unhealthy")
+			}
+			if time.Since(start) > timeout {
+				return errors.New("timed out waiting for container to become healthy")
+			}
+		} else if time.Since(start) > gracePeriod {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"path"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -21,7 +21,8 @@ import (
 // ContainerBuilder builds projects and returns a callback that can be used to deploy the project.
 // No relation to Bob the Builder, though a Bob did write this.
 type ContainerBuilder interface {
-	Build(string, Config, *docker.Client, io.Writer) (func() error, error)
+	Build(context.Context, string, Config, *docker.Client, io.Writer) (func() error, error)
+	RunHook(context.Context, string, Hook, Config, *docker.Client, io.Writer) error
 	GetBuildStageName() string
 	StopContainers(*docker.Client, io.Writer) error
 	Prune(*docker.Client, io.Writer) error
@@ -30,33 +31,37 @@ type ContainerBuilder interface {
 
 // ProjectBuilder builds projects and returns a callback that can be used to deploy the project.
 // No relation to Bob the Builder, though a Bob did write this.
-type ProjectBuilder func(Config, *docker.Client, io.Writer) (func() error, error)
+type ProjectBuilder func(context.Context, Config, *docker.Client, io.Writer) (func() error, error)
 
 // Builder manages build tools and executes builds
 type Builder struct {
 	buildStageName       string
 	dockerComposeVersion string
 	stopper              containers.ContainerStopper
+	logger               *log.Logger
 
 	builders map[string]ProjectBuilder
 }
 
 // NewBuilder creates a builder with given configuration
-func NewBuilder(conf cfg.Config, stopper containers.ContainerStopper) *Builder {
+func NewBuilder(conf cfg.Config, stopper containers.ContainerStopper, logger *log.Logger) *Builder {
 	b := &Builder{
 		buildStageName:       "build",
 		dockerComposeVersion: conf.DockerComposeVersion,
 		stopper:              stopper,
+		logger:               logger,
 	}
 	b.builders = map[string]ProjectBuilder{
 		"dockerfile":     b.dockerBuild,
 		"docker-compose": b.dockerCompose,
+		"image":          b.imageBuild,
+		"buildpack":      b.buildpackBuild,
 	}
 	return b
 }
 
-// GetBuildStageName returns the name of the intermediary container used to
-// build projects
+// GetBuildStageName returns the stage name of the intermediary container used
+// to build projects
 func (b *Builder) GetBuildStageName() string { return b.buildStageName }
 
 // StopContainers stops containers and cleans up assets
@@ -74,6 +79,17 @@ func (b *Builder) PruneAll(docker *docker.Client, out io.Writer) error {
 	return containers.PruneAll(docker, b.dockerComposeVersion)
 }
 
+const (
+	// StrategyRecreate denotes deploys that stop the active project containers
+	// before building
+	StrategyRecreate = "recreate"
+	// StrategyBlueGreen denotes deploys that start the new project container
+	// alongside the active one, and only retire the active container once the
+	// new one is ready. Only supported for Dockerfile, image, and buildpack
+	// projects that don't publish fixed host ports.
+	StrategyBlueGreen = "blue-green"
+)
+
 // Config contains parameters required for builds to execute
 type Config struct {
 	Name string
@@ -81,28 +97,71 @@ type Config struct {
 	BuildFilePath    string
 	BuildDirectory   string
 	PersistDirectory string
+	DeployStrategy   string
+
+	// ReadyTimeout and ReadyGracePeriod configure how long blue-green deploys
+	// wait for new containers to become ready. Defaults are used if they are
+	// not set.
+	ReadyTimeout     time.Duration
+	ReadyGracePeriod time.Duration
+
+	// Image and RegistryAuth are used by image builds. RegistryAuth is the
+	// base64-encoded registry credentials expected by the Docker API, if any.
+	Image        string
+	RegistryAuth string
+
+	// BuildArgs and Target configure Dockerfile builds
+	BuildArgs map[string]*string
+	Target    string
+
+	// Ports are "[ip:]hostPort:containerPort[/protocol]" mappings to publish
+	// instead of every port the project image exposes. Ports and Labels are
+	// applied to Dockerfile, image, and buildpack project containers.
+	Ports  []string
+	Labels map[string]string
+
+	// Resources limits the resources available to project containers, as well
+	// as to the containers used to build projects
+	Resources container.Resources
+
+	// ComposeFiles, ComposeProfiles, and ComposeProjectName configure
+	// docker-compose projects. ComposeFiles are used instead of BuildFilePath
+	// if provided, and ComposeProjectName defaults to Name.
+	ComposeFiles       []string
+	ComposeProfiles    []string
+	ComposeProjectName string
 
 	EnvValues []string
 }
 
-// Build executes build and deploy
-func (b *Builder) Build(buildType string, d Config,
+// Build executes build and deploy. Cancelling the given context aborts the
+// build, as well as the returned deploy callback.
+func (b *Builder) Build(ctx context.Context, buildType string, d Config,
 	cli *docker.Client, out io.Writer) (func() error, error) {
-	// Use the appropriate build method
+	// Use the appropriate build method, guessing at the project type if the
+	// given one is not supported
 	builder, found := b.builders[strings.ToLower(buildType)]
 	if !found {
-		// @todo: attempt a guess at project type instead
-		fmt.Println(out, "Unknown project type "+buildType)
-		fmt.Println(out, "Defaulting to docker-compose build")
-		builder = b.dockerCompose
+		detected, err := DetectBuildType(d.BuildDirectory)
+		if err != nil {
+			return func() error { return nil }, fmt.Errorf("unknown project type '%s': %s",
+				buildType, err.Error())
+		}
+		fmt.Fprintf(out, "Unknown project type '%s' - detected %s project\n", buildType, detected)
+		buildType, builder = detected, b.builders[detected]
 	}
 
 	// Build project
+	var logger = b.logger.With("project", d.Name, "build_type", buildType)
+	var start = time.Now()
+	logger.Info("building project")
 	reportDeployInit(buildType, d.Name, out)
-	deploy, err := builder(d, cli, out)
+	deploy, err := builder(ctx, d, cli, out)
 	if err != nil {
+		logger.Warn("project build failed", "duration", time.Since(start), "error", err)
 		return func() error { return nil }, err
 	}
+	logger.Info("project built", "duration", time.Since(start))
 
 	// Return the deploy callback
 	return deploy, nil
@@ -122,15 +181,9 @@ func (b *Builder) Build(buildType string, d Config,
 // separate from the daemon and the user's project, and is the
 // second container to require access to the docker socket.
 // See https://cloud.google.com/community/tutorials/docker-compose-on-container-optimized-os
-func (b *Builder) dockerCompose(d Config, cli *docker.Client,
+func (b *Builder) dockerCompose(ctx context.Context, d Config, cli *docker.Client,
 	out io.Writer) (func() error, error) {
 	fmt.Fprintln(out, "Setting up docker-compose...")
-	ctx := context.Background()
-
-	dockercomposeFilePath := "docker-compose.yml"
-	if d.BuildFilePath != "" {
-		dockercomposeFilePath = d.BuildFilePath
-	}
 
 	// set up bindings
 	binds := []string{
@@ -142,21 +195,23 @@ func (b *Builder) dockerCompose(d Config, cli *docker.Client,
 	}
 
 	// set up docker-compose runner
+	removeStaleContainer(ctx, cli, d.Name+"-"+b.buildStageName)
 	resp, err := cli.ContainerCreate(
 		ctx, &container.Config{
 			Image:      b.dockerComposeVersion,
 			WorkingDir: "/build",
-			Cmd: []string{
-				"-p", d.Name,
-				"-f", dockercomposeFilePath,
-				"build",
+			Cmd:        composeCommand(d, "build"),
+			Env:        d.EnvValues,
+			Labels: map[string]string{
+				containers.LabelProject: d.Name,
+				containers.LabelStage:   b.buildStageName,
 			},
-			Env: d.EnvValues,
 		},
 		&container.HostConfig{
 			AutoRemove: true,
 			Binds:      binds,
-		}, nil, b.buildStageName,
+			Resources:  d.Resources,
+		}, nil, d.Name+"-"+b.buildStageName,
 	)
 	if err != nil {
 		return nil, err
@@ -169,39 +224,34 @@ func (b *Builder) dockerCompose(d Config, cli *docker.Client,
 
 	// Start container to build project
 	reportProjectBuildBegin(d.Name, out)
-	if err := containers.StartAndWait(cli, resp.ID, out); err != nil {
+	if err := containers.StartAndWait(ctx, cli, resp.ID, out); err != nil {
 		return nil, err
 	}
 	reportProjectBuildComplete(d.Name, out)
 
-	// @TODO allow configuration
-	var (
-		dockerComposeRelFilePath = "docker-compose.yml"
-		dockerComposeFilePath    = path.Join(
-			getTrueDirectory(d.BuildDirectory), dockerComposeRelFilePath,
-		)
-	)
-
-	// Set up docker-compose up
+	// Set up docker-compose up, using the same compose files as the build -
+	// the project directory is mounted so that overrides and any files they
+	// reference are available
 	reportProjectContainerCreateBegin(d.Name, out)
+	removeStaleContainer(ctx, cli, d.Name+"-docker-compose")
 	resp, err = cli.ContainerCreate(
 		ctx, &container.Config{
 			Image:      b.dockerComposeVersion,
 			WorkingDir: "/build",
-			Cmd: []string{
-				"-p", d.Name,
-				"-f", dockercomposeFilePath,
-				"up",
+			Cmd:        composeCommand(d, "up"),
+			Env:        d.EnvValues,
+			Labels: map[string]string{
+				containers.LabelProject: d.Name,
 			},
-			Env: d.EnvValues,
 		},
 		&container.HostConfig{
 			AutoRemove: true,
 			Binds: []string{
-				dockerComposeFilePath + ":/build/docker-compose.yml",
+				getTrueDirectory(d.BuildDirectory) + ":/build",
 				"/var/run/docker.sock:/var/run/docker.sock",
 			},
-		}, nil, "docker-compose",
+			Resources: d.Resources,
+		}, nil, d.Name+"-docker-compose",
 	)
 	if err != nil {
 		return nil, err
@@ -215,33 +265,77 @@ func (b *Builder) dockerCompose(d Config, cli *docker.Client,
 	return func() error { return b.run(ctx, cli, d.Name, resp.ID, out) }, nil
 }
 
+// composeCommand returns the arguments for the given docker-compose command,
+// preceded by the options for the given project - builds, deploys, and hooks
+// all use the same compose files, profiles, and project name
+func composeCommand(d Config, command ...string) []string {
+	var project = d.Name
+	if d.ComposeProjectName != "" {
+		project = d.ComposeProjectName
+	}
+	var args = []string{"-p", project}
+	for _, f := range composeFiles(d) {
+		args = append(args, "-f", f)
+	}
+	for _, p := range d.ComposeProfiles {
+		args = append(args, "--profile", p)
+	}
+	return append(args, command...)
+}
+
 // dockerBuild builds project from Dockerfile, and returns a callback function to deploy it
-func (b *Builder) dockerBuild(d Config, cli *docker.Client,
+func (b *Builder) dockerBuild(ctx context.Context, d Config, cli *docker.Client,
 	out io.Writer) (func() error, error) {
-	var (
-		ctx      = context.Background()
-		buildCtx = bytes.NewBuffer(nil)
-	)
+	return b.buildAndDeployImage(ctx, d, nil, cli, out)
+}
 
-	// Create build context
-	if err := buildTar(d.BuildDirectory, buildCtx); err != nil {
-		return nil, err
-	}
+// buildAndDeployImage builds the project image using the project's Dockerfile,
+// or the given generated Dockerfile if there is one, and returns a callback
+// function to deploy it
+func (b *Builder) buildAndDeployImage(ctx context.Context, d Config, dockerfile []byte,
+	cli *docker.Client, out io.Writer) (func() error, error) {
+	var buildCtx = bytes.NewBuffer(nil)
 
 	// @TODO: support configuration
 	dockerFilePath := "Dockerfile"
 	if d.BuildFilePath != "" {
 		dockerFilePath = d.BuildFilePath
 	}
+	var extraFiles map[string][]byte
+	if dockerfile != nil {
+		dockerFilePath = generatedDockerfileName
+		extraFiles = map[string][]byte{generatedDockerfileName: dockerfile}
+	}
+
+	// Create build context, leaving out files in .dockerignore
+	ignore, err := readDockerignore(d.BuildDirectory, dockerFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %s", err.Error())
+	}
+	if err := buildTar(d.BuildDirectory, ignore, extraFiles, buildCtx); err != nil {
+		return nil, err
+	}
 
-	// Build image
+	// Build image - generated Dockerfiles have no stages to target
+	var target = d.Target
+	if dockerfile != nil {
+		target = ""
+	}
 	reportProjectBuildBegin(d.Name, out)
 	imageName := "inertia-build/" + d.Name
 	buildResp, err := cli.ImageBuild(
 		ctx, buildCtx, types.ImageBuildOptions{
 			Tags:           []string{imageName},
+			Labels:         map[string]string{containers.LabelProject: d.Name},
 			Remove:         true,
 			Dockerfile:     dockerFilePath,
+			BuildArgs:      d.BuildArgs,
+			Target:         target,
+			Memory:         d.Resources.Memory,
+			MemorySwap:     d.Resources.MemorySwap,
+			CPUShares:      d.Resources.CPUShares,
+			CPUQuota:       d.Resources.CPUQuota,
+			CPUPeriod:      d.Resources.CPUPeriod,
 			SuppressOutput: false,
 		},
 	)
@@ -253,33 +347,79 @@ func (b *Builder) dockerBuild(d Config, cli *docker.Client,
 	close(stop)
 	buildResp.Body.Close()
 	// Get image details - this will check if image build was successful
-	image, _, err := cli.ImageInspectWithRaw(ctx, imageName)
-	if err != nil {
+	if _, _, err := cli.ImageInspectWithRaw(ctx, imageName); err != nil {
 		return nil, fmt.Errorf("image build failed: %s", err.Error())
 	}
-	portMap := nat.PortMap{}
-	for p := range image.Config.ExposedPorts {
-		portMap[p] = []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: p.Port()}}
-	}
 	reportProjectBuildComplete(d.Name, out)
 
+	return b.deployImage(ctx, cli, d, imageName, out)
+}
+
+// deployImage creates the project container from the given image, publishing
+// the configured ports or otherwise every port it exposes, and returns a
+// callback function to deploy it. Blue-green deploys only publish configured
+// ports, which must not be fixed host ports.
+func (b *Builder) deployImage(ctx context.Context, cli *docker.Client, d Config,
+	imageName string, out io.Writer) (func() error, error) {
+	if d.DeployStrategy == StrategyBlueGreen {
+		if err := ValidateBlueGreenPorts(d.Ports); err != nil {
+			return nil, err
+		}
+	}
+	exposedPorts, portMap, err := nat.ParsePortSpecs(d.Ports)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port mapping: %s", err.Error())
+	}
+	if len(d.Ports) == 0 && d.DeployStrategy != StrategyBlueGreen {
+		image, _, err := cli.ImageInspectWithRaw(ctx, imageName)
+		if err != nil {
+			return nil, err
+		}
+		for p := range image.Config.ExposedPorts {
+			portMap[p] = []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: p.Port()}}
+		}
+	}
+
+	// inertia labels take precedence over configured ones
+	labels := map[string]string{}
+	for k, v := range d.Labels {
+		labels[k] = v
+	}
+	labels[containers.LabelProject] = d.Name
+
 	// set up bindings
 	binds := []string{}
 	if d.PersistDirectory != "" {
 		binds = append(binds, getTrueDirectory(d.PersistDirectory)+":/persist")
 	}
 
+	var (
+		containerConfig = &container.Config{
+			Image:        imageName,
+			Env:          d.EnvValues,
+			ExposedPorts: exposedPorts,
+			Labels:       labels,
+		}
+		hostConfig = &container.HostConfig{
+			Binds:        binds,
+			PortBindings: portMap,
+			Resources:    d.Resources,
+		}
+	)
+
+	// Blue-green deploys create containers only once the active container is
+	// ready to be swapped out
+	if d.DeployStrategy == StrategyBlueGreen {
+		return func() error {
+			return b.blueGreenDeploy(ctx, cli, d, containerConfig, hostConfig, out)
+		}, nil
+	}
+
 	// Create container from image
 	reportProjectContainerCreateBegin(d.Name, out)
+	removeStaleContainer(ctx, cli, d.Name)
 	containerResp, err := cli.ContainerCreate(
-		ctx, &container.Config{
-			Image: imageName,
-			Env:   d.EnvValues,
-		},
-		&container.HostConfig{
-			Binds:        binds,
-			PortBindings: portMap,
-		}, nil, d.Name)
+		ctx, containerConfig, hostConfig, nil, d.Name)
 	if err != nil {
 		if strings.Contains(err.Error(), "No such image") {
 			return nil, errors.New("Image build was unsuccessful")
@@ -300,3 +440,13 @@ func (b *Builder) run(ctx context.Context, client *docker.Client, name, id strin
 	reportProjectStartup(name, out)
 	return client.ContainerStart(ctx, id, types.ContainerStartOptions{})
 }
+
+// removeStaleContainer removes the named container if it exists but is not
+// running, which happens if a deploy is aborted after its containers have been
+// created but before they have been started
+func removeStaleContainer(ctx context.Context, cli *docker.Client, name string) {
+	c, err := cli.ContainerInspect(ctx, name)
+	if err == nil && c.State != nil && !c.State.Running {
+		cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{})
+	}
+}
@@ -21,10 +21,51 @@ import (
 )
 
 func TestNewBuilder(t *testing.T) {
-	b := NewBuilder(cfg.Config{}, nil)
+	b := NewBuilder(cfg.Config{}, nil, nil)
 	assert.NotNil(t, b)
 }
 
+func Test_composeCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		conf Config
+		want []string
+	}{
+		{"defaults", Config{Name: "myproject"},
+			[]string{"-p", "myproject", "-f", "docker-compose.yml", "up"}},
+		{"build file", Config{Name: "myproject", BuildFilePath: "compose.yml"},
+			[]string{"-p", "myproject", "-f", "compose.yml", "up"}},
+		{"compose files and profiles", Config{
+			Name:               "myproject",
+			BuildFilePath:      "compose.yml",
+			ComposeFiles:       []string{"docker-compose.yml", "docker-compose.prod.yml"},
+			ComposeProfiles:    []string{"workers"},
+			ComposeProjectName: "myproject_prod",
+		}, []string{
+			"-p", "myproject_prod",
+			"-f", "docker-compose.yml", "-f", "docker-compose.prod.yml",
+			"--profile", "workers", "up",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, composeCommand(tt.conf, "up"))
+		})
+	}
+}
+
+func TestBuilder_deployImage_blueGreenPublishedPorts(t *testing.T) {
+	// Published ports are rejected before anything is done with Docker
+	b := NewBuilder(cfg.Config{}, nil, nil)
+	_, err := b.deployImage(context.Background(), nil, Config{
+		Name:           "myproject",
+		Ports:          []string{"8080", "80:80"},
+		DeployStrategy: StrategyBlueGreen,
+	}, "myproject-image", os.Stdout)
+	assert.EqualError(t, err, "blue-green deploys cannot publish fixed host ports, "+
+		"since the new and active containers would both need them (80:80/tcp)")
+}
+
 // killTestContainers is a helper for tests - it implements project.ContainerStopper
 func killTestContainers(cli *docker.Client, w io.Writer) error {
 	ctx := context.Background()
@@ -94,7 +135,7 @@ func TestBuilder_Build(t *testing.T) {
 
 				b = NewBuilder(cfg.Config{
 					DockerComposeVersion: "docker/compose:latest",
-				}, killTestContainers)
+				}, killTestContainers, nil)
 				out = os.Stdout
 			)
 
@@ -106,7 +147,7 @@ func TestBuilder_Build(t *testing.T) {
 			// Run build
 			t.Logf("Preparing to build test project with name '%s' from directory '%s'",
 				testProjectName, testProjectDir)
-			deploy, err := b.Build(tt.args.buildType, Config{
+			deploy, err := b.Build(context.Background(), tt.args.buildType, Config{
 				Name:             testProjectName,
 				BuildFilePath:    tt.args.buildFilePath,
 				BuildDirectory:   testProjectDir,
@@ -0,0 +1,169 @@
+package build
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	docker "github.com/docker/docker/client"
+
+	"github.com/ubclaunchpad/inertia/common"
+)
+
+// generatedDockerfileName is the name given to Dockerfiles generated for
+// buildpack projects in the build context
+const generatedDockerfileName = "Dockerfile.inertia"
+
+// buildpackPort is the port that buildpack projects are expected to serve on,
+// and is provided to them as the PORT environment variable
+const buildpackPort = "8080"
+
+// dockerfileTemplates are Dockerfiles for each detected language, excluding
+// the final command, which is generated separately
+var dockerfileTemplates = map[common.Language]string{
+	common.LanguageGo: `FROM golang:1.15-alpine AS build
+WORKDIR /src
+COPY . .
+RUN CGO_ENABLED=0 go build -o /bin/app .
+
+FROM alpine
+RUN apk add --no-cache ca-certificates
+WORKDIR /app
+COPY --from=build /bin/app /bin/app
+ENV PORT=` + buildpackPort + `
+EXPOSE ` + buildpackPort + `
+`,
+	common.LanguageNode: `FROM node:14-alpine
+WORKDIR /app
+COPY package*.json ./
+RUN npm install --production
+COPY . .
+ENV NODE_ENV=production PORT=` + buildpackPort + `
+EXPOSE ` + buildpackPort + `
+`,
+	common.LanguagePython: `FROM python:3.9
+WORKDIR /app
+COPY requirements.txt ./
+RUN pip install --no-cache-dir -r requirements.txt
+COPY . .
+ENV PYTHONUNBUFFERED=1 PORT=` + buildpackPort + `
+EXPOSE ` + buildpackPort + `
+`,
+	common.LanguageRuby: `FROM ruby:2.7
+WORKDIR /app
+COPY Gemfile Gemfile.lock* ./
+RUN bundle install
+COPY . .
+ENV RACK_ENV=production PORT=` + buildpackPort + `
+EXPOSE ` + buildpackPort + `
+`,
+	common.LanguageStatic: `FROM nginx:alpine
+COPY . /usr/share/nginx/html
+RUN rm -rf /usr/share/nginx/html/.git
+EXPOSE 80
+`,
+}
+
+// DetectBuildType guesses the build type of the project in the given
+// directory, preferring Docker configuration over generated Dockerfiles
+func DetectBuildType(dir string) (string, error) {
+	// docker-compose projects will usually have Dockerfiles, so check for
+	// docker-compose.yml first, then check for Dockerfile
+	if common.CheckForDockerCompose(dir) {
+		return "docker-compose", nil
+	}
+	if common.CheckForDockerfile(dir) {
+		return "dockerfile", nil
+	}
+	if _, found := common.DetectLanguage(dir); found {
+		return "buildpack", nil
+	}
+	return "", errors.New("no Docker configuration or supported language detected")
+}
+
+// buildpackBuild builds projects that have no Docker configuration using a
+// Dockerfile generated for the project's language, and returns a callback
+// function to deploy it
+func (b *Builder) buildpackBuild(ctx context.Context, d Config, cli *docker.Client,
+	out io.Writer) (func() error, error) {
+	language, dockerfile, err := generateDockerfile(d.BuildDirectory)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "Detected %s project - building with generated Dockerfile:\n", language)
+	fmt.Fprint(out, string(dockerfile))
+
+	return b.buildAndDeployImage(ctx, d, dockerfile, cli, out)
+}
+
+// generateDockerfile generates a Dockerfile for the project in the given
+// directory based on its language. Projects are started using the 'web'
+// process in their Procfile if they have one, or a language default otherwise.
+func generateDockerfile(dir string) (common.Language, []byte, error) {
+	language, found := common.DetectLanguage(dir)
+	if !found {
+		return "", nil, errors.New("could not detect project language - supported projects " +
+			"have a go.mod, package.json, requirements.txt, Gemfile, or index.html")
+	}
+
+	var dockerfile = dockerfileTemplates[language]
+	if language == common.LanguageStatic {
+		return language, []byte(dockerfile), nil
+	}
+	if cmd, found := procfileCommand(dir); found {
+		return language, []byte(dockerfile + "CMD " + cmd + "\n"), nil
+	}
+	switch language {
+	case common.LanguageGo:
+		dockerfile += `CMD ["/bin/app"]` + "\n"
+	case common.LanguageNode:
+		dockerfile += `CMD ["npm", "start"]` + "\n"
+	case common.LanguagePython:
+		var entrypoint string
+		for _, f := range []string{"app.py", "main.py", "manage.py"} {
+			if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+				entrypoint = f
+				break
+			}
+		}
+		switch entrypoint {
+		case "":
+			return language, nil, errors.New("could not find an app.py, main.py, or manage.py " +
+				"to run - add a Procfile with a 'web' process to configure how to start your project")
+		case "manage.py":
+			dockerfile += `CMD ["python", "manage.py", "runserver", "0.0.0.0:` + buildpackPort + `"]` + "\n"
+		default:
+			dockerfile += `CMD ["python", "` + entrypoint + `"]` + "\n"
+		}
+	case common.LanguageRuby:
+		dockerfile += `CMD ["bundle", "exec", "rackup", "--host", "0.0.0.0", "--port", "` +
+			buildpackPort + `"]` + "\n"
+	}
+	return language, []byte(dockerfile), nil
+}
+
+// procfileCommand retrieves the command for the 'web' process in the
+// Procfile in the given directory, if there is one
+func procfileCommand(dir string) (string, bool) {
+	f, err := os.Open(filepath.Join(dir, "Procfile"))
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	var scanner = bufio.NewScanner(f)
+	for scanner.Scan() {
+		var parts = strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "web" {
+			if cmd := strings.TrimSpace(parts[1]); cmd != "" {
+				return cmd, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,126 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/common"
+)
+
+// writeTestProject creates a project directory containing the given files
+func writeTestProject(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "inertia-buildpack")
+	assert.NoError(t, err)
+	for name, contents := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	return dir
+}
+
+func TestDetectBuildType(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"docker-compose", map[string]string{"docker-compose.yml": "", "Dockerfile": ""}, "docker-compose", false},
+		{"dockerfile", map[string]string{"Dockerfile": "", "go.mod": ""}, "dockerfile", false},
+		{"buildpack", map[string]string{"go.mod": ""}, "buildpack", false},
+		{"unknown", map[string]string{"README.md": ""}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestProject(t, tt.files)
+			defer os.RemoveAll(dir)
+
+			got, err := DetectBuildType(dir)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_generateDockerfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		wantLanguage common.Language
+		wantCmd      string
+		wantErr      bool
+	}{
+		{"go", map[string]string{"go.mod": ""}, common.LanguageGo, `CMD ["/bin/app"]`, false},
+		{"node", map[string]string{"package.json": ""}, common.LanguageNode, `CMD ["npm", "start"]`, false},
+		{"python", map[string]string{"requirements.txt": "", "main.py": ""},
+			common.LanguagePython, `CMD ["python", "main.py"]`, false},
+		{"django", map[string]string{"requirements.txt": "", "manage.py": ""},
+			common.LanguagePython, `CMD ["python", "manage.py", "runserver", "0.0.0.0:8080"]`, false},
+		{"python without entrypoint", map[string]string{"requirements.txt": ""}, common.LanguagePython, "", true},
+		{"ruby", map[string]string{"Gemfile": ""}, common.LanguageRuby, `CMD ["bundle", "exec", "rackup"`, false},
+		{"static", map[string]string{"index.html": ""}, common.LanguageStatic, "FROM nginx", false},
+		{"procfile", map[string]string{"requirements.txt": "", "Procfile": "worker: celery\nweb: gunicorn app:app\n"},
+			common.LanguagePython, "CMD gunicorn app:app", false},
+		{"unknown", map[string]string{"README.md": ""}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestProject(t, tt.files)
+			defer os.RemoveAll(dir)
+
+			language, dockerfile, err := generateDockerfile(dir)
+			assert.Equal(t, tt.wantLanguage, language)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, string(dockerfile), tt.wantCmd)
+		})
+	}
+}
+
+func Test_buildTar(t *testing.T) {
+	dir := writeTestProject(t, map[string]string{"main.go": "package main"})
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/master"), 0644))
+
+	var buf bytes.Buffer
+	assert.NoError(t, buildTar(dir, nil, map[string][]byte{
+		generatedDockerfileName: []byte("FROM alpine"),
+	}, &buf))
+
+	gzr, err := gzip.NewReader(&buf)
+	assert.NoError(t, err)
+	var tr = tar.NewReader(gzr)
+	var files = map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		contents, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		files[header.Name] = string(contents)
+	}
+	assert.Equal(t, "package main", files["main.go"])
+	assert.Equal(t, "FROM alpine", files[generatedDockerfileName])
+
+	// git metadata is never included
+	for name := range files {
+		assert.NotContains(t, name, ".git")
+	}
+}
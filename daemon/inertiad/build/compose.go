@@ -0,0 +1,71 @@
+package build
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// composeFiles returns the compose files used by the given docker-compose
+// project, relative to its build directory
+func composeFiles(d Config) []string {
+	if len(d.ComposeFiles) > 0 {
+		return d.ComposeFiles
+	}
+	if d.BuildFilePath != "" {
+		return []string{d.BuildFilePath}
+	}
+	return []string{"docker-compose.yml"}
+}
+
+// composeService is the subset of a docker-compose service definition needed
+// to find published ports
+type composeService struct {
+	Ports []interface{} `yaml:"ports"`
+}
+
+// ComposePublishedPorts lists the ports the services of the given
+// docker-compose project publish on fixed host ports, as "service: mapping".
+// Ports published on random host ports are not included.
+func ComposePublishedPorts(d Config) ([]string, error) {
+	var published []string
+	for _, f := range composeFiles(d) {
+		bytes, err := ioutil.ReadFile(filepath.Join(d.BuildDirectory, f))
+		if err != nil {
+			return nil, err
+		}
+		var file struct {
+			Services map[string]composeService `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(bytes, &file); err != nil {
+			return nil, fmt.Errorf("invalid compose file '%s': %s", f, err.Error())
+		}
+		for name, service := range file.Services {
+			for _, p := range service.Ports {
+				if mapping, ok := publishedPort(p); ok {
+					published = append(published, name+": "+mapping)
+				}
+			}
+		}
+	}
+	sort.Strings(published)
+	return published, nil
+}
+
+// publishedPort checks if the given entry of a service's ports, in either the
+// short "[host:]container" or the long syntax, publishes a fixed host port
+func publishedPort(p interface{}) (string, bool) {
+	switch port := p.(type) {
+	case string:
+		return port, strings.Contains(port, ":")
+	case map[interface{}]interface{}:
+		if published, ok := port["published"]; ok && published != nil && published != "" {
+			return fmt.Sprintf("%v:%v", published, port["target"]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComposePublishedPorts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-compose")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(`
+version: "3.2"
+services:
+  web:
+    build: .
+    ports:
+      - "80"
+      - "8080:80"
+      - target: 443
+        published: 8443
+      - target: 9000
+  db:
+    image: postgres
+`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "internal.yml"), []byte(`
+version: "3.2"
+services:
+  db:
+    image: postgres
+    expose:
+      - "5432"
+`), 0644))
+
+	tests := []struct {
+		name    string
+		conf    Config
+		want    []string
+		wantErr bool
+	}{
+		{"default file", Config{}, []string{"web: 8080:80", "web: 8443:443"}, false},
+		{"no published ports", Config{ComposeFiles: []string{"internal.yml"}}, nil, false},
+		{"missing file", Config{BuildFilePath: "missing.yml"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.BuildDirectory = dir
+			got, err := ComposePublishedPorts(tt.conf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/fileutils"
+)
+
+// readDockerignore reads the .dockerignore file in the given directory, if
+// there is one, into a matcher of files to leave out of the build context. As
+// with the Docker CLI, the given Dockerfile and the .dockerignore itself are
+// always kept in the build context, since Docker requires them.
+func readDockerignore(dir, dockerfile string) (*fileutils.PatternMatcher, error) {
+	f, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fileutils.NewPatternMatcher(nil)
+		}
+		return nil, err
+	}
+	defer f.Close()
+	patterns, err := dockerignore.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	for _, keep := range []string{".dockerignore", dockerfile} {
+		if excluded, _ := fileutils.Matches(keep, patterns); excluded {
+			patterns = append(patterns, "!"+keep)
+		}
+	}
+	return fileutils.NewPatternMatcher(patterns)
+}
+
+// contextExcluded checks if the given path, relative to the context root, is
+// left out of the build context by the given patterns, in the same way as
+// archive.TarWithOptions. Returns filepath.SkipDir for excluded directories
+// that can be skipped entirely - directories that may contain re-included
+// files must still be walked.
+func contextExcluded(pm *fileutils.PatternMatcher, path string, dir bool) (bool, error) {
+	excluded, err := pm.Matches(path)
+	if err != nil || !excluded {
+		return false, err
+	}
+	if !dir {
+		return true, nil
+	}
+	if pm.Exclusions() {
+		var dirSlash = path + string(filepath.Separator)
+		for _, p := range pm.Patterns() {
+			if p.Exclusion() && strings.HasPrefix(p.String()+string(filepath.Separator), dirSlash) {
+				return true, nil
+			}
+		}
+	}
+	return true, filepath.SkipDir
+}
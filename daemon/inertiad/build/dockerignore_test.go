@@ -0,0 +1,93 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_contextExcluded(t *testing.T) {
+	ignore, err := fileutils.NewPatternMatcher([]string{
+		".git",
+		"node_modules",
+		"*.log",
+		"**/*.tmp",
+		"docs/*.md",
+		"!docs/README.md",
+	})
+	assert.NoError(t, err)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".git/HEAD", true},
+		{"node_modules/express/index.js", true},
+		{"server.log", true},
+		{"logs/server.log", false},
+		{"build/cache/a.tmp", true},
+		{"a.tmp", true},
+		{"docs/guide.md", true},
+		{"docs/README.md", false},
+		{"docs/nested/guide.md", false},
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			excluded, err := contextExcluded(ignore, tt.path, false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, excluded)
+		})
+	}
+
+	t.Run("directories with re-included files are walked", func(t *testing.T) {
+		excluded, err := contextExcluded(ignore, ".git", true)
+		assert.True(t, excluded)
+		assert.Equal(t, filepath.SkipDir, err)
+
+		ignore, err := fileutils.NewPatternMatcher([]string{"docs", "!docs/README.md"})
+		assert.NoError(t, err)
+		excluded, err = contextExcluded(ignore, "docs", true)
+		assert.True(t, excluded)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_buildTar_dockerignore(t *testing.T) {
+	dir := writeTestProject(t, map[string]string{
+		".dockerignore": "*\n!main.go\n",
+		"Dockerfile":    "FROM alpine",
+		"main.go":       "package main",
+		"secrets.env":   "KEY=VALUE",
+	})
+	defer os.RemoveAll(dir)
+
+	ignore, err := readDockerignore(dir, "Dockerfile")
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, buildTar(dir, ignore, nil, &buf))
+
+	gzr, err := gzip.NewReader(&buf)
+	assert.NoError(t, err)
+	var tr = tar.NewReader(gzr)
+	var files []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if header.Name != "" {
+			files = append(files, header.Name)
+		}
+	}
+	sort.Strings(files)
+	assert.Equal(t, []string{".dockerignore", "Dockerfile", "main.go"}, files)
+}
@@ -0,0 +1,113 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	docker "github.com/docker/docker/client"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+)
+
+// hookStageName is the stage label applied to hook containers
+const hookStageName = "hook"
+
+// Hook is a command run in a one-off container as part of a deploy
+type Hook struct {
+	Name    string
+	Command []string
+
+	// Service is the docker-compose service to run the command in, and is
+	// required for docker-compose projects
+	Service string
+}
+
+// RunHook runs the given hook in a one-off container using the project's most
+// recently built image, and blocks until it exits. Container output is written
+// to out, and an error is returned if the command does not exit successfully.
+func (b *Builder) RunHook(ctx context.Context, buildType string, hook Hook, d Config,
+	cli *docker.Client, out io.Writer) error {
+	if len(hook.Command) == 0 {
+		return fmt.Errorf("hook '%s' has no command", hook.Name)
+	}
+
+	var (
+		name   = d.Name + "-" + hookStageName
+		conf   *container.Config
+		host   *container.HostConfig
+		labels = map[string]string{
+			containers.LabelProject: d.Name,
+			containers.LabelStage:   hookStageName,
+		}
+	)
+	switch strings.ToLower(buildType) {
+	case "dockerfile", "image", "buildpack":
+		// Run command directly in the project image
+		var image = "inertia-build/" + d.Name
+		if d.Image != "" {
+			image = d.Image
+		}
+		binds := []string{}
+		if d.PersistDirectory != "" {
+			binds = append(binds, getTrueDirectory(d.PersistDirectory)+":/persist")
+		}
+		conf = &container.Config{
+			Image:  image,
+			Cmd:    hook.Command,
+			Env:    d.EnvValues,
+			Labels: labels,
+		}
+		host = &container.HostConfig{AutoRemove: true, Binds: binds, Resources: d.Resources}
+
+	case "docker-compose":
+		// Run command in the service using docker-compose, the same way the
+		// project is built
+		if hook.Service == "" {
+			return fmt.Errorf("hook '%s' must specify a service for docker-compose projects", hook.Name)
+		}
+		cmd := composeCommand(d, "run", "--rm", "-T")
+		for _, env := range d.EnvValues {
+			cmd = append(cmd, "-e", env)
+		}
+		cmd = append(append(cmd, hook.Service), hook.Command...)
+		binds := []string{
+			getTrueDirectory(d.BuildDirectory) + ":/build",
+			"/var/run/docker.sock:/var/run/docker.sock",
+		}
+		if d.PersistDirectory != "" {
+			binds = append(binds, getTrueDirectory(d.PersistDirectory)+":/persist")
+		}
+		conf = &container.Config{
+			Image:      b.dockerComposeVersion,
+			WorkingDir: "/build",
+			Cmd:        cmd,
+			Env:        d.EnvValues,
+			Labels:     labels,
+		}
+		host = &container.HostConfig{AutoRemove: true, Binds: binds, Resources: d.Resources}
+
+	default:
+		return fmt.Errorf("hooks are not supported for build type '%s'", buildType)
+	}
+
+	// Run hook container
+	fmt.Fprintf(out, "Running hook '%s': %s\n", hook.Name, strings.Join(hook.Command, " "))
+	cli.ContainerRemove(ctx, name, types.ContainerRemoveOptions{Force: true})
+	resp, err := cli.ContainerCreate(ctx, conf, host, nil, name)
+	if err != nil {
+		return err
+	}
+	if len(resp.Warnings) > 0 {
+		cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		return errors.New(strings.Join(resp.Warnings, "\n"))
+	}
+	if err := containers.StartAndWait(ctx, cli, resp.ID, out); err != nil {
+		return fmt.Errorf("hook '%s' failed: %s", hook.Name, err.Error())
+	}
+	fmt.Fprintf(out, "Hook '%s' completed\n", hook.Name)
+	return nil
+}
@@ -0,0 +1,91 @@
+package build
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+)
+
+// EncodeRegistryAuth encodes the given registry credentials in the format
+// expected by the Docker API. Nothing is encoded if no username is given.
+func EncodeRegistryAuth(server, username, password string) (string, error) {
+	if username == "" {
+		return "", nil
+	}
+	bytes, err := json.Marshal(types.AuthConfig{
+		Username:      username,
+		Password:      password,
+		ServerAddress: server,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(bytes), nil
+}
+
+// imageBuild pulls a prebuilt project image from a registry instead of
+// building one, and returns a callback function to deploy it
+func (b *Builder) imageBuild(ctx context.Context, d Config, cli *docker.Client,
+	out io.Writer) (func() error, error) {
+	if d.Image == "" {
+		return nil, errors.New("no image configured for project")
+	}
+
+	// Pull image
+	reportProjectBuildBegin(d.Name, out)
+	fmt.Fprintf(out, "Pulling image %s...\n", d.Image)
+	pullResp, err := cli.ImagePull(ctx, d.Image, types.ImagePullOptions{
+		RegistryAuth: d.RegistryAuth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("image pull failed: %s", err.Error())
+	}
+	err = readPullOutput(pullResp, out)
+	pullResp.Close()
+	if err != nil {
+		return nil, fmt.Errorf("image pull failed: %s", err.Error())
+	}
+	reportProjectBuildComplete(d.Name, out)
+
+	return b.deployImage(ctx, cli, d, d.Image, out)
+}
+
+// readPullOutput writes the status messages from an image pull to out, and
+// returns the error reported by the pull, if there is one. Errors are reported
+// in the output rather than by the API call, so that an image that fails to
+// pull is not confused with one that is already present.
+func readPullOutput(r io.Reader, out io.Writer) error {
+	var dec = json.NewDecoder(r)
+	for {
+		var msg struct {
+			ID       string `json:"id"`
+			Status   string `json:"status"`
+			Progress string `json:"progress"`
+			Error    string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+		if msg.Progress != "" {
+			// skip download and extraction progress updates
+			continue
+		}
+		if msg.ID != "" {
+			fmt.Fprintf(out, "%s: %s\n", msg.ID, msg.Status)
+		} else {
+			fmt.Fprintln(out, msg.Status)
+		}
+	}
+}
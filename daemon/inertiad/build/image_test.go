@@ -0,0 +1,61 @@
+package build
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeRegistryAuth(t *testing.T) {
+	auth, err := EncodeRegistryAuth("", "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, auth)
+
+	auth, err = EncodeRegistryAuth("ghcr.io", "bob", "hunter2")
+	assert.NoError(t, err)
+	decoded, err := base64.URLEncoding.DecodeString(auth)
+	assert.NoError(t, err)
+	var conf types.AuthConfig
+	assert.NoError(t, json.Unmarshal(decoded, &conf))
+	assert.Equal(t, types.AuthConfig{
+		Username:      "bob",
+		Password:      "hunter2",
+		ServerAddress: "ghcr.io",
+	}, conf)
+}
+
+func Test_readPullOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"successful pull", `{"status":"Pulling from library/alpine","id":"latest"}
+{"status":"Downloading","progressDetail":{"current":1,"total":2},"progress":"[==>  ]","id":"abcde"}
+{"status":"Pull complete","progressDetail":{},"id":"abcde"}
+{"status":"Status: Downloaded newer image for alpine:latest"}
+`, "latest: Pulling from library/alpine\nabcde: Pull complete\nStatus: Downloaded newer image for alpine:latest\n", false},
+		{"failed pull", `{"status":"Pulling from library/alpine","id":"latest"}
+{"errorDetail":{"message":"unauthorized"},"error":"unauthorized"}
+`, "latest: Pulling from library/alpine\n", true},
+		{"invalid output", `{"status"`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := readPullOutput(strings.NewReader(tt.output), &out)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
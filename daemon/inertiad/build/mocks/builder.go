@@ -2,6 +2,7 @@
 package mocks
 
 import (
+	"context"
 	"io"
 	"sync"
 
@@ -10,13 +11,14 @@ import (
 )
 
 type FakeContainerBuilder struct {
-	BuildStub        func(string, build.Config, *client.Client, io.Writer) (func() error, error)
+	BuildStub        func(context.Context, string, build.Config, *client.Client, io.Writer) (func() error, error)
 	buildMutex       sync.RWMutex
 	buildArgsForCall []struct {
-		arg1 string
-		arg2 build.Config
-		arg3 *client.Client
-		arg4 io.Writer
+		arg1 context.Context
+		arg2 string
+		arg3 build.Config
+		arg4 *client.Client
+		arg5 io.Writer
 	}
 	buildReturns struct {
 		result1 func() error
@@ -60,6 +62,22 @@ type FakeContainerBuilder struct {
 	pruneAllReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RunHookStub        func(context.Context, string, build.Hook, build.Config, *client.Client, io.Writer) error
+	runHookMutex       sync.RWMutex
+	runHookArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 build.Hook
+		arg4 build.Config
+		arg5 *client.Client
+		arg6 io.Writer
+	}
+	runHookReturns struct {
+		result1 error
+	}
+	runHookReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StopContainersStub        func(*client.Client, io.Writer) error
 	stopContainersMutex       sync.RWMutex
 	stopContainersArgsForCall []struct {
@@ -76,24 +94,26 @@ type FakeContainerBuilder struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeContainerBuilder) Build(arg1 string, arg2 build.Config, arg3 *client.Client, arg4 io.Writer) (func() error, error) {
+func (fake *FakeContainerBuilder) Build(arg1 context.Context, arg2 string, arg3 build.Config, arg4 *client.Client, arg5 io.Writer) (func() error, error) {
 	fake.buildMutex.Lock()
 	ret, specificReturn := fake.buildReturnsOnCall[len(fake.buildArgsForCall)]
 	fake.buildArgsForCall = append(fake.buildArgsForCall, struct {
-		arg1 string
-		arg2 build.Config
-		arg3 *client.Client
-		arg4 io.Writer
-	}{arg1, arg2, arg3, arg4})
-	fake.recordInvocation("Build", []interface{}{arg1, arg2, arg3, arg4})
+		arg1 context.Context
+		arg2 string
+		arg3 build.Config
+		arg4 *client.Client
+		arg5 io.Writer
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.BuildStub
+	fakeReturns := fake.buildReturns
+	fake.recordInvocation("Build", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.buildMutex.Unlock()
-	if fake.BuildStub != nil {
-		return fake.BuildStub(arg1, arg2, arg3, arg4)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.buildReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -103,17 +123,17 @@ func (fake *FakeContainerBuilder) BuildCallCount() int {
 	return len(fake.buildArgsForCall)
 }
 
-func (fake *FakeContainerBuilder) BuildCalls(stub func(string, build.Config, *client.Client, io.Writer) (func() error, error)) {
+func (fake *FakeContainerBuilder) BuildCalls(stub func(context.Context, string, build.Config, *client.Client, io.Writer) (func() error, error)) {
 	fake.buildMutex.Lock()
 	defer fake.buildMutex.Unlock()
 	fake.BuildStub = stub
 }
 
-func (fake *FakeContainerBuilder) BuildArgsForCall(i int) (string, build.Config, *client.Client, io.Writer) {
+func (fake *FakeContainerBuilder) BuildArgsForCall(i int) (context.Context, string, build.Config, *client.Client, io.Writer) {
 	fake.buildMutex.RLock()
 	defer fake.buildMutex.RUnlock()
 	argsForCall := fake.buildArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeContainerBuilder) BuildReturns(result1 func() error, result2 error) {
@@ -147,15 +167,16 @@ func (fake *FakeContainerBuilder) GetBuildStageName() string {
 	ret, specificReturn := fake.getBuildStageNameReturnsOnCall[len(fake.getBuildStageNameArgsForCall)]
 	fake.getBuildStageNameArgsForCall = append(fake.getBuildStageNameArgsForCall, struct {
 	}{})
+	stub := fake.GetBuildStageNameStub
+	fakeReturns := fake.getBuildStageNameReturns
 	fake.recordInvocation("GetBuildStageName", []interface{}{})
 	fake.getBuildStageNameMutex.Unlock()
-	if fake.GetBuildStageNameStub != nil {
-		return fake.GetBuildStageNameStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.getBuildStageNameReturns
 	return fakeReturns.result1
 }
 
@@ -201,15 +222,16 @@ func (fake *FakeContainerBuilder) Prune(arg1 *client.Client, arg2 io.Writer) err
 		arg1 *client.Client
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.PruneStub
+	fakeReturns := fake.pruneReturns
 	fake.recordInvocation("Prune", []interface{}{arg1, arg2})
 	fake.pruneMutex.Unlock()
-	if fake.PruneStub != nil {
-		return fake.PruneStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.pruneReturns
 	return fakeReturns.result1
 }
 
@@ -262,15 +284,16 @@ func (fake *FakeContainerBuilder) PruneAll(arg1 *client.Client, arg2 io.Writer)
 		arg1 *client.Client
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.PruneAllStub
+	fakeReturns := fake.pruneAllReturns
 	fake.recordInvocation("PruneAll", []interface{}{arg1, arg2})
 	fake.pruneAllMutex.Unlock()
-	if fake.PruneAllStub != nil {
-		return fake.PruneAllStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.pruneAllReturns
 	return fakeReturns.result1
 }
 
@@ -316,6 +339,72 @@ func (fake *FakeContainerBuilder) PruneAllReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeContainerBuilder) RunHook(arg1 context.Context, arg2 string, arg3 build.Hook, arg4 build.Config, arg5 *client.Client, arg6 io.Writer) error {
+	fake.runHookMutex.Lock()
+	ret, specificReturn := fake.runHookReturnsOnCall[len(fake.runHookArgsForCall)]
+	fake.runHookArgsForCall = append(fake.runHookArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 build.Hook
+		arg4 build.Config
+		arg5 *client.Client
+		arg6 io.Writer
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
+	stub := fake.RunHookStub
+	fakeReturns := fake.runHookReturns
+	fake.recordInvocation("RunHook", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.runHookMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeContainerBuilder) RunHookCallCount() int {
+	fake.runHookMutex.RLock()
+	defer fake.runHookMutex.RUnlock()
+	return len(fake.runHookArgsForCall)
+}
+
+func (fake *FakeContainerBuilder) RunHookCalls(stub func(context.Context, string, build.Hook, build.Config, *client.Client, io.Writer) error) {
+	fake.runHookMutex.Lock()
+	defer fake.runHookMutex.Unlock()
+	fake.RunHookStub = stub
+}
+
+func (fake *FakeContainerBuilder) RunHookArgsForCall(i int) (context.Context, string, build.Hook, build.Config, *client.Client, io.Writer) {
+	fake.runHookMutex.RLock()
+	defer fake.runHookMutex.RUnlock()
+	argsForCall := fake.runHookArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+}
+
+func (fake *FakeContainerBuilder) RunHookReturns(result1 error) {
+	fake.runHookMutex.Lock()
+	defer fake.runHookMutex.Unlock()
+	fake.RunHookStub = nil
+	fake.runHookReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerBuilder) RunHookReturnsOnCall(i int, result1 error) {
+	fake.runHookMutex.Lock()
+	defer fake.runHookMutex.Unlock()
+	fake.RunHookStub = nil
+	if fake.runHookReturnsOnCall == nil {
+		fake.runHookReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.runHookReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerBuilder) StopContainers(arg1 *client.Client, arg2 io.Writer) error {
 	fake.stopContainersMutex.Lock()
 	ret, specificReturn := fake.stopContainersReturnsOnCall[len(fake.stopContainersArgsForCall)]
@@ -323,15 +412,16 @@ func (fake *FakeContainerBuilder) StopContainers(arg1 *client.Client, arg2 io.Wr
 		arg1 *client.Client
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.StopContainersStub
+	fakeReturns := fake.stopContainersReturns
 	fake.recordInvocation("StopContainers", []interface{}{arg1, arg2})
 	fake.stopContainersMutex.Unlock()
-	if fake.StopContainersStub != nil {
-		return fake.StopContainersStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.stopContainersReturns
 	return fakeReturns.result1
 }
 
@@ -388,6 +478,8 @@ func (fake *FakeContainerBuilder) Invocations() map[string][][]interface{} {
 	defer fake.pruneMutex.RUnlock()
 	fake.pruneAllMutex.RLock()
 	defer fake.pruneAllMutex.RUnlock()
+	fake.runHookMutex.RLock()
+	defer fake.runHookMutex.RUnlock()
 	fake.stopContainersMutex.RLock()
 	defer fake.stopContainersMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/fileutils"
 )
 
 // getTrueDirectory converts given filepath to host-based filepath if applicable
@@ -20,9 +23,11 @@ func getTrueDirectory(path string) string {
 
 // buildTar takes a source and variable writers and walks 'source' writing each file
 // found to the tar writer; the purpose for accepting multiple writers is to allow
-// for multiple outputs (for example a file, or md5 hash)
+// for multiple outputs (for example a file, or md5 hash). Git metadata and files
+// matched by the given .dockerignore patterns, if there are any, are left out,
+// and the given extra files are added to the root of the archive.
 // Sourced from https://gist.github.com/sdomino/e6bc0c98f87843bc26bb#file-targz-go
-func buildTar(dir string, outputs ...io.Writer) error {
+func buildTar(dir string, ignore *fileutils.PatternMatcher, extra map[string][]byte, outputs ...io.Writer) error {
 
 	// ensure the src actually exists before trying to tar it
 	if _, err := os.Stat(dir); err != nil {
@@ -37,12 +42,32 @@ func buildTar(dir string, outputs ...io.Writer) error {
 	tw := tar.NewWriter(gzw)
 	defer tw.Close()
 
-	return filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+	if err := filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
 		// return on any error
 		if err != nil {
 			return err
 		}
 
+		// leave out git metadata, which includes the repository's entire
+		// history, and ignored files
+		if fi.Name() == ".git" && file != dir {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignore != nil {
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+			if rel != "." {
+				if excluded, err := contextExcluded(ignore, rel, fi.IsDir()); excluded || err != nil {
+					return err
+				}
+			}
+		}
+
 		// create a new dir/file header
 		header, err := tar.FileInfoHeader(fi, fi.Name())
 		if err != nil {
@@ -72,5 +97,23 @@ func buildTar(dir string, outputs ...io.Writer) error {
 		// copy file data into tar writer
 		_, err = io.Copy(tw, f)
 		return err
-	})
+	}); err != nil {
+		return err
+	}
+
+	// write extra files
+	for name, contents := range extra {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(contents)),
+			ModTime: time.Now(),
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(contents); err != nil {
+			return err
+		}
+	}
+	return nil
 }
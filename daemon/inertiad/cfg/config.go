@@ -11,15 +11,24 @@ import (
 // Config provides basic daemon configuration
 type Config struct {
 	// Directories
-	ProjectDirectory string // "/app/host/inertia/project/"
-	PersistDirectory string // "/app/host/inertia/persist"
+	ProjectDirectory string // "/app/host/inertia/project/", one directory per project
+	PersistDirectory string // "/app/host/inertia/persist", one directory per project
 	DataDirectory    string // "/app/host/inertia/data/"
 	SecretsDirectory string // "/app/host/.inertia/"
+	ConfigDirectory  string // "/app/host/inertia/config/"
 
 	// Build tools
 	DockerComposeVersion string // "docker/compose:${version}"
 
 	WebhookSecret string
+
+	// Retention is the policy for cleaning up Docker assets, read from the
+	// configuration file by Load
+	Retention Retention
+
+	// Logging configures the daemon's logs, read from the configuration file
+	// by Load
+	Logging Logging
 }
 
 // New creates a new daemon configuration from environment values
@@ -38,5 +47,6 @@ func New() *Config {
 		DockerComposeVersion: fmt.Sprintf("docker/compose:%s", dcVersionString),
 		ProjectDirectory:     os.Getenv("INERTIA_PROJECT_DIR"),
 		PersistDirectory:     os.Getenv("INERTIA_PERSIST_DIR"),
+		ConfigDirectory:      os.Getenv("INERTIA_CONFIG_DIR"),
 	}
 }
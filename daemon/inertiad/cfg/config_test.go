@@ -1,10 +1,15 @@
 package cfg
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 )
 
 func TestNew(t *testing.T) {
@@ -13,3 +18,77 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, "/user/project", cfg.ProjectDirectory)
 	t.Log(cfg.DockerComposeVersion)
 }
+
+func TestConfig_Load(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// No configuration file
+	var conf = &Config{ConfigDirectory: dir}
+	assert.NoError(t, conf.Load())
+	assert.False(t, conf.Retention.Enabled())
+
+	tests := []struct {
+		name    string
+		file    string
+		want    Retention
+		wantErr bool
+	}{
+		{"defaults", "[retention]\nkeep = 3\n",
+			Retention{Keep: 3, Interval: DefaultRetentionInterval}, false},
+		{"all rules", "[retention]\nkeep = 3\nmax_age = \"168h\"\nmin_free_percent = 10.0\ninterval = \"30m\"\n",
+			Retention{Keep: 3, MaxAge: 168 * time.Hour, MinFreePercent: 10, Interval: 30 * time.Minute}, false},
+		{"negative keep", "[retention]\nkeep = -1\n", Retention{}, true},
+		{"invalid max age", "[retention]\nmax_age = \"one week\"\n", Retention{}, true},
+		{"invalid min free", "[retention]\nmin_free_percent = 100.0\n", Retention{}, true},
+		{"interval too short", "[retention]\ninterval = \"1s\"\n", Retention{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte(tt.file), 0600))
+			var conf = &Config{ConfigDirectory: dir}
+			err := conf.Load()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, conf.Retention)
+			assert.True(t, conf.Retention.Enabled())
+		})
+	}
+}
+
+func TestConfig_Load_logging(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		file    string
+		want    Logging
+		wantErr bool
+	}{
+		{"no logging section", "[retention]\nkeep = 3\n", Logging{}, false},
+		{"defaults", "[log]\n", Logging{Level: log.LevelInfo, Format: log.FormatText}, false},
+		{"level and format", "[log]\nlevel = \"debug\"\nformat = \"json\"\n",
+			Logging{Level: log.LevelDebug, Format: log.FormatJSON}, false},
+		{"invalid level", "[log]\nlevel = \"loud\"\n", Logging{}, true},
+		{"invalid format", "[log]\nformat = \"xml\"\n", Logging{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte(tt.file), 0600))
+			var conf = &Config{ConfigDirectory: dir}
+			err := conf.Load()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, conf.Logging)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
+)
+
+// ConfigFileName is the name of the daemon configuration file in the
+// configuration directory
+const ConfigFileName = "inertiad.toml"
+
+// Logging configures the level and format of the daemon's logs. Entries below
+// Level are discarded, and Format is one of "text" or "json".
+type Logging struct {
+	Level  log.Level
+	Format string
+}
+
+// configFile is the format of the daemon configuration file
+type configFile struct {
+	Retention *struct {
+		Keep           int     `toml:"keep"`
+		MaxAge         string  `toml:"max_age"`
+		MinFreePercent float64 `toml:"min_free_percent"`
+		Interval       string  `toml:"interval"`
+	} `toml:"retention"`
+	Log *struct {
+		Level  string `toml:"level"`
+		Format string `toml:"format"`
+	} `toml:"log"`
+}
+
+// Load reads the daemon configuration file in the configuration directory,
+// if there is one
+func (c *Config) Load() error {
+	if c.ConfigDirectory == "" {
+		return nil
+	}
+	var path = filepath.Join(c.ConfigDirectory, ConfigFileName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	var file configFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return fmt.Errorf("failed to read %s: %s", path, err.Error())
+	}
+
+	if r := file.Retention; r != nil {
+		var retention = Retention{
+			Keep:           r.Keep,
+			MinFreePercent: r.MinFreePercent,
+			Interval:       DefaultRetentionInterval,
+		}
+		if r.Keep < 0 {
+			return fmt.Errorf("invalid retention keep %d", r.Keep)
+		}
+		if r.MinFreePercent < 0 || r.MinFreePercent >= 100 {
+			return fmt.Errorf("invalid retention min_free_percent %g", r.MinFreePercent)
+		}
+		if r.MaxAge != "" {
+			maxAge, err := time.ParseDuration(r.MaxAge)
+			if err != nil || maxAge <= 0 {
+				return fmt.Errorf("invalid retention max_age '%s'", r.MaxAge)
+			}
+			retention.MaxAge = maxAge
+		}
+		if r.Interval != "" {
+			interval, err := time.ParseDuration(r.Interval)
+			if err != nil || interval < time.Minute {
+				return fmt.Errorf("invalid retention interval '%s' - must be at least 1m", r.Interval)
+			}
+			retention.Interval = interval
+		}
+		c.Retention = retention
+	}
+
+	if l := file.Log; l != nil {
+		var logging = Logging{Format: log.FormatText}
+		if l.Level != "" {
+			level, err := log.ParseLevel(l.Level)
+			if err != nil {
+				return err
+			}
+			logging.Level = level
+		}
+		if l.Format != "" {
+			if err := log.ValidateFormat(l.Format); err != nil {
+				return err
+			}
+			logging.Format = l.Format
+		}
+		c.Logging = logging
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cfg
+
+import "time"
+
+// DefaultRetentionInterval is how often the retention policy is enforced if
+// no interval is configured
+const DefaultRetentionInterval = time.Hour
+
+// Retention is a policy for cleaning up Docker assets left behind by past
+// deployments. Archived containers and unused images beyond the Keep most
+// recent of each project, or older than MaxAge, are removed. If less than
+// MinFreePercent of the disk is free, all archived containers, unused images,
+// and the build cache are removed. Each rule is disabled if left unset.
+type Retention struct {
+	Keep           int
+	MaxAge         time.Duration
+	MinFreePercent float64
+	Interval       time.Duration
+}
+
+// Enabled returns true if any retention rules are set
+func (r Retention) Enabled() bool {
+	return r.Keep > 0 || r.MaxAge > 0 || r.MinFreePercent > 0
+}
@@ -21,6 +21,22 @@ var (
 	ErrNoContainers = errors.New("There are currently no active containers")
 )
 
+const (
+	// LabelProject is applied to containers created by Inertia, and denotes
+	// the name of the project the container belongs to
+	LabelProject = "inertia.project"
+	// LabelStage is applied to intermediary containers created by Inertia,
+	// and denotes the stage the container is used for
+	LabelStage = "inertia.stage"
+	// LabelPreviewHost is applied to containers of preview deployments that
+	// are served on a hostname, and denotes the hostname to route to them
+	LabelPreviewHost = "inertia.preview.host"
+
+	// labelComposeProject is applied by docker-compose to all containers it
+	// creates, and denotes the (normalized) compose project name
+	labelComposeProject = "com.docker.compose.project"
+)
+
 // LogOptions is used to configure retrieved container logs
 type LogOptions struct {
 	Container    string
@@ -80,9 +96,108 @@ func GetActiveContainers(docker *docker.Client) ([]types.Container, error) {
 	return containers, nil
 }
 
+// ComposeProjectName normalizes the given project name the same way
+// docker-compose does when it is used as a compose project name
+func ComposeProjectName(project string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(project))
+}
+
+// BelongsToProject checks if a container with the given labels is part of
+// the given project. composeProject is the compose project name used by the
+// project, and defaults to the project name if empty.
+func BelongsToProject(labels map[string]string, project, composeProject string) bool {
+	if project == "" {
+		return false
+	}
+	if labels[LabelProject] == project {
+		return true
+	}
+	if composeProject == "" {
+		composeProject = project
+	}
+	return labels[labelComposeProject] != "" &&
+		labels[labelComposeProject] == ComposeProjectName(composeProject)
+}
+
+// GetProjectContainers returns all active containers that belong to the given
+// project, and returns ErrNoContainers if there are none. composeProject is
+// the compose project name used by the project, and defaults to the project
+// name if empty.
+func GetProjectContainers(docker *docker.Client, project, composeProject string) ([]types.Container, error) {
+	list, err := docker.ContainerList(
+		context.Background(),
+		types.ContainerListOptions{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers = make([]types.Container, 0)
+	for _, c := range list {
+		if BelongsToProject(c.Labels, project, composeProject) {
+			containers = append(containers, c)
+		}
+	}
+	if len(containers) == 0 {
+		return nil, ErrNoContainers
+	}
+
+	return containers, nil
+}
+
+// GetHostAddress returns the address of the Docker host on the default bridge
+// network, which allows containers such as the daemon to reach ports published
+// on the host
+func GetHostAddress(docker *docker.Client) (string, error) {
+	bridge, err := docker.NetworkInspect(context.Background(), "bridge",
+		types.NetworkInspectOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, conf := range bridge.IPAM.Config {
+		if conf.Gateway != "" {
+			return conf.Gateway, nil
+		}
+	}
+	return "", errors.New("unable to determine host address from bridge network")
+}
+
 // ContainerStopper is a function interface
 type ContainerStopper func(*docker.Client, io.Writer) error
 
+// ProjectContainerStopper returns a ContainerStopper that only stops
+// containers belonging to the given project
+func ProjectContainerStopper(project string) ContainerStopper {
+	return func(docker *docker.Client, out io.Writer) error {
+		return StopProjectContainers(docker, out, project, "")
+	}
+}
+
+// StopProjectContainers kills all active containers belonging to the given
+// project. composeProject is the compose project name used by the project, and
+// defaults to the project name if empty.
+func StopProjectContainers(docker *docker.Client, out io.Writer, project, composeProject string) error {
+	fmt.Fprintf(out, "Shutting down active containers for project %s...\n", project)
+	containers, err := GetProjectContainers(docker, project, composeProject)
+	if err == ErrNoContainers {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		if err := StopAndArchive(docker, container.ID, container.Names[0], out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // StopActiveContainers kills all active project containers (ie not including daemon)
 func StopActiveContainers(docker *docker.Client, out io.Writer) error {
 	fmt.Fprintln(out, "Shutting down active containers...")
@@ -95,20 +210,30 @@ func StopActiveContainers(docker *docker.Client, out io.Writer) error {
 	// Gracefully take down all containers except the daemon
 	for _, container := range containers {
 		if container.Names[0] != "/inertia-daemon" {
-			fmt.Fprintln(out, "Stopping "+container.Names[0]+"...")
-			timeout := 10 * time.Second
-			if err := docker.ContainerStop(ctx, container.ID, &timeout); err != nil {
+			if err := StopAndArchive(docker, container.ID, container.Names[0], out); err != nil {
 				return err
 			}
-
-			// Archive container
-			docker.ContainerRename(
-				ctx, container.ID, fmt.Sprintf("%s-%d", container.Names[0], time.Now().Unix()))
 		}
 	}
 	return nil
 }
 
+// StopAndArchive gracefully stops the given container and archives it by
+// renaming it, which frees up its name for use by a new container
+func StopAndArchive(docker *docker.Client, id, name string, out io.Writer) error {
+	fmt.Fprintln(out, "Stopping "+name+"...")
+	ctx := context.Background()
+	timeout := 10 * time.Second
+	if err := docker.ContainerStop(ctx, id, &timeout); err != nil {
+		return err
+	}
+
+	// Archive container
+	docker.ContainerRename(
+		ctx, id, fmt.Sprintf("%s-%d", name, time.Now().Unix()))
+	return nil
+}
+
 // Prune clears up unused Docker assets.
 func Prune(docker *docker.Client) error {
 	ctx := context.Background()
@@ -159,10 +284,59 @@ func PruneAll(docker *docker.Client, exceptions ...string) error {
 	return nil
 }
 
-// Wait blocks until given container ID stops
-func Wait(cli *docker.Client, id string, stop chan struct{}) (int64, error) {
+// PruneProject removes stopped containers belonging to the given project,
+// including containers archived by past deployments, and the project's images
+// that are no longer used by any container. composeProject is the compose
+// project name used by the project, and defaults to the project name if empty.
+func PruneProject(docker *docker.Client, project, composeProject string) error {
+	ctx := context.Background()
+
+	// Delete stopped containers, keeping track of the images they used
+	list, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return err
+	}
+	var images = make(map[string]bool)
+	for _, c := range list {
+		if !BelongsToProject(c.Labels, project, composeProject) {
+			continue
+		}
+		images[c.ImageID] = true
+		if c.State == "running" || c.State == "restarting" || c.State == "paused" {
+			continue
+		}
+		if err := docker.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Delete images built for the project as well, even if no containers were
+	// created from them
+	built, err := docker.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", LabelProject+"="+project)),
+	})
+	if err != nil {
+		return err
+	}
+	for _, i := range built {
+		images[i.ID] = true
+	}
+
+	// Images still in use by containers, including those of other projects,
+	// cannot be removed without force, so failures are ignored
+	for id := range images {
+		docker.ImageRemove(ctx, id, types.ImageRemoveOptions{PruneChildren: true})
+	}
+	return nil
+}
+
+// Wait blocks until given container ID stops, or until the given context is
+// cancelled
+func Wait(ctx context.Context, cli *docker.Client, id string, stop chan struct{}) (int64, error) {
 	var status container.ContainerWaitOKBody
-	statusCh, errCh := cli.ContainerWait(context.Background(), id, "")
+	statusCh, errCh := cli.ContainerWait(ctx, id, "")
 	select {
 	case err := <-errCh:
 		if err != nil {
@@ -175,17 +349,24 @@ func Wait(cli *docker.Client, id string, stop chan struct{}) (int64, error) {
 	return status.StatusCode, nil
 }
 
-// StartAndWait starts and waits for container to exit
-func StartAndWait(cli *docker.Client, containerID string, out io.Writer) error {
-	ctx := context.Background()
+// StartAndWait starts and waits for container to exit. If the given context is
+// cancelled, the container is stopped.
+func StartAndWait(ctx context.Context, cli *docker.Client, containerID string, out io.Writer) error {
 	if err := cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
 		return err
 	}
 
 	stop := make(chan struct{})
 	go StreamContainerLogs(cli, containerID, out, stop)
-	exitCode, err := Wait(cli, containerID, stop)
+	exitCode, err := Wait(ctx, cli, containerID, stop)
 	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintln(out, "Stopping cancelled container...")
+			timeout := 10 * time.Second
+			cli.ContainerStop(context.Background(), containerID, &timeout)
+			close(stop)
+			return ctx.Err()
+		}
 		return err
 	}
 	if exitCode != 0 {
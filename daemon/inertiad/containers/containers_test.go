@@ -107,3 +107,34 @@ func TestPruneAll(t *testing.T) {
 	}
 	assert.True(t, found)
 }
+
+func TestComposeProjectName(t *testing.T) {
+	assert.Equal(t, "myproject", ComposeProjectName("MyProject"))
+	assert.Equal(t, "my-project_2", ComposeProjectName("my-project_2"))
+	assert.Equal(t, "myproject", ComposeProjectName("my.project"))
+}
+
+func TestBelongsToProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		labels         map[string]string
+		project        string
+		composeProject string
+		want           bool
+	}{
+		{"no labels", nil, "wow", "", false},
+		{"inertia label", map[string]string{LabelProject: "wow"}, "wow", "", true},
+		{"other inertia project", map[string]string{LabelProject: "wow"}, "amazing", "", false},
+		{"compose label", map[string]string{labelComposeProject: "myproject"}, "MyProject", "", true},
+		{"other compose project", map[string]string{labelComposeProject: "wow"}, "amazing", "", false},
+		{"compose project override", map[string]string{labelComposeProject: "staging"}, "wow", "staging", true},
+		{"overridden compose project", map[string]string{labelComposeProject: "wow"}, "wow", "staging", false},
+		{"inertia label with override", map[string]string{LabelProject: "wow"}, "wow", "staging", true},
+		{"empty project", map[string]string{LabelProject: ""}, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, BelongsToProject(tt.labels, tt.project, tt.composeProject))
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package containers
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+// archivedName matches the names of containers archived by StopAndArchive,
+// which are suffixed with the unix time they were archived at
+var archivedName = regexp.MustCompile(`^/?(.+)-(\d{10})$`)
+
+// Asset is a Docker asset that can be removed to free up disk space
+type Asset struct {
+	ID   string
+	Name string
+	// Project is the name of the project the asset belongs to, if it is known
+	Project string
+	Created time.Time
+	Size    int64
+}
+
+// ArchivedAt returns the time the container with the given name was archived
+// at by StopAndArchive, if it has been archived
+func ArchivedAt(name string) (time.Time, bool) {
+	var match = archivedName.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), true
+}
+
+// getArchivedProject returns the project of the given container if it is a
+// stopped container created by Inertia that has been archived
+func getArchivedProject(c *types.Container) (string, time.Time, bool) {
+	if c.State == "running" || len(c.Names) == 0 {
+		return "", time.Time{}, false
+	}
+	var project = c.Labels[LabelProject]
+	if project == "" {
+		project = c.Labels[labelComposeProject]
+	}
+	if project == "" {
+		return "", time.Time{}, false
+	}
+	archived, ok := ArchivedAt(c.Names[0])
+	return project, archived, ok
+}
+
+// GetArchivedContainers returns the containers created by Inertia that have
+// been stopped and archived
+func GetArchivedContainers(cli *docker.Client) ([]Asset, error) {
+	list, err := cli.ContainerList(context.Background(), types.ContainerListOptions{
+		All:  true,
+		Size: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var assets = make([]Asset, 0)
+	for i := range list {
+		project, archived, ok := getArchivedProject(&list[i])
+		if !ok {
+			continue
+		}
+		assets = append(assets, Asset{
+			ID:      list[i].ID,
+			Name:    strings.TrimPrefix(list[i].Names[0], "/"),
+			Project: project,
+			Created: archived,
+			Size:    list[i].SizeRw,
+		})
+	}
+	return assets, nil
+}
+
+// GetUnusedImages returns the images built by Inertia, and dangling images,
+// that are not used by any container
+func GetUnusedImages(cli *docker.Client) ([]Asset, error) {
+	var ctx = context.Background()
+	list, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+	var used = make(map[string]bool, len(list))
+	for _, c := range list {
+		used[c.ImageID] = true
+	}
+
+	images, err := cli.ImageList(ctx, types.ImageListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var assets = make([]Asset, 0)
+	for _, i := range images {
+		if used[i.ID] {
+			continue
+		}
+		var (
+			name    string
+			project = i.Labels[LabelProject]
+		)
+		for _, tag := range i.RepoTags {
+			if tag == "<none>:<none>" {
+				continue
+			}
+			if name == "" {
+				name = tag
+			}
+			if strings.HasPrefix(tag, "inertia-build/") && project == "" {
+				project = strings.SplitN(strings.TrimPrefix(tag, "inertia-build/"), ":", 2)[0]
+			}
+		}
+
+		// Leave tagged images alone unless Inertia built them
+		if name != "" && project == "" {
+			continue
+		}
+		if name == "" {
+			name = strings.TrimPrefix(i.ID, "sha256:")
+			if len(name) > 12 {
+				name = name[:12]
+			}
+		}
+		assets = append(assets, Asset{
+			ID:      i.ID,
+			Name:    name,
+			Project: project,
+			Created: time.Unix(i.Created, 0),
+			Size:    i.Size,
+		})
+	}
+	return assets, nil
+}
+
+// SelectExpired returns the assets that should be removed to only keep the
+// given number of most recent assets of each project, and assets no older
+// than maxAge. Either rule is disabled if set to zero. Expired assets are
+// returned oldest first.
+func SelectExpired(assets []Asset, keep int, maxAge time.Duration, now time.Time) []Asset {
+	var projects = make(map[string][]Asset)
+	for _, a := range assets {
+		projects[a.Project] = append(projects[a.Project], a)
+	}
+
+	var expired = make([]Asset, 0)
+	for _, group := range projects {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Created.After(group[j].Created)
+		})
+		for i, a := range group {
+			if (keep > 0 && i >= keep) || (maxAge > 0 && now.Sub(a.Created) > maxAge) {
+				expired = append(expired, a)
+			}
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		if expired[i].Created.Equal(expired[j].Created) {
+			return expired[i].ID < expired[j].ID
+		}
+		return expired[i].Created.Before(expired[j].Created)
+	})
+	return expired
+}
+
+// RemoveContainer removes the given stopped container
+func RemoveContainer(cli *docker.Client, id string) error {
+	return cli.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{})
+}
+
+// RemoveImage removes the given image, along with its untagged parents
+func RemoveImage(cli *docker.Client, id string) error {
+	_, err := cli.ImageRemove(context.Background(), id, types.ImageRemoveOptions{
+		PruneChildren: true,
+	})
+	return err
+}
+
+// PruneBuildCache removes the build cache, and returns the space reclaimed
+func PruneBuildCache(cli *docker.Client) (uint64, error) {
+	report, err := cli.BuildCachePrune(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return report.SpaceReclaimed, nil
+}
+
+// GetDiskUsage reports the disk space used by Docker images, archived
+// containers, volumes, and the build cache
+func GetDiskUsage(cli *docker.Client) (api.DiskUsage, error) {
+	du, err := cli.DiskUsage(context.Background())
+	if err != nil {
+		return api.DiskUsage{}, err
+	}
+
+	var usage api.DiskUsage
+	usage.Images.Size = du.LayersSize
+	for _, i := range du.Images {
+		usage.Images.Count++
+		if i.Containers == 0 {
+			// Layers shared with other images are not freed by removing this one
+			var size = i.Size
+			if i.SharedSize > 0 {
+				size -= i.SharedSize
+			}
+			usage.Images.Reclaimable += size
+		}
+	}
+	for _, c := range du.Containers {
+		if _, _, ok := getArchivedProject(c); ok {
+			usage.ArchivedContainers.Count++
+			usage.ArchivedContainers.Size += c.SizeRw
+		}
+	}
+	usage.ArchivedContainers.Reclaimable = usage.ArchivedContainers.Size
+	for _, v := range du.Volumes {
+		usage.Volumes.Count++
+		if v.UsageData == nil || v.UsageData.Size < 0 {
+			continue
+		}
+		usage.Volumes.Size += v.UsageData.Size
+		if v.UsageData.RefCount == 0 {
+			usage.Volumes.Reclaimable += v.UsageData.Size
+		}
+	}
+	usage.BuildCache.Size = du.BuilderSize
+	usage.BuildCache.Reclaimable = du.BuilderSize
+	return usage, nil
+}
@@ -0,0 +1,86 @@
+package containers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchivedAt(t *testing.T) {
+	archived, ok := ArchivedAt("/inertia-project-1600000000")
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(1600000000, 0), archived)
+
+	_, ok = ArchivedAt("/inertia-project")
+	assert.False(t, ok)
+	_, ok = ArchivedAt("/myproject-web-1")
+	assert.False(t, ok)
+}
+
+func Test_getArchivedProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		container   types.Container
+		wantProject string
+		wantOK      bool
+	}{
+		{"archived", types.Container{Names: []string{"/wow-1600000000"}, State: "exited",
+			Labels: map[string]string{LabelProject: "wow"}}, "wow", true},
+		{"archived compose container", types.Container{Names: []string{"/wow_web_1-1600000000"}, State: "exited",
+			Labels: map[string]string{labelComposeProject: "wow"}}, "wow", true},
+		{"running", types.Container{Names: []string{"/wow-1600000000"}, State: "running",
+			Labels: map[string]string{LabelProject: "wow"}}, "", false},
+		{"not archived", types.Container{Names: []string{"/wow"}, State: "exited",
+			Labels: map[string]string{LabelProject: "wow"}}, "wow", false},
+		{"not created by inertia", types.Container{Names: []string{"/wow-1600000000"}, State: "exited"},
+			"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, _, ok := getArchivedProject(&tt.container)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.wantProject, project)
+			}
+		})
+	}
+}
+
+func TestSelectExpired(t *testing.T) {
+	var now = time.Unix(1600000000, 0)
+	var day = 24 * time.Hour
+	var assets = []Asset{
+		{ID: "a1", Project: "a", Created: now.Add(-1 * day)},
+		{ID: "a2", Project: "a", Created: now.Add(-2 * day)},
+		{ID: "a3", Project: "a", Created: now.Add(-10 * day)},
+		{ID: "b1", Project: "b", Created: now.Add(-3 * day)},
+		{ID: "n1", Created: now.Add(-5 * day)},
+	}
+	var ids = func(assets []Asset) []string {
+		var ids = make([]string, 0, len(assets))
+		for _, a := range assets {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		keep   int
+		maxAge time.Duration
+		want   []string
+	}{
+		{"no rules", 0, 0, []string{}},
+		{"keep most recent", 1, 0, []string{"a3", "a2"}},
+		{"keep all", 5, 0, []string{}},
+		{"max age", 0, 4 * day, []string{"a3", "n1"}},
+		{"keep and max age", 2, 4 * day, []string{"a3", "n1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(SelectExpired(assets, tt.keep, tt.maxAge, now)))
+		})
+	}
+}
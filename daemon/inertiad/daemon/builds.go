@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// recordBuild runs the given build of the named project, recording its output
+// in the project's build logs as well as writing it to the given output
+func (s *Server) recordBuild(
+	name, trigger string,
+	deployment project.Deployer,
+	out io.Writer,
+	build func(out io.Writer, buildID string) error,
+) error {
+	if s.builds == nil {
+		return build(out, "")
+	}
+	l, err := s.builds.Start(name, trigger)
+	if err != nil {
+		fmt.Fprintln(out, "warning: failed to record build: "+err.Error())
+		s.logger.Warn("failed to record build", "project", name, "error", err)
+		return build(out, "")
+	}
+	fmt.Fprintf(out, "Recording output of build %s\n", l.ID())
+
+	err = build(io.MultiWriter(out, l), l.ID())
+
+	var commit string
+	if status, statusErr := deployment.GetStatus(s.docker); statusErr == nil {
+		commit = status.CommitHash
+	}
+	if finishErr := l.Finish(commit, err); finishErr != nil {
+		s.logger.Warn("failed to save build log", "project", name, "build_id", l.ID(), "error", finishErr)
+	}
+	return err
+}
+
+// buildsHandler lists recorded builds of the project
+func (s *Server) buildsHandler(w http.ResponseWriter, r *http.Request) {
+	name, _, ok := s.getProject(w, r)
+	if !ok {
+		return
+	}
+	builds, err := s.builds.List(name)
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve builds", err))
+		return
+	}
+
+	render.Render(w, r, res.MsgOK("builds retrieved",
+		"builds", builds))
+}
+
+// buildLogsHandler writes the output of a build of the project as plain text,
+// following the output of running builds if requested
+func (s *Server) buildLogsHandler(w http.ResponseWriter, r *http.Request) {
+	var follow bool
+	if streamParam := r.URL.Query().Get(api.Stream); streamParam != "" {
+		var err error
+		if follow, err = strconv.ParseBool(streamParam); err != nil {
+			render.Render(w, r, res.ErrBadRequest(err.Error()))
+			return
+		}
+	}
+	name, _, ok := s.getProject(w, r)
+	if !ok {
+		return
+	}
+
+	var id = chi.URLParam(r, "id")
+	logs, err := s.builds.Open(r.Context(), name, id, follow)
+	switch err {
+	case nil:
+	case project.ErrBuildNotFound:
+		render.Render(w, r, res.ErrNotFound(fmt.Sprintf("build '%s' of project '%s' not found", id, name)))
+		return
+	default:
+		render.Render(w, r, res.ErrInternalServer("failed to read build logs", err))
+		return
+	}
+	defer logs.Close()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	var buf = make([]byte, 32*1024)
+	for {
+		n, err := logs.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestBuildHandlers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-builds")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var fake = &mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{CommitHash: "abcde"}, nil
+		},
+	}
+	var s = newTestServer(fake)
+	s.builds = project.NewBuildLogs(dir, project.DefaultMaxBuildLogSize, project.DefaultMaxBuilds)
+
+	// output of builds is recorded as well as written to the given output
+	var out bytes.Buffer
+	var buildID string
+	err = s.recordBuild("test", project.BuildTriggerUp, fake, &out, func(w io.Writer, id string) error {
+		buildID = id
+		fmt.Fprintln(w, "building project")
+		return errors.New("build failed")
+	})
+	assert.Error(t, err)
+	assert.NotEmpty(t, buildID)
+	assert.Contains(t, out.String(), "building project")
+
+	var router = chi.NewRouter()
+	router.Get("/builds", s.buildsHandler)
+	router.Get("/builds/{id}/logs", s.buildLogsHandler)
+
+	t.Run("list builds", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/builds", nil)
+		assert.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), buildID)
+		assert.Contains(t, recorder.Body.String(), project.BuildFailed)
+		assert.Contains(t, recorder.Body.String(), "abcde")
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"build logs", "/builds/" + buildID + "/logs", http.StatusOK, "building project\n"},
+		{"follow finished build", "/builds/" + buildID + "/logs?stream=true", http.StatusOK, "building project\n"},
+		{"unknown build", "/builds/20200101-000000-abcd/logs", http.StatusNotFound, ""},
+		{"invalid stream", "/builds/" + buildID + "/logs?stream=wow", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.path, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
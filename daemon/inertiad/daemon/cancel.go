@@ -0,0 +1,23 @@
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// cancelHandler aborts the in-progress deploy of the project, as well as any
+// deploy waiting to start
+func (s *Server) cancelHandler(w http.ResponseWriter, r *http.Request) {
+	name, _, ok := s.getProject(w, r)
+	if !ok {
+		return
+	}
+	if !s.deployments.Queue(name).Cancel() {
+		render.Render(w, r, res.Err("no deploy in progress", http.StatusPreconditionFailed))
+		return
+	}
+	render.Render(w, r, res.MsgOK("deploy cancelled"))
+}
@@ -0,0 +1,40 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestCancelHandler(t *testing.T) {
+	var s = newTestServer(&mocks.FakeDeployer{})
+
+	// nothing to cancel
+	req, err := http.NewRequest("POST", "/deploy/cancel", nil)
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(s.cancelHandler).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	// cancel a running deploy
+	var (
+		started = make(chan struct{})
+		result  = make(chan error)
+	)
+	go func() {
+		result <- s.deployments.Queue("test").Submit(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(s.cancelHandler).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Error(t, <-result)
+}
@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// containerActionParam is the URL parameter denoting the container action
+const containerActionParam = "action"
+
+// containerHandler restarts, stops, or starts an individual project container
+func (s *Server) containerHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		container = chi.URLParam(r, api.Container)
+		action    = project.ContainerAction(chi.URLParam(r, containerActionParam))
+	)
+	switch action {
+	case project.ContainerRestart, project.ContainerStop, project.ContainerStart:
+	default:
+		render.Render(w, r, res.ErrBadRequest("unknown container action",
+			"action", action))
+		return
+	}
+
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	if err := deployment.ControlContainer(s.docker, container, action); err == project.ErrContainerNotFound {
+		render.Render(w, r, res.ErrNotFound(err.Error(), "container", container))
+		return
+	} else if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to "+string(action)+" container", err))
+		return
+	}
+
+	render.Render(w, r, res.MsgOK("container "+string(action)+" succeeded",
+		"container", container))
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestContainerHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		err      error
+		wantCode int
+	}{
+		{"restart", "/containers/web/restart", nil, http.StatusOK},
+		{"stop", "/containers/web/stop", nil, http.StatusOK},
+		{"start", "/containers/web/start", nil, http.StatusOK},
+		{"unknown action", "/containers/web/destroy", nil, http.StatusBadRequest},
+		{"container not in project", "/containers/web/restart", project.ErrContainerNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{}
+			fake.ControlContainerReturns(tt.err)
+			var s = newTestServer(fake)
+			var router = chi.NewRouter()
+			router.Post("/containers/{container}/{action}", s.containerHandler)
+
+			req, err := http.NewRequest("POST", tt.path, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+
+			if tt.wantCode != http.StatusBadRequest {
+				_, name, action := fake.ControlContainerArgsForCall(0)
+				assert.Equal(t, "web", name)
+				assert.Contains(t, tt.path, string(action))
+			}
+		})
+	}
+}
@@ -1,34 +1,60 @@
 package daemon
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	docker "github.com/docker/docker/client"
+	"github.com/go-chi/render"
 	"github.com/gorilla/websocket"
+
+	"github.com/ubclaunchpad/inertia/api"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/auth"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/cfg"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/crypto"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
 )
 
 // Server is the core component of Inertiad, and hosts its API and deployment manager
 type Server struct {
 	version string
 
-	deployment project.Deployer
-	state      cfg.Config
+	// host is the address the daemon is served on, used to derive the URLs of
+	// previews
+	host string
+
+	deployments *project.Registry
+	builds      *project.BuildLogs
+	state       cfg.Config
+
+	// watchers stops the container event watchers of deployments, by name
+	watchers    map[string]context.CancelFunc
+	watchersMux sync.Mutex
 
 	docker    *docker.Client
 	websocket *websocket.Upgrader
+
+	logger *log.Logger
 }
 
 // New instantiates a new Inertiad server
-func New(version string, state cfg.Config, deployment project.Deployer) (*Server, error) {
+func New(
+	version string,
+	state cfg.Config,
+	deployments *project.Registry,
+	logger *log.Logger,
+) (*Server, error) {
 	// Establish connection with dockerd
 	cli, err := containers.NewDockerClient()
 	if err != nil {
@@ -36,18 +62,22 @@ func New(version string, state cfg.Config, deployment project.Deployer) (*Server
 	}
 
 	// Download build tools
-	go downloadDeps(cli, state.DockerComposeVersion)
+	go downloadDeps(cli, logger, state.DockerComposeVersion)
 
 	return &Server{
 		version: version,
 
-		deployment: deployment,
-		state:      state,
+		deployments: deployments,
+		builds: project.NewBuildLogs(path.Join(state.DataDirectory, "builds"),
+			project.DefaultMaxBuildLogSize, project.DefaultMaxBuilds),
+		state: state,
 
 		docker: cli,
 		websocket: &websocket.Upgrader{
 			HandshakeTimeout: 5 * time.Second,
 		},
+
+		logger: logger,
 	}, nil
 }
 
@@ -59,6 +89,7 @@ func (s *Server) Run(host, port string) error {
 		cert   = path.Join(sslDir, "daemon.cert")
 		key    = path.Join(sslDir, "daemon.key")
 	)
+	s.host = host
 
 	// Check if the cert files are available.
 	_, err = os.Stat(cert)
@@ -68,56 +99,71 @@ func (s *Server) Run(host, port string) error {
 
 	// If they are not available, generate new ones.
 	if keyNotPresent && certNotPresent {
-		fmt.Printf("No certificates found in %s - generating new ones...", sslDir)
+		s.logger.Info("no certificates found - generating new ones", "directory", sslDir)
 		if err = crypto.GenerateCertificate(cert, key, host+":"+port, "RSA"); err != nil {
 			return err
 		}
 	} else {
-		fmt.Printf("Found certificates in %s (%s, %s)",
-			sslDir, cert, key)
+		s.logger.Info("found certificates", "directory", sslDir, "cert", cert, "key", key)
 	}
 
-	// Watch container events
-	go func() {
-		logsCh, errCh := s.deployment.Watch(s.docker)
-		for {
-			select {
-			case err := <-errCh:
-				if err != nil {
-					println(err.Error())
-					return
-				}
-			case event := <-logsCh:
-				println(event)
-			}
-		}
-	}()
+	// Pick up projects set up before the daemon was last restarted
+	s.restoreDeployments()
+
+	// Clean up Docker assets according to the retention policy, if one is
+	// configured
+	if s.state.Retention.Enabled() {
+		go s.enforceRetention(s.state.Retention)
+	}
 
 	// Set up endpoints
-	handler, err := auth.NewPermissionsHandler(path.Join(s.state.DataDirectory, "users.db"), host, 120)
+	handler, err := auth.NewPermissionsHandler(path.Join(s.state.DataDirectory, "users.db"), host, 120,
+		s.logger.With("component", "http"))
 	if err != nil {
 		return err
 	}
 	defer handler.Close()
-	println("Permissions manager successfully created")
+	s.logger.Debug("permissions manager successfully created")
 
 	// GitHub webhook endpoint
 	handler.AttachPublicHandlerFunc("/webhook",
 		s.webhookHandler, http.MethodPost)
+	handler.AttachPublicHandlerFunc("/webhook/registry",
+		s.registryWebhookHandler, http.MethodPost)
 
 	// API endpoints
 	handler.AttachUserRestrictedHandlerFunc("/status",
 		s.statusHandler, http.MethodGet)
 	handler.AttachUserRestrictedHandlerFunc("/logs",
 		s.logHandler, http.MethodGet)
+	handler.AttachUserRestrictedHandlerFunc("/history",
+		s.historyHandler, http.MethodGet)
+	handler.AttachUserRestrictedHandlerFunc("/builds",
+		s.buildsHandler, http.MethodGet)
+	handler.AttachUserRestrictedHandlerFunc("/builds/{id}/logs",
+		s.buildLogsHandler, http.MethodGet)
+	handler.AttachUserRestrictedHandlerFunc("/disk",
+		s.diskHandler, http.MethodGet)
 	handler.AttachAdminRestrictedHandlerFunc("/up",
 		s.upHandler, http.MethodPost)
+	handler.AttachAdminRestrictedHandlerFunc("/up/plan",
+		s.planHandler, http.MethodPost)
+	handler.AttachAdminRestrictedHandlerFunc("/rollback",
+		s.rollbackHandler, http.MethodPost)
+	handler.AttachAdminRestrictedHandlerFunc("/deploy/cancel",
+		s.cancelHandler, http.MethodPost)
 	handler.AttachAdminRestrictedHandlerFunc("/down",
 		s.downHandler, http.MethodPost)
+	handler.AttachAdminRestrictedHandlerFunc("/exec",
+		s.execHandler, http.MethodGet)
+	handler.AttachAdminRestrictedHandlerFunc("/containers/{container}/{action}",
+		s.containerHandler, http.MethodPost)
 	handler.AttachAdminRestrictedHandlerFunc("/reset",
 		s.resetHandler, http.MethodPost)
 	handler.AttachAdminRestrictedHandlerFunc("/env",
 		s.envHandler, http.MethodGet, http.MethodPost)
+	handler.AttachAdminRestrictedHandlerFunc("/git-credentials",
+		s.gitCredentialsHandler, http.MethodGet, http.MethodPost)
 	handler.AttachAdminRestrictedHandlerFunc("/prune",
 		s.pruneHandler, http.MethodPost)
 	handler.AttachAdminRestrictedHandlerFunc("/token",
@@ -129,7 +175,7 @@ func (s *Server) Run(host, port string) error {
 	})
 
 	// Serve daemon on port
-	println("Serving daemon on port " + port)
+	s.logger.Info("serving daemon", "port", port)
 	return http.ListenAndServeTLS(
 		":"+port,
 		cert,
@@ -139,6 +185,130 @@ func (s *Server) Run(host, port string) error {
 
 // Close releases server assets
 func (s *Server) Close() {
-	s.deployment.Down(s.docker, os.Stdout)
+	s.deployments.ForEach(func(name string, d project.Deployer) {
+		d.Down(s.docker, ioutil.Discard)
+	})
 	s.docker.Close()
 }
+
+// watch starts watching container events for the given deployment in the
+// background, until unwatch is called or another watcher is started for it
+func (s *Server) watch(name string, d project.Deployer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.watchersMux.Lock()
+	if s.watchers == nil {
+		s.watchers = make(map[string]context.CancelFunc)
+	}
+	if stop, found := s.watchers[name]; found {
+		stop()
+	}
+	s.watchers[name] = cancel
+	s.watchersMux.Unlock()
+
+	logsCh, errCh := d.Watch(ctx, s.docker)
+	go func() {
+		for {
+			select {
+			case err, ok := <-errCh:
+				if !ok {
+					return
+				}
+				if err != nil {
+					s.logger.Error("stopped watching container events", "project", name, "error", err)
+					return
+				}
+			case event := <-logsCh:
+				s.logger.Info(event, "project", name)
+			}
+		}
+	}()
+}
+
+// unwatch stops watching container events for the named deployment
+func (s *Server) unwatch(name string) {
+	s.watchersMux.Lock()
+	defer s.watchersMux.Unlock()
+	if stop, found := s.watchers[name]; found {
+		stop()
+		delete(s.watchers, name)
+	}
+}
+
+// checkHealth waits for the given deployment to pass its health check. If the
+// check fails, the deployment is rolled back to the previously deployed commit.
+// Cancelling the given context aborts the check and any rollback.
+func (s *Server) checkHealth(ctx context.Context, deployment project.Deployer, out io.Writer) error {
+	err := deployment.CheckHealth(ctx, s.docker, out)
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintln(out, "Project failed health check: "+err.Error())
+	if rollbackErr := deployment.RollbackFailedDeploy(ctx, s.docker, out, err); rollbackErr != nil {
+		fmt.Fprintln(out, "Failed to roll back project: "+rollbackErr.Error())
+	}
+	return err
+}
+
+// queueDeploy runs the given deploy through the deploy queue of the named
+// project, records it as a build with the given trigger, and reports failures
+// to the given stream. The deploy should write its output to the given writer
+// and return an error response if it fails.
+func (s *Server) queueDeploy(
+	name, trigger string,
+	deployment project.Deployer,
+	stream *log.Streamer,
+	deploy func(ctx context.Context, out io.Writer, buildID string) *res.ErrResponse,
+) bool {
+	var queue = s.deployments.Queue(name)
+	if queue.Active() {
+		stream.Println("Another deploy is in progress - this deploy will start once it completes, " +
+			"unless another deploy is requested in the meantime")
+	}
+
+	var failure *res.ErrResponse
+	err := queue.Submit(func(ctx context.Context) error {
+		return s.recordBuild(name, trigger, deployment, stream, func(out io.Writer, buildID string) error {
+			if failure = deploy(ctx, out, buildID); failure == nil {
+				return nil
+			} else if failure.Err != "" {
+				return fmt.Errorf("%s: %s", failure.Message, failure.Err)
+			}
+			return errors.New(failure.Message)
+		})
+	})
+	switch err {
+	case nil:
+		return true
+	case project.ErrDeploySuperseded, project.ErrDeployCancelled:
+		stream.Error(res.Err(err.Error(), http.StatusConflict))
+	default:
+		stream.Error(failure)
+	}
+	return false
+}
+
+// getDeployment retrieves the deployment the given request is scoped to, as
+// specified by the project query parameter, and renders an error response if
+// no appropriate deployment is found
+func (s *Server) getDeployment(w http.ResponseWriter, r *http.Request) (project.Deployer, bool) {
+	_, d, ok := s.getProject(w, r)
+	return d, ok
+}
+
+// getProject is the same as getDeployment, but also retrieves the name of the
+// project the request is scoped to
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) (string, project.Deployer, bool) {
+	name, d, err := s.deployments.Resolve(r.URL.Query().Get(api.Project))
+	switch err {
+	case nil:
+		return name, d, true
+	case project.ErrNoDeployments:
+		render.Render(w, r, res.Err(msgNoDeployment, http.StatusPreconditionFailed))
+	case project.ErrProjectNotSpecified:
+		render.Render(w, r, res.ErrBadRequest(err.Error(),
+			"projects", s.deployments.Names()))
+	default:
+		render.Render(w, r, res.ErrNotFound(err.Error()))
+	}
+	return "", nil, false
+}
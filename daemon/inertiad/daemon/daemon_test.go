@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+// newTestServer creates a server hosting the given deployment as project
+// "test"
+func newTestServer(d project.Deployer) *Server {
+	var s = &Server{
+		deployments: project.NewRegistry(func(string) (project.Deployer, error) {
+			return d, nil
+		}),
+	}
+	s.deployments.GetOrCreate("test")
+	return s
+}
+
+func TestServer_getDeployment(t *testing.T) {
+	var s = &Server{
+		deployments: project.NewRegistry(func(string) (project.Deployer, error) {
+			return &mocks.FakeDeployer{}, nil
+		}),
+	}
+	tests := []struct {
+		name     string
+		projects []string
+		query    string
+		wantCode int
+		wantOK   bool
+	}{
+		{"no deployments", nil, "", http.StatusPreconditionFailed, false},
+		{"single deployment as default", []string{"wow"}, "", http.StatusOK, true},
+		{"specified project", []string{"amazing"}, "?project=wow", http.StatusOK, true},
+		{"unspecified project", nil, "", http.StatusBadRequest, false},
+		{"unknown project", nil, "?project=ohno", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, p := range tt.projects {
+				s.deployments.GetOrCreate(p)
+			}
+			req, err := http.NewRequest("GET", "/status"+tt.query, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			_, ok := s.getDeployment(recorder, req)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+		})
+	}
+}
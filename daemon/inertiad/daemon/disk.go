@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// diskHandler reports disk usage on the remote
+func (s *Server) diskHandler(w http.ResponseWriter, r *http.Request) {
+	usage, err := containers.GetDiskUsage(s.docker)
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve Docker disk usage", err))
+		return
+	}
+	if usage.Persist, err = getPersistUsage(s.state.PersistDirectory); err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve persist directory usage", err))
+		return
+	}
+	if usage.Total, usage.Free, err = getDiskSpace(s.state.PersistDirectory); err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve disk space", err))
+		return
+	}
+
+	render.Render(w, r, res.MsgOK("disk usage retrieved",
+		"disk", usage))
+}
+
+// getPersistUsage reports the disk space used by the given persist directory,
+// which has one directory per project
+func getPersistUsage(dir string) (api.DiskUsageEntry, error) {
+	var usage api.DiskUsageEntry
+	projects, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return usage, nil
+	} else if err != nil {
+		return usage, err
+	}
+	for _, p := range projects {
+		if p.IsDir() {
+			usage.Count++
+		}
+	}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			usage.Size += info.Size()
+		}
+		return nil
+	})
+	return usage, err
+}
+
+// getDiskSpace returns the total and available space, in bytes, of the disk
+// the given directory is on
+func getDiskSpace(dir string) (total, free uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Blocks * uint64(stat.Bsize), stat.Bavail * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+func Test_getPersistUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-persist")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "wow", "data"), os.ModePerm))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "amazing"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "wow", "data", "db"), make([]byte, 100), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "amazing", "log"), make([]byte, 20), 0600))
+
+	usage, err := getPersistUsage(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, api.DiskUsageEntry{Count: 2, Size: 120}, usage)
+
+	// Missing persist directory
+	usage, err = getPersistUsage(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, api.DiskUsageEntry{}, usage)
+}
+
+func Test_getDiskSpace(t *testing.T) {
+	total, free, err := getDiskSpace(os.TempDir())
+	assert.NoError(t, err)
+	assert.True(t, total > 0)
+	assert.True(t, free <= total)
+
+	_, _, err = getDiskSpace("/does/not/exist")
+	assert.Error(t, err)
+}
+
+func Test_freePercent(t *testing.T) {
+	assert.Equal(t, 25.0, freePercent(400, 100))
+	assert.Equal(t, 100.0, freePercent(0, 0))
+}
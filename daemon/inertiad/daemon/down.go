@@ -17,7 +17,11 @@ const (
 
 // downHandler tries to take the deployment offline
 func (s *Server) downHandler(w http.ResponseWriter, r *http.Request) {
-	if status, _ := s.deployment.GetStatus(s.docker); len(status.Containers) == 0 {
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	if status, _ := deployment.GetStatus(s.docker); len(status.Containers) == 0 {
 		render.Render(w, r, res.Err(msgNoDeployment, http.StatusPreconditionFailed))
 		return
 	}
@@ -27,9 +31,9 @@ func (s *Server) downHandler(w http.ResponseWriter, r *http.Request) {
 		Stdout:     os.Stdout,
 		HTTPWriter: w,
 	})
-	defer s.Close()
+	defer stream.Close()
 
-	if err := s.deployment.Down(s.docker, stream); err == containers.ErrNoContainers {
+	if err := deployment.Down(s.docker, stream); err == containers.ErrNoContainers {
 		stream.Error(res.Err(err.Error(), http.StatusPreconditionFailed))
 		return
 	} else if err != nil {
@@ -12,15 +12,13 @@ import (
 )
 
 func TestDownHandlerNoDeployment(t *testing.T) {
-	var s = &Server{
-		deployment: &mocks.FakeDeployer{
-			GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
-				return api.DeploymentStatus{
-					Containers: []string{},
-				}, nil
-			},
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{
+				Containers: []string{},
+			}, nil
 		},
-	}
+	})
 
 	// Assmble request
 	req, err := http.NewRequest("POST", "/down", nil)
@@ -39,7 +39,11 @@ func envPostHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	manager, found := s.deployment.GetDataManager()
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	manager, found := deployment.GetDataManager()
 	if !found {
 		render.Render(w, r, res.Err("no environment manager found", http.StatusPreconditionFailed))
 		return
@@ -65,7 +69,11 @@ func envPostHandler(s *Server, w http.ResponseWriter, r *http.Request) {
 }
 
 func envGetHandler(s *Server, w http.ResponseWriter, r *http.Request) {
-	manager, found := s.deployment.GetDataManager()
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	manager, found := deployment.GetDataManager()
 	if !found {
 		render.Render(w, r, res.Err("no environment manager found", http.StatusPreconditionFailed))
 		return
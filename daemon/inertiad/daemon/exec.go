@@ -0,0 +1,176 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// execHandler runs a command in a project container with a TTY attached, and
+// proxies terminal input and output over a websocket
+func (s *Server) execHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		params    = r.URL.Query()
+		container = params.Get(api.Container)
+		cmd       = params[api.Command]
+	)
+	if container == "" {
+		render.Render(w, r, res.ErrBadRequest("no container provided"))
+		return
+	}
+	if len(cmd) == 0 {
+		cmd = []string{"sh"}
+	}
+
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	if !s.checkProjectContainer(w, r, deployment, container) {
+		return
+	}
+
+	// Set up the exec session before upgrading the connection, so that errors
+	// can be reported as regular responses
+	var ctx = r.Context()
+	exec, err := s.docker.ContainerExecCreate(ctx, strings.TrimPrefix(container, "/"), types.ExecConfig{
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm"},
+		Cmd:          cmd,
+	})
+	if err != nil {
+		if docker.IsErrNotFound(err) {
+			render.Render(w, r, res.ErrNotFound(err.Error()))
+		} else {
+			render.Render(w, r, res.ErrInternalServer("failed to create exec session", err))
+		}
+		return
+	}
+	session, err := s.docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: true})
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to attach to exec session", err))
+		return
+	}
+	defer session.Close()
+
+	socket, err := s.websocket.Upgrade(w, r, nil)
+	if err != nil {
+		render.Render(w, r,
+			res.ErrInternalServer("failed to esablish websocket connection", err))
+		return
+	}
+	defer socket.Close()
+
+	// Proxy the session until the command exits or the client disconnects,
+	// then report how the command exited
+	var exit = api.ExecMessage{Type: api.ExecExit}
+	if err := proxyExec(socket, struct {
+		io.Reader
+		io.Writer
+	}{session.Reader, session.Conn}, func(rows, cols uint) error {
+		return s.docker.ContainerExecResize(ctx, exec.ID, types.ResizeOptions{
+			Height: rows,
+			Width:  cols,
+		})
+	}); err != nil {
+		exit.Error = err.Error()
+	}
+	if inspect, err := s.docker.ContainerExecInspect(ctx, exec.ID); err == nil && !inspect.Running {
+		exit.ExitCode = inspect.ExitCode
+	}
+	socket.WriteJSON(exit)
+	socket.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// proxyExec writes binary messages received from the socket to the session's
+// input, handles resize messages, and writes the session's output to the
+// socket. It blocks until the session's output ends or the socket is closed.
+func proxyExec(socket *websocket.Conn, session io.ReadWriter, resize func(rows, cols uint) error) error {
+	var (
+		outputErr = make(chan error, 1)
+		inputErr  = make(chan error, 1)
+	)
+	go func() {
+		var buf = make([]byte, 32*1024)
+		for {
+			n, err := session.Read(buf)
+			if n > 0 {
+				if err := socket.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					outputErr <- err
+					return
+				}
+			}
+			if err == io.EOF {
+				outputErr <- nil
+				return
+			} else if err != nil {
+				outputErr <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			kind, msg, err := socket.ReadMessage()
+			if err != nil {
+				inputErr <- err
+				return
+			}
+			switch kind {
+			case websocket.BinaryMessage:
+				if _, err := session.Write(msg); err != nil {
+					inputErr <- err
+					return
+				}
+			case websocket.TextMessage:
+				var ctl api.ExecMessage
+				if err := json.Unmarshal(msg, &ctl); err == nil && ctl.Type == api.ExecResize {
+					resize(ctl.Rows, ctl.Cols)
+				}
+			}
+		}
+	}()
+
+	select {
+	case err := <-outputErr:
+		return err
+	case err := <-inputErr:
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return nil
+		}
+		return err
+	}
+}
+
+// checkProjectContainer checks that the given container is an active container
+// of the given deployment, and renders an error response if it is not
+func (s *Server) checkProjectContainer(
+	w http.ResponseWriter,
+	r *http.Request,
+	deployment project.Deployer,
+	container string,
+) bool {
+	status, _ := deployment.GetStatus(s.docker)
+	for _, c := range status.Containers {
+		if strings.TrimPrefix(c, "/") == strings.TrimPrefix(container, "/") {
+			return true
+		}
+	}
+	render.Render(w, r, res.ErrNotFound("container not found in project",
+		"container", container))
+	return false
+}
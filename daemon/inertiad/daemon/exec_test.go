@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestExecHandler(t *testing.T) {
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{Containers: []string{"/web"}}, nil
+		},
+	})
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{"no container", "", http.StatusBadRequest},
+		{"container not in project", "?container=db", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/exec"+tt.query, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(s.execHandler).ServeHTTP(recorder, req)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+		})
+	}
+}
+
+func Test_proxyExec(t *testing.T) {
+	var (
+		stdinR, stdinW   = io.Pipe()
+		stdoutR, stdoutW = io.Pipe()
+		resized          = make(chan [2]uint, 1)
+		done             = make(chan error, 1)
+		upgrader         = websocket.Upgrader{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		socket, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer socket.Close()
+		done <- proxyExec(socket, struct {
+			io.Reader
+			io.Writer
+		}{stdoutR, stdinW}, func(rows, cols uint) error {
+			resized <- [2]uint{rows, cols}
+			return nil
+		})
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	// resize messages are handled
+	assert.NoError(t, client.WriteJSON(api.ExecMessage{Type: api.ExecResize, Rows: 24, Cols: 80}))
+	assert.Equal(t, [2]uint{24, 80}, <-resized)
+
+	// input is written to the session
+	assert.NoError(t, client.WriteMessage(websocket.BinaryMessage, []byte("ls\n")))
+	var input = make([]byte, 3)
+	_, err = io.ReadFull(stdinR, input)
+	assert.NoError(t, err)
+	assert.Equal(t, "ls\n", string(input))
+
+	// output is written to the socket
+	go stdoutW.Write([]byte("hello"))
+	kind, output, err := client.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, kind)
+	assert.Equal(t, "hello", string(output))
+
+	// the session ends once its output ends
+	stdoutW.Close()
+	assert.NoError(t, <-done)
+}
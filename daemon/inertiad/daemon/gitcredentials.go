@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// gitCredentialsHandler manages requests to manage the credentials used to
+// access HTTPS git remotes
+func (s *Server) gitCredentialsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		gitCredentialsPostHandler(s, w, r)
+	} else if r.Method == "GET" {
+		gitCredentialsGetHandler(s, w, r)
+	}
+}
+
+func gitCredentialsPostHandler(s *Server, w http.ResponseWriter, r *http.Request) {
+	// Parse request
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+	var credsReq api.GitCredentialsRequest
+	if err = json.Unmarshal(body, &credsReq); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return
+	}
+	if !credsReq.Remove && (credsReq.Username == "" || credsReq.Token == "") {
+		render.Render(w, r, res.ErrBadRequest("a username and token are required"))
+		return
+	}
+
+	// Credentials are needed before a project's first deployment, so set up
+	// the named project if it does not exist yet
+	var deployment project.Deployer
+	if name := r.URL.Query().Get(api.Project); name != "" && !credsReq.Remove {
+		var created bool
+		if deployment, created, err = s.deployments.GetOrCreate(name); err != nil {
+			render.Render(w, r, res.ErrBadRequest(err.Error()))
+			return
+		}
+		if created {
+			s.watch(name, deployment)
+		}
+	} else {
+		var ok bool
+		if deployment, ok = s.getDeployment(w, r); !ok {
+			return
+		}
+	}
+	manager, found := deployment.GetDataManager()
+	if !found {
+		render.Render(w, r, res.Err("no data manager found", http.StatusPreconditionFailed))
+		return
+	}
+
+	// Update or remove stored credentials
+	if credsReq.Remove {
+		err = manager.RemoveGitCredentials()
+	} else {
+		err = manager.SetGitCredentials(project.GitCredentials{
+			Username: credsReq.Username,
+			Token:    credsReq.Token,
+		})
+	}
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to update git credentials", err))
+		return
+	}
+
+	render.Render(w, r, res.Msg(
+		"git credentials updated - these will be used the next time your project is fetched",
+		http.StatusAccepted))
+}
+
+func gitCredentialsGetHandler(s *Server, w http.ResponseWriter, r *http.Request) {
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	manager, found := deployment.GetDataManager()
+	if !found {
+		render.Render(w, r, res.Err("no data manager found", http.StatusPreconditionFailed))
+		return
+	}
+
+	creds, err := manager.GetGitCredentials()
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve git credentials", err))
+		return
+	}
+	if creds == nil {
+		render.Render(w, r, res.Err("no git credentials configured", http.StatusNotFound))
+		return
+	}
+
+	// never expose the stored token
+	render.Render(w, r, res.Msg("git credentials retrieved", http.StatusOK,
+		"username", creds.Username))
+}
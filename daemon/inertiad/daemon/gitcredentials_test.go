@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestGitCredentialsHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-git-credentials")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	manager, err := project.NewDataManager(filepath.Join(dir, "deployment.db"), filepath.Join(dir, "key"))
+	assert.NoError(t, err)
+
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetDataManagerStub: func() (*project.DeploymentDataManager, bool) {
+			return manager, true
+		},
+	})
+	var do = func(method, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, "/git-credentials", strings.NewReader(body))
+		assert.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		http.HandlerFunc(s.gitCredentialsHandler).ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// nothing configured
+	assert.Equal(t, http.StatusNotFound, do("GET", "").Code)
+
+	// invalid request
+	assert.Equal(t, http.StatusBadRequest, do("POST", `{"username":"bob"}`).Code)
+
+	// set credentials - token should never be returned
+	assert.Equal(t, http.StatusAccepted, do("POST", `{"username":"bob","token":"sekret"}`).Code)
+	recorder := do("GET", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "bob")
+	assert.NotContains(t, recorder.Body.String(), "sekret")
+
+	// remove credentials
+	assert.Equal(t, http.StatusAccepted, do("POST", `{"remove":true}`).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "").Code)
+}
@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// historyHandler lists past deployments of the project
+func (s *Server) historyHandler(w http.ResponseWriter, r *http.Request) {
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
+		return
+	}
+	history, err := deployment.GetHistory()
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve deployment history", err))
+		return
+	}
+
+	render.Render(w, r, res.MsgOK("deployment history retrieved",
+		"history", history))
+}
+
+// rollbackHandler redeploys a previously deployed commit
+func (s *Server) rollbackHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+	var rollbackReq api.RollbackRequest
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &rollbackReq); err != nil {
+			render.Render(w, r, res.ErrBadRequest(err.Error()))
+			return
+		}
+	}
+
+	// Rollbacks require an existing deployment
+	name, deployment, ok := s.getProject(w, r)
+	if !ok {
+		return
+	}
+	status, _ := deployment.GetStatus(s.docker)
+	if status.CommitHash == "" {
+		render.Render(w, r, res.Err(msgNoDeployment, http.StatusPreconditionFailed))
+		return
+	}
+
+	// Find commit to roll back to
+	history, err := deployment.GetHistory()
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to retrieve deployment history", err))
+		return
+	}
+	target, err := getRollbackTarget(status.CommitHash, history, rollbackReq)
+	if err != nil {
+		render.Render(w, r, res.ErrNotFound(err.Error()))
+		return
+	}
+
+	// Configure streamer
+	var stream = log.NewStreamer(log.StreamerOptions{
+		Request:    r,
+		Stdout:     os.Stdout,
+		HTTPWriter: w,
+		HTTPStream: rollbackReq.Stream,
+	})
+	defer stream.Close()
+	stream.Println(fmt.Sprintf("Rolling back to commit %s (deployed %s)",
+		target.CommitHash, target.DeployedAt.Format("2006-01-02 15:04:05")))
+
+	// Deploy target commit once other deploys of this project are done
+	if ok := s.queueDeploy(name, project.BuildTriggerRollback, deployment, stream, func(
+		ctx context.Context,
+		out io.Writer,
+		buildID string,
+	) *res.ErrResponse {
+		deploy, err := deployment.Deploy(ctx, s.docker, out, project.DeployOptions{
+			Commit:  target.CommitHash,
+			BuildID: buildID,
+		})
+		if err != nil {
+			return res.ErrInternalServer("failed to build project", err)
+		}
+
+		if err = deploy(); err != nil {
+			return res.ErrInternalServer("failed to deploy project", err)
+		}
+
+		// Rollbacks are not rolled back again if they fail their health check
+		if err = deployment.CheckHealth(ctx, s.docker, out); err != nil {
+			return res.ErrInternalServer("project failed health check", err)
+		}
+
+		// Update container management history following a successful build and deployment
+		if err = deployment.UpdateContainerHistory(s.docker); err != nil {
+			fmt.Fprintln(out, "warning: failed to update container history:", err)
+		}
+		return nil
+	}); !ok {
+		return
+	}
+
+	stream.Success(res.Msg("Project rollback initiated!", http.StatusCreated))
+}
+
+// getRollbackTarget finds the deployment record the given request refers to.
+// If a commit is requested, the most recent deployment of a commit with the
+// given prefix is returned. Otherwise, history is walked back the requested
+// number of distinct commits from the current commit (default 1).
+func getRollbackTarget(
+	current string,
+	history []api.DeploymentRecord,
+	req api.RollbackRequest,
+) (*api.DeploymentRecord, error) {
+	if req.Commit != "" {
+		for i, h := range history {
+			if !h.Failed && strings.HasPrefix(h.CommitHash, req.Commit) {
+				return &history[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no deployment of commit '%s' found in history", req.Commit)
+	}
+
+	var steps = req.Steps
+	if steps < 1 {
+		steps = 1
+	}
+	var last, remaining = current, steps
+	for i, h := range history {
+		if h.Failed || h.CommitHash == last {
+			continue
+		}
+		last = h.CommitHash
+		if remaining--; remaining == 0 {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("not enough deployments in history to roll back %d commit(s)", steps)
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetHistoryStub: func() ([]api.DeploymentRecord, error) {
+			return []api.DeploymentRecord{
+				{CommitHash: "abcde", Branch: "wow"},
+				{CommitHash: "12345", Branch: "wow"},
+			}, nil
+		},
+	})
+
+	req, err := http.NewRequest("GET", "/history", nil)
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(s.historyHandler).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "abcde")
+	assert.Contains(t, recorder.Body.String(), "12345")
+}
+
+func TestRollbackHandlerNoDeployment(t *testing.T) {
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{}, nil
+		},
+	})
+
+	req, err := http.NewRequest("POST", "/rollback", strings.NewReader(`{"steps":1}`))
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(s.rollbackHandler).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), msgNoDeployment)
+}
+
+func Test_getRollbackTarget(t *testing.T) {
+	var history = []api.DeploymentRecord{
+		{CommitHash: "dddd4444", Failed: true},
+		{CommitHash: "cccc3333"},
+		{CommitHash: "cccc3333"},
+		{CommitHash: "eeee5555", Failed: true},
+		{CommitHash: "bbbb2222"},
+		{CommitHash: "aaaa1111"},
+	}
+	tests := []struct {
+		name    string
+		current string
+		req     api.RollbackRequest
+		want    string
+		wantErr bool
+	}{
+		{"default to previous commit", "cccc3333", api.RollbackRequest{}, "bbbb2222", false},
+		{"skip repeated deployments", "cccc3333", api.RollbackRequest{Steps: 2}, "aaaa1111", false},
+		{"current commit not most recent record", "dddd4444", api.RollbackRequest{Steps: 1}, "cccc3333", false},
+		{"too many steps", "cccc3333", api.RollbackRequest{Steps: 3}, "", true},
+		{"commit prefix", "cccc3333", api.RollbackRequest{Commit: "aaaa"}, "aaaa1111", false},
+		{"unknown commit", "cccc3333", api.RollbackRequest{Commit: "ffff"}, "", true},
+		{"failed commit", "cccc3333", api.RollbackRequest{Commit: "eeee"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getRollbackTarget(tt.current, history, tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.CommitHash)
+		})
+	}
+
+	// Errors should report the number of commits that were to be rolled back
+	_, err := getRollbackTarget("aaaa1111", history[5:], api.RollbackRequest{})
+	assert.EqualError(t, err, "not enough deployments in history to roll back 1 commit(s)")
+}
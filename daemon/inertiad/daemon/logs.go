@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"bytes"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -16,6 +17,9 @@ import (
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
 )
 
+// daemonContainer is the name of the container the daemon runs in
+const daemonContainer = "inertia-daemon"
+
 // logHandler handles requests for container logs
 func (s *Server) logHandler(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -30,7 +34,6 @@ func (s *Server) logHandler(w http.ResponseWriter, r *http.Request) {
 	if streamParam != "" {
 		s, err := strconv.ParseBool(streamParam)
 		if err != nil {
-			println(err.Error())
 			render.Render(w, r, res.ErrBadRequest(err.Error()))
 			return
 		}
@@ -53,6 +56,31 @@ func (s *Server) logHandler(w http.ResponseWriter, r *http.Request) {
 		entries = 500
 	}
 
+	// Only the daemon's own logs are structured, so only they can be
+	// filtered by level
+	var isDaemon = strings.TrimPrefix(container, "/") == daemonContainer
+	var level = log.LevelDebug
+	if levelParam := params.Get(api.Level); levelParam != "" {
+		if !isDaemon {
+			render.Render(w, r, res.ErrBadRequest("logs can only be filtered by level for the daemon"))
+			return
+		}
+		if level, err = log.ParseLevel(levelParam); err != nil {
+			render.Render(w, r, res.ErrBadRequest("invalid log level",
+				"error", err))
+			return
+		}
+	}
+
+	// If a project is specified, only allow access to the daemon and the
+	// project's own containers
+	if params.Get(api.Project) != "" && !isDaemon {
+		deployment, ok := s.getDeployment(w, r)
+		if !ok || !s.checkProjectContainer(w, r, deployment, container) {
+			return
+		}
+	}
+
 	// Upgrade to websocket connection if required, otherwise just set up a
 	// standard streamer
 	var stream *log.Streamer
@@ -91,6 +119,10 @@ func (s *Server) logHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer logs.Close()
+	var filtered io.Reader = logs
+	if level > log.LevelDebug {
+		filtered = log.FilterLevel(logs, level)
+	}
 
 	if shouldStream {
 		var stop = make(chan struct{})
@@ -99,12 +131,12 @@ func (s *Server) logHandler(w http.ResponseWriter, r *http.Request) {
 			stream.Error(res.ErrInternalServer("failed to write to socket", err))
 			return
 		}
-		log.FlushRoutine(socket, logs, stop)
+		log.FlushRoutine(socket, filtered, stop)
 		defer stream.Close()
 		defer close(stop)
 	} else {
 		buf := new(bytes.Buffer)
-		buf.ReadFrom(logs)
+		buf.ReadFrom(filtered)
 		render.Render(w, r, res.MsgOK("logs retrieved",
 			"logs", strings.Split(buf.String(), "\n")))
 	}
@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestLogHandlerContainerNotInProject(t *testing.T) {
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{
+				Containers: []string{"/test"},
+			}, nil
+		},
+	})
+
+	req, err := http.NewRequest("GET", "/logs?project=test&container=/other", nil)
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(s.logHandler).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "container not found in project")
+}
+
+func TestLogHandlerInvalidLevel(t *testing.T) {
+	var s = newTestServer(&mocks.FakeDeployer{})
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"project container", "container=/test&level=warn", "only be filtered by level for the daemon"},
+		{"unknown level", "container=/inertia-daemon&level=loud", "invalid log level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/logs?"+tt.query, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(s.logHandler).ServeHTTP(recorder, req)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.wantErr)
+		})
+	}
+}
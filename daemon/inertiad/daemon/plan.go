@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
+)
+
+// planHandler describes what the given up request would change, without
+// deploying anything
+func (s *Server) planHandler(w http.ResponseWriter, r *http.Request) {
+	upReq, ok := readUpRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// projects that have not been set up yet would be deployed from scratch
+	deployment, found := s.deployments.Get(upReq.Project)
+	if !found {
+		render.Render(w, r, res.MsgOK("deployment plan generated",
+			"plan", api.DeploymentPlan{Project: upReq.Project, NewDeployment: true}))
+		return
+	}
+	plan, err := deployment.Plan(deploymentConfig(upReq))
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to generate deployment plan", err))
+		return
+	}
+	if !plan.NewDeployment {
+		if err := deployment.CompareRemotes(upReq.GitOptions.RemoteURL); err != nil {
+			render.Render(w, r, res.Err(err.Error(), http.StatusPreconditionFailed))
+			return
+		}
+		if status, err := deployment.GetStatus(s.docker); err == nil {
+			plan.ContainersToStop = status.Containers
+		}
+	}
+
+	render.Render(w, r, res.MsgOK("deployment plan generated",
+		"plan", plan))
+}
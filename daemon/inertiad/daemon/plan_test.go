@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestPlanHandler(t *testing.T) {
+	var fake = &mocks.FakeDeployer{}
+	fake.PlanStub = func(cfg project.DeploymentConfig) (api.DeploymentPlan, error) {
+		return api.DeploymentPlan{Project: cfg.ProjectName, TargetRef: cfg.Ref}, nil
+	}
+	fake.GetStatusReturns(api.DeploymentStatus{Containers: []string{"/web"}}, nil)
+	var s = newTestServer(fake)
+
+	tests := []struct {
+		name       string
+		project    string
+		wantCode   int
+		wantNew    bool
+		wantStop   []string
+		wantCalled bool
+	}{
+		{"invalid project", "", http.StatusBadRequest, false, nil, false},
+		{"new project", "new", http.StatusOK, true, nil, false},
+		{"existing project", "test", http.StatusOK, false, []string{"/web"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls = fake.PlanCallCount()
+			body, err := json.Marshal(api.UpRequest{
+				Project:    tt.project,
+				GitOptions: api.GitOptions{Ref: "v1.0.0"},
+			})
+			assert.NoError(t, err)
+			req, err := http.NewRequest("POST", "/up/plan", bytes.NewReader(body))
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(s.planHandler).ServeHTTP(recorder, req)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+			assert.Equal(t, tt.wantCalled, fake.PlanCallCount() > calls)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data struct {
+					Plan api.DeploymentPlan `json:"plan"`
+				} `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&resp))
+			assert.Equal(t, tt.project, resp.Data.Plan.Project)
+			assert.Equal(t, tt.wantNew, resp.Data.Plan.NewDeployment)
+			assert.Equal(t, tt.wantStop, resp.Data.Plan.ContainersToStop)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/crypto"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/webhook"
+)
+
+// deployPreview deploys the head branch of the given pull request as a preview
+// of the named project, setting up the preview if it does not exist yet, and
+// posts the outcome to the project's notifiers
+func deployPreview(
+	s *Server,
+	logger *log.Logger,
+	name string,
+	deployment project.Deployer,
+	pr webhook.PullRequest,
+) {
+	conf, err := deployment.GetPreviewConfig(project.PullRequest{
+		Number: pr.Number,
+		Branch: pr.HeadBranch,
+	}, s.host)
+	if err != nil {
+		logger.Info("ignoring event", "reason", err.Error())
+		return
+	}
+	conf.PemFilePath = crypto.DaemonInertiaKeyLocation
+
+	var previewName = conf.ProjectName
+	preview, created, err := s.deployments.GetOrCreate(previewName)
+	if err != nil {
+		logger.Error("failed to set up preview", "error", err)
+		return
+	}
+	logger = logger.With("preview", previewName)
+	if created {
+		s.watch(previewName, preview)
+	}
+
+	logger.Info("accepting event", "reason", "deploying preview of pull request")
+	err = s.deployments.Queue(previewName).Submit(func(ctx context.Context) error {
+		// Pull request events are also sent for changes such as new titles,
+		// which don't need a new deploy
+		status, _ := preview.GetStatus(s.docker)
+		if pr.HeadCommit != "" && strings.HasPrefix(status.CommitHash, pr.HeadCommit) {
+			logger.Info("commit is already deployed", "commit", status.CommitHash)
+			return nil
+		}
+
+		// Previews use the project's environment variables and git
+		// credentials, which may have changed since the last deploy
+		if err := copySecrets(deployment, preview); err != nil {
+			return fmt.Errorf("failed to copy secrets: %s", err.Error())
+		}
+
+		preview.SetConfig(conf)
+		if err := s.recordBuild(previewName, project.BuildTriggerPreview, preview, ioutil.Discard, func(
+			out io.Writer,
+			buildID string,
+		) error {
+			var skipUpdate = false
+			if status.CommitHash == "" {
+				if err := preview.Initialize(conf, out); err != nil {
+					return fmt.Errorf("setup failed: %s", err.Error())
+				}
+				skipUpdate = true
+			}
+
+			deploy, err := preview.Deploy(ctx, s.docker, out, project.DeployOptions{
+				SkipUpdate: skipUpdate,
+				BuildID:    buildID,
+			})
+			if err != nil {
+				return fmt.Errorf("build failed: %s", err.Error())
+			}
+			if err = deploy(); err != nil {
+				return fmt.Errorf("deploy failed: %s", err.Error())
+			}
+			if err = s.checkHealth(ctx, preview, out); err != nil {
+				return fmt.Errorf("health check failed: %s", err.Error())
+			}
+			if err = preview.UpdateContainerHistory(s.docker); err != nil {
+				fmt.Fprintf(out, "[%s] Failed to update container history: %s\n", previewName, err.Error())
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		var msg = fmt.Sprintf("Preview of pull request #%d (%s) deployed as project %s",
+			pr.Number, pr.Title, previewName)
+		if host := conf.Labels[containers.LabelPreviewHost]; host != "" {
+			msg = fmt.Sprintf("%s - it can be reached at %s once your reverse proxy routes %s to it",
+				msg, conf.PreviewURL, host)
+		} else if conf.PreviewURL != "" {
+			msg = fmt.Sprintf("Preview of pull request #%d (%s) deployed at %s",
+				pr.Number, pr.Title, conf.PreviewURL)
+		}
+		if err := preview.Notify(msg, notify.Options{Color: notify.Green}); err != nil {
+			logger.Warn("failed to send notification", "error", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("preview not deployed", "error", err)
+		if notifyErr := preview.Notify(
+			fmt.Sprintf("Preview of pull request #%d failed: %s", pr.Number, err.Error()),
+			notify.Options{Color: notify.Red},
+		); notifyErr != nil {
+			logger.Warn("failed to send notification", "error", notifyErr)
+		}
+	}
+}
+
+// removePreview tears down the named preview of the given pull request and
+// removes it from the daemon, if it exists
+func removePreview(s *Server, logger *log.Logger, previewName string, pr webhook.PullRequest) {
+	logger = logger.With("preview", previewName)
+	preview, found := s.deployments.Get(previewName)
+	if !found {
+		logger.Info("ignoring event", "reason", "no preview of pull request is deployed")
+		return
+	}
+
+	logger.Info("accepting event", "reason", "removing preview of pull request")
+	if err := s.deployments.Queue(previewName).Submit(func(ctx context.Context) error {
+		return preview.Destroy(s.docker, ioutil.Discard)
+	}); err != nil {
+		logger.Error("preview not removed", "error", err)
+		return
+	}
+
+	// Release the preview's database and stop watching its containers, so
+	// that the preview can be set up again if the pull request is reopened
+	s.deployments.Remove(previewName)
+	s.unwatch(previewName)
+	if manager, found := preview.GetDataManager(); found {
+		if err := manager.Close(); err != nil {
+			logger.Warn("failed to close database", "error", err)
+		}
+	}
+
+	if err := preview.Notify(fmt.Sprintf("Preview of pull request #%d removed", pr.Number),
+		notify.Options{Color: notify.Green}); err != nil {
+		logger.Warn("failed to send notification", "error", err)
+	}
+}
+
+// copySecrets copies the environment variables and git credentials of the
+// given deployment to its preview
+func copySecrets(deployment, preview project.Deployer) error {
+	src, found := deployment.GetDataManager()
+	if !found {
+		return errors.New("no data manager found")
+	}
+	dst, found := preview.GetDataManager()
+	if !found {
+		return errors.New("no data manager found for preview")
+	}
+	return src.CopySecrets(dst)
+}
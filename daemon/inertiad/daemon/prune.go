@@ -4,16 +4,14 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/go-chi/render"
-
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
 )
 
-// pruneHandler cleans up Docker assets
+// pruneHandler cleans up Docker assets of the requested project
 func (s *Server) pruneHandler(w http.ResponseWriter, r *http.Request) {
-	if s.deployment == nil {
-		render.Render(w, r, res.Err(msgNoDeployment, http.StatusPreconditionFailed))
+	deployment, ok := s.getDeployment(w, r)
+	if !ok {
 		return
 	}
 
@@ -24,7 +22,11 @@ func (s *Server) pruneHandler(w http.ResponseWriter, r *http.Request) {
 	})
 	defer stream.Close()
 
-	if err := s.deployment.Prune(s.docker, stream); err != nil {
+	// Images built by deploys are only used once the deploy completes, so
+	// wait for deploys in progress to avoid removing them
+	var release = s.deployments.HoldDeploys()
+	defer release()
+	if err := deployment.Prune(s.docker, stream); err != nil {
 		stream.Error(res.ErrInternalServer("failed to prune Docker assets", err))
 		return
 	}
@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestPruneHandler(t *testing.T) {
+	var pruned = map[string]int{}
+	var s = &Server{
+		deployments: project.NewRegistry(func(name string) (project.Deployer, error) {
+			return &mocks.FakeDeployer{
+				PruneStub: func(*docker.Client, io.Writer) error {
+					pruned[name]++
+					return nil
+				},
+			}, nil
+		}),
+	}
+	s.deployments.GetOrCreate("wow")
+	s.deployments.GetOrCreate("amazing")
+
+	// Only the requested project should be pruned
+	req, err := http.NewRequest("POST", "/prune?project=wow", nil)
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(s.pruneHandler).ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, map[string]int{"wow": 1}, pruned)
+}
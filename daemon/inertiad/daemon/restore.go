@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/ubclaunchpad/inertia/common"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/cfg"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/git"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+)
+
+// restoreDeployments registers the projects set up by previous runs of the
+// daemon, so that they can be managed without being set up again. Projects
+// deployed by versions of the daemon that only hosted a single project are
+// moved to where projects are kept now first.
+func (s *Server) restoreDeployments() {
+	if name, err := migrateLegacyProject(s.state); err != nil {
+		s.logger.Error("failed to migrate project", "error", err)
+	} else if name != "" {
+		s.logger.Info("migrated project to multi-project layout", "project", name)
+	}
+
+	entries, err := ioutil.ReadDir(s.state.ProjectDirectory)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.logger.Error("failed to read project directory", "error", err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		var name = entry.Name()
+		if !entry.IsDir() || project.ValidateProjectName(name) != nil {
+			continue
+		}
+		if _, err := os.Stat(path.Join(s.state.ProjectDirectory, name, ".git")); err != nil {
+			continue
+		}
+
+		deployment, created, err := s.deployments.GetOrCreate(name)
+		if err != nil {
+			s.logger.Error("failed to restore project", "project", name, "error", err)
+			continue
+		}
+		if created {
+			s.watch(name, deployment)
+		}
+		// Projects that can't be restored are left registered, and are set up
+		// again by their next deploy
+		if err := deployment.Load(); err != nil {
+			s.logger.Warn("failed to restore project", "project", name, "error", err)
+			continue
+		}
+		s.logger.Info("restored project", "project", name)
+	}
+}
+
+// migrateLegacyProject moves a project deployed by a version of the daemon
+// that only hosted a single project, which kept the project's repository and
+// persistent data directly in the project and persist directories and its
+// database at project.db in the data directory, to where the project is kept
+// now. The name of the migrated project is returned, or an empty string if
+// there was nothing to migrate.
+func migrateLegacyProject(conf cfg.Config) (string, error) {
+	if _, err := os.Stat(path.Join(conf.ProjectDirectory, ".git")); err != nil {
+		return "", nil
+	}
+	var legacyDatabase = path.Join(conf.DataDirectory, "project.db")
+	name, err := legacyProjectName(conf.ProjectDirectory, legacyDatabase)
+	if err != nil {
+		return "", err
+	}
+
+	if err := moveContents(conf.ProjectDirectory, name); err != nil {
+		return "", fmt.Errorf("failed to move repository: %s", err.Error())
+	}
+	if _, err := os.Stat(conf.PersistDirectory); err == nil {
+		if err := moveContents(conf.PersistDirectory, name); err != nil {
+			return "", fmt.Errorf("failed to move persistent data: %s", err.Error())
+		}
+	}
+	if _, err := os.Stat(legacyDatabase); err == nil {
+		var databaseDir = path.Join(conf.DataDirectory, "projects")
+		if err := os.MkdirAll(databaseDir, os.ModePerm); err != nil {
+			return "", err
+		}
+		if err := os.Rename(legacyDatabase, path.Join(databaseDir, name+".db")); err != nil {
+			return "", fmt.Errorf("failed to move database: %s", err.Error())
+		}
+	}
+	return name, nil
+}
+
+// legacyProjectName determines the name of a project deployed by a version of
+// the daemon that only hosted a single project. Deployed projects recorded
+// their name with their build data - otherwise, the name of the project's
+// repository is used.
+func legacyProjectName(directory, database string) (string, error) {
+	if _, err := os.Stat(database); err == nil {
+		names, err := project.DeployedProjectNames(database)
+		if err != nil {
+			return "", err
+		}
+		if len(names) == 1 && project.ValidateProjectName(names[0]) == nil {
+			return names[0], nil
+		}
+	}
+
+	repo, err := git.OpenRepository(directory)
+	if err != nil {
+		return "", err
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", err
+	}
+	if len(remote.Config().URLs) == 0 {
+		return "", errors.New("unable to determine project name - repository has no origin URL")
+	}
+	var name = path.Base(common.ExtractRepository(remote.Config().URLs[0]))
+	if err := project.ValidateProjectName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// moveContents moves everything in the given directory into a new
+// subdirectory with the given name. The directory itself is left in place,
+// since it may be a mount.
+func moveContents(dir, name string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Move everything into a temporary directory first, in case the
+	// directory already contains an entry with the given name
+	tmp, err := ioutil.TempDir(dir, ".migrate-")
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, info.Mode().Perm()); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(path.Join(dir, entry.Name()), path.Join(tmp, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, path.Join(dir, name))
+}
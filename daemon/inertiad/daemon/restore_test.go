@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/cfg"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestServer_restoreDeployments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-restore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Only project repositories should be restored
+	var projects = path.Join(dir, "project")
+	for _, p := range []string{"wow/.git", "broken/.git", "empty", ".migrate-123/.git"} {
+		assert.NoError(t, os.MkdirAll(path.Join(projects, p), os.ModePerm))
+	}
+
+	var loaded = map[string]*mocks.FakeDeployer{}
+	var s = &Server{
+		state: cfg.Config{ProjectDirectory: projects, DataDirectory: path.Join(dir, "data")},
+		deployments: project.NewRegistry(func(name string) (project.Deployer, error) {
+			var d = &mocks.FakeDeployer{}
+			if name == "broken" {
+				d.LoadReturns(errors.New("oh no"))
+			}
+			loaded[name] = d
+			return d, nil
+		}),
+	}
+	s.restoreDeployments()
+
+	// Projects that fail to load are still registered
+	assert.Equal(t, []string{"broken", "wow"}, s.deployments.Names())
+	for _, d := range loaded {
+		assert.Equal(t, 1, d.LoadCallCount())
+	}
+}
+
+func Test_migrateLegacyProject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-migrate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	var conf = cfg.Config{
+		ProjectDirectory: path.Join(dir, "project"),
+		PersistDirectory: path.Join(dir, "persist"),
+		DataDirectory:    path.Join(dir, "data"),
+	}
+	for _, d := range []string{conf.PersistDirectory, conf.DataDirectory} {
+		assert.NoError(t, os.MkdirAll(d, os.ModePerm))
+	}
+
+	// Nothing to migrate
+	name, err := migrateLegacyProject(conf)
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+
+	// Set up the single-project layout - the project repository has a
+	// directory with the same name as the project
+	repo, err := gogit.PlainInit(conf.ProjectDirectory, false)
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"git@github.com:ubclaunchpad/inertia.git"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(path.Join(conf.ProjectDirectory, "wow"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(path.Join(conf.PersistDirectory, "data"), []byte("data"), 0644))
+	db, err := project.NewDataManager(path.Join(conf.DataDirectory, "project.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+	assert.NoError(t, db.AddProjectBuildData("wow", project.DeploymentMetadata{Hash: "first"}))
+	assert.NoError(t, db.Close())
+
+	name, err = migrateLegacyProject(conf)
+	assert.NoError(t, err)
+	assert.Equal(t, "wow", name)
+	for _, p := range []string{
+		path.Join(conf.ProjectDirectory, "wow", ".git"),
+		path.Join(conf.ProjectDirectory, "wow", "wow"),
+		path.Join(conf.PersistDirectory, "wow", "data"),
+		path.Join(conf.DataDirectory, "projects", "wow.db"),
+	} {
+		_, err := os.Stat(p)
+		assert.NoError(t, err, p)
+	}
+	_, err = os.Stat(path.Join(conf.DataDirectory, "project.db"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Migrated projects should not be migrated again
+	name, err = migrateLegacyProject(conf)
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+}
+
+func Test_legacyProjectName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-migrate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Projects that were never deployed are named after their repository
+	repo, err := gogit.PlainInit(dir, false)
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"https://github.com/ubclaunchpad/inertia.git"},
+	})
+	assert.NoError(t, err)
+	name, err := legacyProjectName(dir, path.Join(dir, "project.db"))
+	assert.NoError(t, err)
+	assert.Equal(t, "inertia", name)
+}
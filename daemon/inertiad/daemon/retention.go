@@ -0,0 +1,140 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	units "github.com/docker/go-units"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/cfg"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
+)
+
+// enforceRetention cleans up Docker assets according to the given retention
+// policy on the policy's interval. Best used as a goroutine.
+func (s *Server) enforceRetention(policy cfg.Retention) {
+	s.logger.Info("enforcing retention policy", "component", "retention", "interval", policy.Interval)
+	for {
+		s.collectGarbage(policy, time.Now())
+		time.Sleep(policy.Interval)
+	}
+}
+
+// collectGarbage removes archived containers and unused images that are not
+// retained by the given policy, and logs the outcome of the run
+func (s *Server) collectGarbage(policy cfg.Retention, now time.Time) {
+	var logger = s.logger.With("component", "retention")
+
+	// Images that are being built may not be used by a container yet, so runs
+	// are skipped while projects are being deployed, and deploys are held off
+	// until the run is done
+	for _, name := range s.deployments.Names() {
+		if s.deployments.Queue(name).Active() {
+			logger.Info("skipping run: project is being deployed", "project", name)
+			return
+		}
+	}
+	var release = s.deployments.HoldDeploys()
+	defer release()
+
+	var dir = s.state.PersistDirectory
+	_, freeBefore, err := getDiskSpace(dir)
+	if err != nil {
+		logger.Warn("failed to check disk space", "error", err)
+	}
+
+	var removedContainers, removedImages = s.removeAssets(logger, func(assets []containers.Asset) []containers.Asset {
+		return containers.SelectExpired(assets, policy.Keep, policy.MaxAge, now)
+	})
+	if policy.MinFreePercent > 0 {
+		total, free, err := getDiskSpace(dir)
+		if err != nil {
+			logger.Warn("failed to check disk space", "error", err)
+		} else if percent := freePercent(total, free); percent < policy.MinFreePercent {
+			logger.Warn("disk space is low - removing all archived containers, unused images, and build cache",
+				"free_percent", fmt.Sprintf("%.1f", percent))
+			if _, err := containers.PruneBuildCache(s.docker); err != nil {
+				logger.Warn("failed to prune build cache", "error", err)
+			}
+			var c, i = s.removeAssets(logger, func(assets []containers.Asset) []containers.Asset {
+				return assets
+			})
+			removedContainers += c
+			removedImages += i
+		}
+	}
+
+	total, freeAfter, err := getDiskSpace(dir)
+	if err != nil {
+		logger.Info("retention policy enforced",
+			"removed_containers", removedContainers, "removed_images", removedImages)
+		return
+	}
+	var freed uint64
+	if freeAfter > freeBefore {
+		freed = freeAfter - freeBefore
+	}
+	logger.Info("retention policy enforced",
+		"removed_containers", removedContainers, "removed_images", removedImages,
+		"freed", units.HumanSize(float64(freed)),
+		"free", units.HumanSize(float64(freeAfter)),
+		"free_percent", fmt.Sprintf("%.1f", freePercent(total, freeAfter)))
+}
+
+// removeAssets removes the given selection of archived containers, and then
+// of the images that are no longer used, and returns the number of containers
+// and images removed
+func (s *Server) removeAssets(
+	logger *log.Logger,
+	selectAssets func([]containers.Asset) []containers.Asset,
+) (int, int) {
+	archived, err := containers.GetArchivedContainers(s.docker)
+	if err != nil {
+		logger.Warn("failed to list archived containers", "error", err)
+		return 0, 0
+	}
+	var removedContainers = remove(s.docker, logger, "container",
+		selectAssets(archived), containers.RemoveContainer)
+
+	// Images are only unused once the containers that used them are removed
+	images, err := containers.GetUnusedImages(s.docker)
+	if err != nil {
+		logger.Warn("failed to list unused images", "error", err)
+		return removedContainers, 0
+	}
+	var removedImages = remove(s.docker, logger, "image",
+		selectAssets(images), containers.RemoveImage)
+
+	return removedContainers, removedImages
+}
+
+// remove removes each of the given assets of the given kind, logging failures,
+// and returns the number of assets removed
+func remove(
+	cli *docker.Client,
+	logger *log.Logger,
+	kind string,
+	assets []containers.Asset,
+	removeAsset func(*docker.Client, string) error,
+) int {
+	var removed = 0
+	for _, a := range assets {
+		if err := removeAsset(cli, a.ID); err != nil {
+			logger.Warn("failed to remove "+kind, "name", a.Name, "error", err)
+			continue
+		}
+		logger.Info("removed "+kind, "name", a.Name, "created", a.Created.Format(time.RFC3339))
+		removed++
+	}
+	return removed
+}
+
+// freePercent returns the percentage of the given total space that is free
+func freePercent(total, free uint64) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(free) / float64(total) * 100
+}
@@ -6,25 +6,27 @@ import (
 
 	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 )
 
-func downloadDeps(cli *docker.Client, images ...string) {
+func downloadDeps(cli *docker.Client, logger *log.Logger, images ...string) {
 	var wait sync.WaitGroup
 	wait.Add(len(images))
 	for _, i := range images {
-		go dockerPull(i, cli, &wait)
+		go dockerPull(i, cli, logger, &wait)
 	}
 	wait.Wait()
 	cli.Close()
 }
 
-func dockerPull(image string, cli *docker.Client, wait *sync.WaitGroup) {
+func dockerPull(image string, cli *docker.Client, logger *log.Logger, wait *sync.WaitGroup) {
 	defer wait.Done()
-	println("Downloading " + image)
+	logger.Info("downloading image", "image", image)
 	_, err := cli.ImagePull(context.Background(), image, types.ImagePullOptions{})
 	if err != nil {
-		println(err.Error())
+		logger.Error("failed to download image", "image", image, "error", err)
 	} else {
-		println(image + " download complete")
+		logger.Info("image download complete", "image", image)
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/blang/semver"
 	"github.com/go-chi/render"
 
+	"github.com/ubclaunchpad/inertia/api"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
 )
@@ -27,7 +28,18 @@ type shieldsIOData struct {
 // statusHandler returns a formatted string about the status of the
 // deployment and lists currently active project containers
 func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
-	status, err := s.deployment.GetStatus(s.docker)
+	// report an empty status if no projects have been deployed yet
+	var (
+		status api.DeploymentStatus
+		err    error
+	)
+	if len(s.deployments.Names()) > 0 {
+		deployment, ok := s.getDeployment(w, r)
+		if !ok {
+			return
+		}
+		status, err = deployment.GetStatus(s.docker)
+	}
 	status.InertiaVersion = s.version
 
 	// badge generator for https://shields.io/endpoint
@@ -24,19 +24,17 @@ func readBadge(body io.Reader) (*shieldsIOData, error) {
 }
 
 func TestStatusHandlerBuildInProgress(t *testing.T) {
-	var s = &Server{
-		deployment: &mocks.FakeDeployer{
-			GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
-				return api.DeploymentStatus{
-					Branch:               "wow",
-					CommitHash:           "abcde",
-					CommitMessage:        "",
-					Containers:           []string{},
-					BuildContainerActive: true,
-				}, nil
-			},
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{
+				Branch:               "wow",
+				CommitHash:           "abcde",
+				CommitMessage:        "",
+				Containers:           []string{},
+				BuildContainerActive: true,
+			}, nil
 		},
-	}
+	})
 
 	// Assmble request
 	req, err := http.NewRequest("GET", "/status", nil)
@@ -62,19 +60,17 @@ func TestStatusHandlerBuildInProgress(t *testing.T) {
 }
 
 func TestStatusHandlerNoContainers(t *testing.T) {
-	var s = &Server{
-		deployment: &mocks.FakeDeployer{
-			GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
-				return api.DeploymentStatus{
-					Branch:               "wow",
-					CommitHash:           "abcde",
-					CommitMessage:        "",
-					Containers:           []string{},
-					BuildContainerActive: false,
-				}, nil
-			},
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{
+				Branch:               "wow",
+				CommitHash:           "abcde",
+				CommitMessage:        "",
+				Containers:           []string{},
+				BuildContainerActive: false,
+			}, nil
 		},
-	}
+	})
 
 	// Assmble request
 	req, err := http.NewRequest("GET", "/status", nil)
@@ -100,19 +96,17 @@ func TestStatusHandlerNoContainers(t *testing.T) {
 }
 
 func TestStatusHandlerActiveContainers(t *testing.T) {
-	var s = &Server{
-		deployment: &mocks.FakeDeployer{
-			GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
-				return api.DeploymentStatus{
-					Branch:               "wow",
-					CommitHash:           "abcde",
-					CommitMessage:        "",
-					Containers:           []string{"mycontainer_1", "yourcontainer_2"},
-					BuildContainerActive: false,
-				}, nil
-			},
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{
+				Branch:               "wow",
+				CommitHash:           "abcde",
+				CommitMessage:        "",
+				Containers:           []string{"mycontainer_1", "yourcontainer_2"},
+				BuildContainerActive: false,
+			}, nil
 		},
-	}
+	})
 
 	// Assmble request
 	req, err := http.NewRequest("GET", "/status", nil)
@@ -140,13 +134,11 @@ func TestStatusHandlerActiveContainers(t *testing.T) {
 }
 
 func TestStatusHandlerStatusError(t *testing.T) {
-	var s = &Server{
-		deployment: &mocks.FakeDeployer{
-			GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
-				return api.DeploymentStatus{CommitHash: "1234"}, errors.New("uh oh")
-			},
+	var s = newTestServer(&mocks.FakeDeployer{
+		GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+			return api.DeploymentStatus{CommitHash: "1234"}, errors.New("uh oh")
 		},
-	}
+	})
 
 	// Assmble request
 	req, err := http.NewRequest("GET", "/status", nil)
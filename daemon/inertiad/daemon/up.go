@@ -1,7 +1,10 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -16,34 +19,27 @@ import (
 
 // upHandler tries to bring the deployment online
 func (s *Server) upHandler(w http.ResponseWriter, r *http.Request) {
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		render.Render(w, r, res.ErrBadRequest(err.Error()))
+	upReq, ok := readUpRequest(w, r)
+	if !ok {
 		return
 	}
-	defer r.Body.Close()
-	var upReq api.UpRequest
-	if err = json.Unmarshal(body, &upReq); err != nil {
-		render.Render(w, r, res.ErrBadRequest(err.Error()))
+	var gitOpts = upReq.GitOptions
+
+	// retrieve project deployment, setting one up if this is a new project
+	deployment, created, err := s.deployments.GetOrCreate(upReq.Project)
+	if err != nil {
+		render.Render(w, r, res.ErrInternalServer("failed to set up deployment", err))
 		return
 	}
-	var gitOpts = upReq.GitOptions
+	if created {
+		s.watch(upReq.Project, deployment)
+	}
 
 	// apply configuration updates
 	if upReq.WebHookSecret != "" {
 		s.state.WebhookSecret = upReq.WebHookSecret
 	}
-	conf := project.DeploymentConfig{
-		ProjectName:            upReq.Project,
-		BuildType:              upReq.BuildType,
-		BuildFilePath:          upReq.BuildFilePath,
-		RemoteURL:              gitOpts.RemoteURL,
-		Branch:                 gitOpts.Branch,
-		PemFilePath:            crypto.DaemonInertiaKeyLocation,
-		IntermediaryContainers: upReq.IntermediaryContainers,
-		SlackNotificationURL:   upReq.SlackNotificationURL,
-	}
-	s.deployment.SetConfig(conf)
+	var conf = deploymentConfig(upReq)
 
 	// Configure streamer
 	var stream = log.NewStreamer(log.StreamerOptions{
@@ -54,49 +50,166 @@ func (s *Server) upHandler(w http.ResponseWriter, r *http.Request) {
 	})
 	defer stream.Close()
 
-	// Check for existing git repository, clone if no git repository exists.
-	var skipUpdate = false
-	if status, _ := s.deployment.GetStatus(s.docker); status.CommitHash == "" {
-		stream.Println("No deployment detected")
-		if err = s.deployment.Initialize(conf, stream); err != nil {
-			stream.Error(res.Err(err.Error(), http.StatusPreconditionFailed))
-			return
+	// Deploy project once other deploys of this project are done - the new
+	// configuration only takes effect if this deploy is not superseded
+	if ok := s.queueDeploy(upReq.Project, project.BuildTriggerUp, deployment, stream, func(
+		ctx context.Context,
+		out io.Writer,
+		buildID string,
+	) *res.ErrResponse {
+		deployment.SetConfig(conf)
+
+		// Check for existing git repository, clone if no git repository exists.
+		var skipUpdate = false
+		if status, _ := deployment.GetStatus(s.docker); status.CommitHash == "" {
+			fmt.Fprintln(out, "No deployment detected")
+			if err := deployment.Initialize(conf, out); err != nil {
+				return res.Err(err.Error(), http.StatusPreconditionFailed)
+			}
+
+			// Project was just pulled! No need to update again.
+			skipUpdate = true
 		}
 
-		// Project was just pulled! No need to update again.
-		skipUpdate = true
-	}
+		// Check for matching remotes
+		if err := deployment.CompareRemotes(gitOpts.RemoteURL); err != nil {
+			return res.Err(err.Error(), http.StatusPreconditionFailed)
+		}
+
+		// Deploy project
+		deploy, err := deployment.Deploy(ctx, s.docker, out, project.DeployOptions{
+			SkipUpdate: skipUpdate,
+			BuildID:    buildID,
+		})
+		if err != nil {
+			return res.ErrInternalServer("failed to build project", err)
+		}
 
-	// Check for matching remotes
-	if err = s.deployment.CompareRemotes(gitOpts.RemoteURL); err != nil {
-		stream.Error(res.Err(err.Error(), http.StatusPreconditionFailed))
+		if err = deploy(); err != nil {
+			return res.ErrInternalServer("failed to deploy project", err)
+		}
+
+		// Wait for the project to become healthy, rolling back if it does not
+		if err = s.checkHealth(ctx, deployment, out); err != nil {
+			return res.ErrInternalServer("project failed health check", err)
+		}
+
+		// Update container management history following a successful build and deployment
+		if err = deployment.UpdateContainerHistory(s.docker); err != nil {
+			fmt.Fprintln(out, "warning: failed to update container history:", err)
+		}
+		return nil
+	}); !ok {
 		return
 	}
 
-	// Change deployment parameters if necessary
-	s.deployment.SetConfig(project.DeploymentConfig{
-		ProjectName: upReq.Project,
-		Branch:      gitOpts.Branch,
-	})
+	stream.Success(res.Msg("Project startup initiated!", http.StatusCreated))
+}
 
-	// Deploy project
-	deploy, err := s.deployment.Deploy(s.docker, stream, project.DeployOptions{
-		SkipUpdate: skipUpdate,
-	})
+// readUpRequest parses and validates the up request in the given request
+// body, and renders an error response if it is invalid
+func readUpRequest(w http.ResponseWriter, r *http.Request) (api.UpRequest, bool) {
+	var upReq api.UpRequest
+	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		stream.Error(res.ErrInternalServer("failed to build project", err))
-		return
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
 	}
-
-	if err = deploy(); err != nil {
-		stream.Error(res.ErrInternalServer("failed to deploy project", err))
-		return
+	defer r.Body.Close()
+	if err = json.Unmarshal(body, &upReq); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
 	}
-
-	// Update container management history following a successful build and deployment
-	if err = s.deployment.UpdateContainerHistory(s.docker); err != nil {
-		stream.Println("warning: failed to update container history:", err)
+	if err = project.ValidateProjectName(upReq.Project); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateHealthCheck(upReq.HealthCheck); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateRestartPolicy(upReq.RestartPolicy); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateHooks(upReq.Hooks); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateImage(upReq.BuildType, upReq.Image); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidatePorts(upReq.Ports); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateDeployStrategy(upReq.DeployStrategy, upReq.BuildType, upReq.Ports); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateResources(upReq.Resources); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateCompose(upReq.Compose); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateGitOptions(upReq.GitOptions); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateBuildContext(upReq.BuildContext); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateWatchPaths(upReq.WatchPaths); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidateTriggers(upReq.Triggers); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
+	}
+	if err = project.ValidatePreviews(upReq.Previews); err != nil {
+		render.Render(w, r, res.ErrBadRequest(err.Error()))
+		return upReq, false
 	}
+	return upReq, true
+}
 
-	stream.Success(res.Msg("Project startup initiated!", http.StatusCreated))
+// deploymentConfig converts the given up request to deployment configuration
+func deploymentConfig(upReq api.UpRequest) project.DeploymentConfig {
+	return project.DeploymentConfig{
+		ProjectName:            upReq.Project,
+		BuildType:              upReq.BuildType,
+		BuildFilePath:          upReq.BuildFilePath,
+		BuildContext:           upReq.BuildContext,
+		WatchPaths:             upReq.WatchPaths,
+		Triggers:               upReq.Triggers,
+		Image:                  upReq.Image,
+		BuildArgs:              upReq.BuildArgs,
+		BuildArgsFromEnv:       upReq.BuildArgsFromEnv,
+		Target:                 upReq.Target,
+		Ports:                  upReq.Ports,
+		Labels:                 upReq.Labels,
+		Resources:              upReq.Resources,
+		Compose:                upReq.Compose,
+		DeployStrategy:         upReq.DeployStrategy,
+		HealthCheck:            upReq.HealthCheck,
+		RestartPolicy:          upReq.RestartPolicy,
+		Hooks:                  upReq.Hooks,
+		RemoteURL:              upReq.GitOptions.RemoteURL,
+		Branch:                 upReq.GitOptions.Branch,
+		Ref:                    upReq.GitOptions.Ref,
+		Submodules:             upReq.GitOptions.Submodules,
+		LFS:                    upReq.GitOptions.LFS,
+		Depth:                  upReq.GitOptions.Depth,
+		PruneTo:                upReq.GitOptions.PruneTo,
+		PemFilePath:            crypto.DaemonInertiaKeyLocation,
+		IntermediaryContainers: upReq.IntermediaryContainers,
+		Previews:               upReq.Previews,
+		SlackNotificationURL:   upReq.SlackNotificationURL,
+	}
 }
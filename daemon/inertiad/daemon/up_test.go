@@ -0,0 +1,35 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+)
+
+func TestUpHandlerInvalidRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"invalid project name", `{"project":"../wow"}`, "invalid project name"},
+		{"invalid health check", `{"project":"wow","health_check":{"type":"vibes"}}`, "unknown health check type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{}
+			var s = newTestServer(fake)
+			req, err := http.NewRequest("POST", "/up", strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(s.upHandler).ServeHTTP(recorder, req)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.wantErr)
+			assert.Equal(t, 0, fake.DeployCallCount())
+		})
+	}
+}
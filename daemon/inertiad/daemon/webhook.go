@@ -1,14 +1,17 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/go-chi/render"
 	"github.com/ubclaunchpad/inertia/api"
 	"github.com/ubclaunchpad/inertia/common"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/res"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/webhook"
@@ -16,13 +19,15 @@ import (
 
 // webhookHandler receives and parses Git-based webhooks
 // Supported vendors: Github, Gitlab, Bitbucket
-// Supported events: push
+// Supported events: push, pull request
 func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
+	var logger = s.logger.WithRequest(r).With("component", "webhook")
+
 	// read
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		msg := "unable to read payload: " + err.Error()
-		println(msg)
+		logger.Warn(msg)
 		render.Render(w, r, res.ErrBadRequest(msg))
 		return
 	}
@@ -32,11 +37,11 @@ func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
 
 	// ensure validity
 	if s.state.WebhookSecret == "" {
-		println("warning: no webhook secret is set up yet! set one in inertia.toml and run inertia [remote] up")
+		logger.Warn("no webhook secret is set up yet - set one in inertia.toml and run inertia [remote] up")
 	}
 	if err := webhook.Verify(host, s.state.WebhookSecret, r.Header, body); err != nil {
 		msg := "unable to verify payload: " + err.Error()
-		println(msg)
+		logger.Warn(msg, "host", host)
 		render.Render(w, r, res.ErrBadRequest(msg))
 		return
 	}
@@ -45,7 +50,7 @@ func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	payload, err := webhook.Parse(host, event, r.Header, body)
 	if err != nil {
 		msg := "unable to parse payload: " + err.Error()
-		println(msg)
+		logger.Warn(msg, "host", host, "event", event)
 		render.Render(w, r, res.ErrBadRequest(msg))
 		return
 	}
@@ -53,69 +58,293 @@ func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	// process event
 	switch event := payload.GetEventType(); event {
 	case webhook.PingEvent:
-		println("ping webhook received")
+		logger.Info("ping webhook received", "host", host)
 		render.Render(w, r, res.Msg(api.MsgDaemonOK, http.StatusAccepted))
 		return
 	case webhook.PushEvent:
 		render.Render(w, r, res.Msg(api.MsgDaemonOK, http.StatusAccepted))
-		processPushEvent(s, payload)
-	// case webhook.PullEvent:
-	//	fmt.Fprint(w, common.MsgDaemonOK)
-	// 	processPullRequestEvent(payload)
+		processPushEvent(s, logger, payload)
+	case webhook.PullEvent:
+		render.Render(w, r, res.Msg(api.MsgDaemonOK, http.StatusAccepted))
+		processPullRequestEvent(s, logger, payload)
 	default:
-		println("unrecognized event type")
+		logger.Warn("unrecognized event type", "host", host, "event", event)
 		render.Render(w, r, res.ErrBadRequest("unrecognized event type",
 			"type", event))
 	}
 }
 
-// specialized handler for docker webhooks
-func dockerWebhookHandler(w http.ResponseWriter, r *http.Request) {
-	p, err := webhook.ParseDocker(r)
+// registryWebhookHandler receives Docker registry webhooks, which must provide
+// the webhook secret as a query parameter since they are not signed
+// Supported vendors: Docker Hub
+// Supported events: push
+func (s *Server) registryWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var logger = s.logger.WithRequest(r).With("component", "webhook")
+	if err := webhook.VerifyDocker(s.state.WebhookSecret, r); err != nil {
+		msg := "unable to verify payload: " + err.Error()
+		logger.Warn(msg)
+		render.Render(w, r, res.ErrBadRequest(msg))
+		return
+	}
+
+	payload, err := webhook.ParseDocker(r)
 	if err != nil {
-		fmt.Fprintln(os.Stdout, err.Error())
+		msg := "unable to parse payload: " + err.Error()
+		logger.Warn(msg)
+		render.Render(w, r, res.ErrBadRequest(msg))
 		return
 	}
 
-	fmt.Printf("Received dockerhub webhook event: %s:%s\n", p.GetRepoName(), p.GetTag())
+	render.Render(w, r, res.Msg(api.MsgDaemonOK, http.StatusAccepted))
+	processImagePushEvent(s, logger, payload)
 }
 
-// processPushEvent prints information about the given PushEvent.
-func processPushEvent(s *Server, p webhook.Payload) {
-	fmt.Printf("Received %s push event: %s (%s)\n",
-		p.GetSource(), p.GetRepoName(), p.GetRef())
+// processPushEvent logs information about the given PushEvent and deploys
+// all projects whose repository and branch match the event.
+func processPushEvent(s *Server, logger *log.Logger, p webhook.Payload) {
+	logger = logger.With("event", "push", "repository", p.GetRepoName())
+	logger.Info("received event", "source", p.GetSource(), "ref", p.GetRef())
 
-	// Ignore event if repository not set up yet, otherwise
-	// let deploy() handle the update.
-	if status, _ := s.deployment.GetStatus(s.docker); status.CommitHash == "" {
-		fmt.Println(msgNoDeployment)
+	// Deleted refs may still exist locally, so they must not be deployed
+	if p.IsDeleted() {
+		logger.Info("ignoring event", "reason", "ref was deleted")
 		return
 	}
 
-	// Check for matching remotes
-	if err := s.deployment.CompareRemotes(p.GetSSHURL()); err != nil {
-		fmt.Println(err.Error())
-		return
+	var matched bool
+	s.deployments.ForEach(func(name string, deployment project.Deployer) {
+		// Previews are only updated by pull request events
+		if deployment.IsPreview() {
+			return
+		}
+
+		// Ignore deployments whose repository is not set up yet, otherwise
+		// let deploy() handle the update.
+		status, _ := deployment.GetStatus(s.docker)
+		if status.CommitHash == "" {
+			return
+		}
+
+		// Check for matching remotes
+		if err := deployment.CompareRemotes(p.GetSSHURL()); err != nil {
+			return
+		}
+		matched = true
+		var logger = logger.With("project", name)
+
+		// Pinned deployments are only updated by an explicit 'up'
+		if status.Pinned {
+			logger.Info("ignoring event",
+				"reason", "deployment is pinned to ref "+status.Ref)
+			return
+		}
+
+		// Check the pushed ref against the deployment's triggers - tags and
+		// branches other than the deployed branch are deployed without
+		// changing the deployed branch
+		var (
+			triggers = deployment.GetTriggers()
+			refName  = common.GetBranchFromRef(p.GetRef())
+			opts     project.DeployOptions
+			reason   string
+		)
+		switch p.GetRefType() {
+		case webhook.TagRef:
+			if !project.MatchesTagTriggers(triggers, refName) {
+				logger.Info("ignoring event",
+					"reason", fmt.Sprintf("tag %s does not match tag triggers", refName))
+				return
+			}
+			opts.Ref = p.GetRef()
+			reason = fmt.Sprintf("tag %s matches tag triggers", refName)
+		default:
+			if !project.MatchesBranchTriggers(triggers, deployment.GetBranch(), refName) {
+				if triggers != nil && len(triggers.Branches) > 0 {
+					logger.Info("ignoring event",
+						"reason", fmt.Sprintf("event branch %s does not match branch triggers %s",
+							refName, strings.Join(triggers.Branches, ", ")))
+				} else {
+					logger.Info("ignoring event",
+						"reason", fmt.Sprintf("event branch %s does not match deployed branch %s",
+							refName, deployment.GetBranch()))
+				}
+				return
+			}
+			if refName != deployment.GetBranch() {
+				opts.Ref = p.GetRef()
+				reason = fmt.Sprintf("event branch %s matches branch triggers", refName)
+			} else {
+				reason = fmt.Sprintf("event branch %s matches deployed branch %s",
+					refName, deployment.GetBranch())
+			}
+		}
+
+		// Check for changes to watched paths, if the changes are known. If the
+		// payload doesn't list them, work them out from the pushed commits
+		// instead - if that fails, the push is deployed.
+		if watch := deployment.GetWatchPaths(); len(watch) > 0 {
+			files, ok := p.GetChangedFiles()
+			if from, to, known := p.GetCommitRange(); !ok && known {
+				var err error
+				if files, err = deployment.GetChangedFiles(from, to); err != nil {
+					logger.Warn("failed to determine changed files", "error", err)
+				} else {
+					ok = true
+				}
+			}
+			if ok && !project.MatchesWatchPaths(watch, files) {
+				logger.Info("ignoring event",
+					"reason", "no changes to watched paths "+strings.Join(watch, ", "))
+				return
+			}
+		}
+
+		// Check for directives to skip the deploy
+		if directive, skip := project.GetSkipDirective(triggers, p.GetCommitMessage()); skip {
+			logger.Info("ignoring event", "reason", "commit message contains "+directive)
+			return
+		}
+
+		logger.Info("accepting event", "reason", reason)
+		deployFromWebhook(s, logger, name, deployment, opts)
+	})
+	if !matched {
+		logger.Info("ignoring event", "reason", "no deployed project matches repository")
 	}
+}
 
-	// Check for matching branch
-	var branch = common.GetBranchFromRef(p.GetRef())
-	if s.deployment.GetBranch() != branch {
-		fmt.Printf("Ignoring event: event branch %s does not match deployed branch %s\n",
-			branch, s.deployment.GetBranch())
+// processPullRequestEvent logs information about the given pull request
+// event, and deploys or removes previews of the pull request for all projects
+// whose repository and branch match the pull request's.
+func processPullRequestEvent(s *Server, logger *log.Logger, p webhook.Payload) {
+	pr, ok := p.GetPullRequest()
+	if !ok {
 		return
 	}
+	logger = logger.With("event", "pull_request", "repository", p.GetRepoName(),
+		"pull_request", pr.Number)
+	logger.Info("received event", "source", p.GetSource(), "action", pr.Action)
 
-	// If branches match, deploy
-	fmt.Printf("Accepting event: event branch %s matches deployed branch %s\n",
-		branch, s.deployment.GetBranch())
-	deploy, err := s.deployment.Deploy(s.docker, os.Stdout, project.DeployOptions{})
-	if err != nil {
-		fmt.Println("Build failed: " + err.Error())
+	// Previews are deployed with the project's secrets, so code from other
+	// repositories is never deployed
+	if pr.FromFork {
+		logger.Info("ignoring event",
+			"reason", "previews are not deployed for pull requests from forks")
 		return
 	}
 
-	if err = deploy(); err != nil {
-		fmt.Println("Deploy failed: " + err.Error())
+	var matched bool
+	s.deployments.ForEach(func(name string, deployment project.Deployer) {
+		if deployment.IsPreview() {
+			return
+		}
+
+		// Ignore deployments whose repository is not set up yet
+		status, _ := deployment.GetStatus(s.docker)
+		if status.CommitHash == "" {
+			return
+		}
+
+		// Check for matching remotes
+		if err := deployment.CompareRemotes(p.GetSSHURL()); err != nil {
+			return
+		}
+		matched = true
+		var logger = logger.With("project", name)
+
+		// Check for matching branch
+		if deployment.GetBranch() != pr.BaseBranch {
+			logger.Info("ignoring event",
+				"reason", fmt.Sprintf("pull request branch %s does not match deployed branch %s",
+					pr.BaseBranch, deployment.GetBranch()))
+			return
+		}
+
+		switch pr.Action {
+		case webhook.PullRequestOpened, webhook.PullRequestSynchronized:
+			deployPreview(s, logger, name, deployment, pr)
+		case webhook.PullRequestClosed:
+			removePreview(s, logger, project.PreviewName(name, pr.Number), pr)
+		default:
+			logger.Info("ignoring event",
+				"reason", "unsupported pull request action "+pr.Action)
+		}
+	})
+	if !matched {
+		logger.Info("ignoring event", "reason", "no deployed project matches repository")
+	}
+}
+
+// processImagePushEvent logs information about the given Docker push event
+// and deploys all image projects whose image matches the event.
+func processImagePushEvent(s *Server, logger *log.Logger, p *webhook.DockerWebhook) {
+	logger = logger.With("event", "image_push", "image", p.GetRepoName()+":"+p.GetTag())
+	logger.Info("received event")
+
+	var matched bool
+	s.deployments.ForEach(func(name string, deployment project.Deployer) {
+		var image = deployment.GetImage()
+		if image == "" || !p.MatchesImage(image) {
+			return
+		}
+
+		// Ignore deployments that are not set up yet
+		status, _ := deployment.GetStatus(s.docker)
+		if status.CommitHash == "" {
+			return
+		}
+		matched = true
+		var logger = logger.With("project", name)
+
+		// Pinned deployments are only updated by an explicit 'up'
+		if status.Pinned {
+			logger.Info("ignoring event",
+				"reason", "deployment is pinned to ref "+status.Ref)
+			return
+		}
+
+		logger.Info("accepting event",
+			"reason", "pushed tag matches deployed image "+image)
+		deployFromWebhook(s, logger, name, deployment, project.DeployOptions{})
+	})
+	if !matched {
+		logger.Info("ignoring event", "reason", "no deployed project matches image")
+	}
+}
+
+// deployFromWebhook deploys the named project with the given options once
+// other deploys of it are done, logging the outcome
+func deployFromWebhook(
+	s *Server,
+	logger *log.Logger,
+	name string,
+	deployment project.Deployer,
+	opts project.DeployOptions,
+) {
+	err := s.deployments.Queue(name).Submit(func(ctx context.Context) error {
+		return s.recordBuild(name, project.BuildTriggerWebhook, deployment, ioutil.Discard, func(
+			out io.Writer,
+			buildID string,
+		) error {
+			opts.BuildID = buildID
+			deploy, err := deployment.Deploy(ctx, s.docker, out, opts)
+			if err != nil {
+				return fmt.Errorf("build failed: %s", err.Error())
+			}
+
+			if err = deploy(); err != nil {
+				return fmt.Errorf("deploy failed: %s", err.Error())
+			}
+			if err = s.checkHealth(ctx, deployment, out); err != nil {
+				return fmt.Errorf("health check failed: %s", err.Error())
+			}
+			if err = deployment.UpdateContainerHistory(s.docker); err != nil {
+				fmt.Fprintf(out, "[%s] Failed to update container history: %s\n", name, err.Error())
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logger.Error("webhook event not deployed", "error", err)
 	}
 }
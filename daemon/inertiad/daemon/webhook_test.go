@@ -2,14 +2,25 @@ package daemon
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	docker "github.com/docker/docker/client"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/cfg"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/project/mocks"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/webhook"
 )
 
 const (
@@ -86,3 +97,428 @@ func getTestWebhookEvent(headers map[string]string) *http.Request {
 	}
 	return req
 }
+
+// fakePushEvent implements webhook.Payload
+type fakePushEvent struct {
+	ref     string
+	sshURL  string
+	files   []string
+	from    string
+	to      string
+	deleted bool
+	refType webhook.RefType
+	message string
+}
+
+func (f fakePushEvent) GetSource() string                 { return "test" }
+func (f fakePushEvent) GetEventType() webhook.EventType   { return webhook.PushEvent }
+func (f fakePushEvent) GetRepoName() string               { return "inertia" }
+func (f fakePushEvent) GetRef() string                    { return f.ref }
+func (f fakePushEvent) GetRefType() webhook.RefType       { return f.refType }
+func (f fakePushEvent) GetGitURL() string                 { return "" }
+func (f fakePushEvent) GetSSHURL() string                 { return f.sshURL }
+func (f fakePushEvent) GetChangedFiles() ([]string, bool) { return f.files, f.files != nil }
+func (f fakePushEvent) GetCommitMessage() string          { return f.message }
+func (f fakePushEvent) IsDeleted() bool                   { return f.deleted }
+func (f fakePushEvent) GetCommitRange() (string, string, bool) {
+	return f.from, f.to, f.from != "" && f.to != ""
+}
+func (f fakePushEvent) GetPullRequest() (webhook.PullRequest, bool) {
+	return webhook.PullRequest{}, false
+}
+
+func Test_processPushEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		ref        string
+		status     api.DeploymentStatus
+		watch      []string
+		files      []string
+		wantDeploy bool
+	}{
+		{"matching branch", "refs/heads/master",
+			api.DeploymentStatus{CommitHash: "abcde"}, nil, nil, true},
+		{"other branch", "refs/heads/dev",
+			api.DeploymentStatus{CommitHash: "abcde"}, nil, nil, false},
+		{"no repository", "refs/heads/master",
+			api.DeploymentStatus{}, nil, nil, false},
+		{"pinned deployment", "refs/heads/master",
+			api.DeploymentStatus{CommitHash: "abcde", Pinned: true, Ref: "v1.0.0"}, nil, nil, false},
+		{"changes to watched paths", "refs/heads/master",
+			api.DeploymentStatus{CommitHash: "abcde"}, []string{"services/api"},
+			[]string{"docs/index.md", "services/api/main.go"}, true},
+		{"no changes to watched paths", "refs/heads/master",
+			api.DeploymentStatus{CommitHash: "abcde"}, []string{"services/api"},
+			[]string{"docs/index.md"}, false},
+		{"unknown changes", "refs/heads/master",
+			api.DeploymentStatus{CommitHash: "abcde"}, []string{"services/api"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					return tt.status, nil
+				},
+				GetBranchStub:     func() string { return "master" },
+				GetWatchPathsStub: func() []string { return tt.watch },
+				DeployStub: func(context.Context, *docker.Client, io.Writer, project.DeployOptions) (func() error, error) {
+					return func() error { return nil }, nil
+				},
+			}
+			var s = newTestServer(fake)
+			processPushEvent(s, nil, fakePushEvent{
+				ref:     tt.ref,
+				sshURL:  "git@github.com:ubclaunchpad/inertia.git",
+				files:   tt.files,
+				refType: webhook.BranchRef,
+			})
+			assert.Equal(t, tt.wantDeploy, fake.DeployCallCount() == 1)
+		})
+	}
+}
+
+func Test_processPushEvent_changedFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		changes    []string
+		changesErr error
+		wantCheck  bool
+		wantDeploy bool
+	}{
+		{"changes to watched paths", "12345",
+			[]string{"docs/index.md", "services/api/main.go"}, nil, true, true},
+		{"no changes to watched paths", "12345",
+			[]string{"docs/index.md"}, nil, true, false},
+		{"failed to determine changes", "12345",
+			nil, errors.New("object not found"), true, true},
+		{"unknown commit range", "",
+			nil, nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					return api.DeploymentStatus{CommitHash: "abcde"}, nil
+				},
+				GetBranchStub:     func() string { return "master" },
+				GetWatchPathsStub: func() []string { return []string{"services/api"} },
+				GetChangedFilesStub: func(from, to string) ([]string, error) {
+					return tt.changes, tt.changesErr
+				},
+				DeployStub: func(context.Context, *docker.Client, io.Writer, project.DeployOptions) (func() error, error) {
+					return func() error { return nil }, nil
+				},
+			}
+			var s = newTestServer(fake)
+			processPushEvent(s, nil, fakePushEvent{
+				ref:     "refs/heads/master",
+				sshURL:  "git@github.com:ubclaunchpad/inertia.git",
+				from:    tt.from,
+				to:      "abcde",
+				refType: webhook.BranchRef,
+			})
+			assert.Equal(t, tt.wantCheck, fake.GetChangedFilesCallCount() == 1)
+			if tt.wantCheck {
+				from, to := fake.GetChangedFilesArgsForCall(0)
+				assert.Equal(t, tt.from, from)
+				assert.Equal(t, "abcde", to)
+			}
+			assert.Equal(t, tt.wantDeploy, fake.DeployCallCount() == 1)
+		})
+	}
+}
+
+func Test_processPushEvent_deleted(t *testing.T) {
+	var triggers = &api.Triggers{
+		Branches: []string{"master", "feature/*"},
+		Tags:     []string{"v*.*.*"},
+	}
+	for _, ref := range []string{"refs/heads/master", "refs/heads/feature/wow", "refs/tags/v1.2.3"} {
+		t.Run(ref, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					return api.DeploymentStatus{CommitHash: "abcde"}, nil
+				},
+				GetBranchStub:   func() string { return "master" },
+				GetTriggersStub: func() *api.Triggers { return triggers },
+			}
+			var s = newTestServer(fake)
+			processPushEvent(s, nil, fakePushEvent{
+				ref:     ref,
+				sshURL:  "git@github.com:ubclaunchpad/inertia.git",
+				refType: webhook.BranchRef,
+				deleted: true,
+			})
+			assert.Equal(t, 0, fake.DeployCallCount())
+		})
+	}
+}
+
+func Test_processPushEvent_triggers(t *testing.T) {
+	var triggers = &api.Triggers{
+		Branches: []string{"master", "release/*"},
+		Tags:     []string{"v*.*.*"},
+	}
+	tests := []struct {
+		name       string
+		ref        string
+		refType    webhook.RefType
+		message    string
+		triggers   *api.Triggers
+		wantDeploy bool
+		wantRef    string
+	}{
+		{"deployed branch", "refs/heads/master", webhook.BranchRef, "",
+			triggers, true, ""},
+		{"matching branch", "refs/heads/release/1.2", webhook.BranchRef, "",
+			triggers, true, "refs/heads/release/1.2"},
+		{"other branch", "refs/heads/dev", webhook.BranchRef, "",
+			triggers, false, ""},
+		{"matching tag", "refs/tags/v1.2.3", webhook.TagRef, "",
+			triggers, true, "refs/tags/v1.2.3"},
+		{"other tag", "refs/tags/nightly", webhook.TagRef, "",
+			triggers, false, ""},
+		{"tag without triggers", "refs/tags/v1.2.3", webhook.TagRef, "",
+			nil, false, ""},
+		{"default skip directive", "refs/heads/master", webhook.BranchRef, "fix typo [skip deploy]",
+			nil, false, ""},
+		{"custom skip directive", "refs/heads/master", webhook.BranchRef, "fix typo [no ci]",
+			&api.Triggers{Skip: []string{"[no ci]"}}, false, ""},
+		{"custom skip directive replaces default", "refs/heads/master", webhook.BranchRef, "fix typo [skip deploy]",
+			&api.Triggers{Skip: []string{"[no ci]"}}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					return api.DeploymentStatus{CommitHash: "abcde"}, nil
+				},
+				GetBranchStub:   func() string { return "master" },
+				GetTriggersStub: func() *api.Triggers { return tt.triggers },
+				DeployStub: func(context.Context, *docker.Client, io.Writer, project.DeployOptions) (func() error, error) {
+					return func() error { return nil }, nil
+				},
+			}
+			var s = newTestServer(fake)
+			processPushEvent(s, nil, fakePushEvent{
+				ref:     tt.ref,
+				sshURL:  "git@github.com:ubclaunchpad/inertia.git",
+				refType: tt.refType,
+				message: tt.message,
+			})
+			assert.Equal(t, tt.wantDeploy, fake.DeployCallCount() == 1)
+			if tt.wantDeploy {
+				_, _, _, opts := fake.DeployArgsForCall(0)
+				assert.Equal(t, tt.wantRef, opts.Ref)
+			}
+		})
+	}
+}
+
+// fakePullEvent implements webhook.Payload
+type fakePullEvent struct {
+	fakePushEvent
+	pr webhook.PullRequest
+}
+
+func (f fakePullEvent) GetEventType() webhook.EventType { return webhook.PullEvent }
+func (f fakePullEvent) GetPullRequest() (webhook.PullRequest, bool) {
+	return f.pr, true
+}
+
+func Test_processPullRequestEvent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-previews")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name       string
+		pr         webhook.PullRequest
+		previewErr error
+		existing   bool
+		wantDeploy bool
+		wantRemove bool
+	}{
+		{"opened", webhook.PullRequest{Number: 1, Action: webhook.PullRequestOpened,
+			HeadBranch: "feature", BaseBranch: "master"}, nil, false, true, false},
+		{"synchronized", webhook.PullRequest{Number: 1, Action: webhook.PullRequestSynchronized,
+			HeadBranch: "feature", BaseBranch: "master"}, nil, true, true, false},
+		{"already deployed", webhook.PullRequest{Number: 1, Action: webhook.PullRequestSynchronized,
+			HeadBranch: "feature", HeadCommit: "abc", BaseBranch: "master"}, nil, true, false, false},
+		{"closed", webhook.PullRequest{Number: 1, Action: webhook.PullRequestClosed,
+			HeadBranch: "feature", BaseBranch: "master"}, nil, true, false, true},
+		{"closed without preview", webhook.PullRequest{Number: 1, Action: webhook.PullRequestClosed,
+			HeadBranch: "feature", BaseBranch: "master"}, nil, false, false, false},
+		{"other branch", webhook.PullRequest{Number: 1, Action: webhook.PullRequestOpened,
+			HeadBranch: "feature", BaseBranch: "dev"}, nil, false, false, false},
+		{"fork", webhook.PullRequest{Number: 1, Action: webhook.PullRequestOpened,
+			HeadBranch: "feature", BaseBranch: "master", FromFork: true}, nil, false, false, false},
+		{"previews not enabled", webhook.PullRequest{Number: 1, Action: webhook.PullRequestOpened,
+			HeadBranch: "feature", BaseBranch: "master"}, errors.New("previews are not enabled"), false, false, false},
+		{"edited", webhook.PullRequest{Number: 1, Action: "edited",
+			HeadBranch: "feature", BaseBranch: "master"}, nil, true, false, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var newManager = func(name string) *project.DeploymentDataManager {
+				m, err := project.NewDataManager(
+					filepath.Join(dir, fmt.Sprintf("%s-%d.db", name, i)), filepath.Join(dir, "key"))
+				assert.NoError(t, err)
+				return m
+			}
+			var base = &mocks.FakeDeployer{
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					return api.DeploymentStatus{CommitHash: "abcde"}, nil
+				},
+				GetBranchStub: func() string { return "master" },
+				GetPreviewConfigStub: func(pr project.PullRequest, host string) (project.DeploymentConfig, error) {
+					assert.Equal(t, "feature", pr.Branch)
+					return project.DeploymentConfig{
+						ProjectName: project.PreviewName("test", pr.Number),
+						PreviewOf:   "test",
+					}, tt.previewErr
+				},
+			}
+			base.GetDataManagerReturns(newManager("test"), true)
+			var preview = &mocks.FakeDeployer{
+				IsPreviewStub: func() bool { return true },
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					if !tt.existing {
+						return api.DeploymentStatus{}, nil
+					}
+					return api.DeploymentStatus{CommitHash: "abcde"}, nil
+				},
+				DeployStub: func(context.Context, *docker.Client, io.Writer, project.DeployOptions) (func() error, error) {
+					return func() error { return nil }, nil
+				},
+			}
+			preview.GetDataManagerReturns(newManager("test-pr-1"), true)
+			var watching context.Context
+			preview.WatchStub = func(ctx context.Context, _ *docker.Client) (<-chan string, <-chan error) {
+				watching = ctx
+				var errCh = make(chan error)
+				go func() {
+					<-ctx.Done()
+					close(errCh)
+				}()
+				return nil, errCh
+			}
+
+			var s = &Server{
+				deployments: project.NewRegistry(func(name string) (project.Deployer, error) {
+					if name == "test" {
+						return base, nil
+					}
+					return preview, nil
+				}),
+			}
+			s.deployments.GetOrCreate("test")
+			if tt.existing {
+				s.deployments.GetOrCreate("test-pr-1")
+				s.watch("test-pr-1", preview)
+			}
+
+			processPullRequestEvent(s, nil, fakePullEvent{
+				fakePushEvent{
+					ref:     "refs/heads/feature",
+					sshURL:  "git@github.com:ubclaunchpad/inertia.git",
+					refType: webhook.BranchRef,
+				},
+				tt.pr,
+			})
+			assert.Equal(t, tt.wantDeploy, preview.DeployCallCount() == 1)
+			assert.Equal(t, tt.wantDeploy && !tt.existing, preview.InitializeCallCount() == 1)
+			assert.Equal(t, tt.wantRemove, preview.DestroyCallCount() == 1)
+			_, found := s.deployments.Get("test-pr-1")
+			assert.Equal(t, (tt.existing || tt.wantDeploy) && !tt.wantRemove, found)
+
+			// Removed previews should no longer be watched
+			if watching != nil {
+				assert.Equal(t, tt.wantRemove, watching.Err() != nil)
+			}
+			if tt.wantDeploy {
+				assert.Equal(t, "test-pr-1", preview.SetConfigArgsForCall(0).ProjectName)
+			}
+		})
+	}
+}
+
+func getTestRegistryWebhookEvent(secret, repo, tag string) *http.Request {
+	buf := bytes.NewBufferString(`{"push_data":{"pusher":"bob","tag":"` + tag + `"},` +
+		`"repository":{"repo_name":"` + repo + `"}}`)
+	req, err := http.NewRequest("POST", "http://127.0.0.1/webhook/registry?secret="+secret, buf)
+	if err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
+	req.Header.Set("content-type", "application/json")
+	return req
+}
+
+func Test_registryWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		tag      string
+		wantCode int
+		wantErr  string
+	}{
+		{"okay", testKey, "latest", http.StatusAccepted, ""},
+		{"invalid secret", "wrong", "latest", http.StatusBadRequest, "invalid webhook secret"},
+		{"invalid payload", testKey, "", http.StatusBadRequest, "unable to parse payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s = newTestServer(&mocks.FakeDeployer{})
+			s.state.WebhookSecret = testKey
+			recorder := httptest.NewRecorder()
+			handler := http.HandlerFunc(s.registryWebhookHandler)
+
+			handler.ServeHTTP(recorder, getTestRegistryWebhookEvent(tt.secret, "ubclaunchpad/inertia", tt.tag))
+			assert.Equal(t, tt.wantCode, recorder.Code)
+
+			b, err := ioutil.ReadAll(recorder.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(b), tt.wantErr)
+		})
+	}
+}
+
+func Test_processImagePushEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		image      string
+		status     api.DeploymentStatus
+		wantDeploy bool
+	}{
+		{"matching image", "ubclaunchpad/inertia:latest",
+			api.DeploymentStatus{CommitHash: "abcde"}, true},
+		{"other tag", "ubclaunchpad/inertia:v1.0.0",
+			api.DeploymentStatus{CommitHash: "abcde"}, false},
+		{"not an image project", "",
+			api.DeploymentStatus{CommitHash: "abcde"}, false},
+		{"no repository", "ubclaunchpad/inertia:latest",
+			api.DeploymentStatus{}, false},
+		{"pinned deployment", "ubclaunchpad/inertia:latest",
+			api.DeploymentStatus{CommitHash: "abcde", Pinned: true, Ref: "v1.0.0"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake = &mocks.FakeDeployer{
+				GetStatusStub: func(*docker.Client) (api.DeploymentStatus, error) {
+					return tt.status, nil
+				},
+				GetImageStub: func() string { return tt.image },
+				DeployStub: func(context.Context, *docker.Client, io.Writer, project.DeployOptions) (func() error, error) {
+					return func() error { return nil }, nil
+				},
+			}
+			var s = newTestServer(fake)
+			payload, err := webhook.ParseDocker(getTestRegistryWebhookEvent(testKey, "ubclaunchpad/inertia", "latest"))
+			assert.NoError(t, err)
+			processImagePushEvent(s, nil, payload)
+			assert.Equal(t, tt.wantDeploy, fake.DeployCallCount() == 1)
+		})
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
+	"path"
 	"strings"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
 )
 
 var (
@@ -34,6 +39,22 @@ type RepoOptions struct {
 	Directory string
 	Branch    string
 	Auth      transport.AuthMethod
+
+	// Ref, if set, is checked out instead of the tip of Branch. It may be a
+	// commit hash, tag, or branch name.
+	Ref string
+
+	// Submodules enables recursively cloning and updating submodules, and LFS
+	// enables fetching Git LFS objects for the checked out commit
+	Submodules bool
+	LFS        bool
+
+	// Depth, if set, limits fetched history to the given number of commits from
+	// the tip of each branch and tag
+	Depth int
+
+	// PruneTo, if set, prunes the worktree to the given paths after checkout
+	PruneTo PrunePaths
 }
 
 // InitializeRepository sets up a project repository for the first time
@@ -49,12 +70,22 @@ func InitializeRepository(remoteURL string, opts RepoOptions, w io.Writer) (*gog
 	return repo, nil
 }
 
+// OpenRepository opens a project repository previously set up by
+// InitializeRepository in the given directory
+func OpenRepository(directory string) (*gogit.Repository, error) {
+	repo, err := plainOpen(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %s", err.Error())
+	}
+	return repo, nil
+}
+
 // clone wraps gogit.PlainClone() and returns a more helpful error message
 // if the given error is an authentication-related error.
 func clone(remoteURL string, opts RepoOptions, out io.Writer) (*gogit.Repository, error) {
 	// Preserve existing files by creating a repository, setting a remote, then
 	// updating the directory
-	repo, err := gogit.PlainInit(opts.Directory, false)
+	repo, err := plainInit(opts.Directory)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init bare repository: %s", err.Error())
 	}
@@ -81,6 +112,24 @@ func clone(remoteURL string, opts RepoOptions, out io.Writer) (*gogit.Repository
 	return repo, nil
 }
 
+// FetchRemote fetches the latest branches and tags from origin without
+// modifying local branches or the working tree. Remote branches are available
+// as refs/remotes/origin/<branch> once fetched.
+func FetchRemote(repo *gogit.Repository, auth transport.AuthMethod, out io.Writer) error {
+	fmt.Fprintln(out, "Fetching repository...")
+	err := repo.Fetch(&gogit.FetchOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+		RefSpecs: []config.RefSpec{
+			"+refs/heads/*:refs/remotes/origin/*",
+			"+refs/tags/*:refs/tags/*",
+		},
+		Progress: out,
+		Force:    true,
+	})
+	return SimplifyGitErr(err)
+}
+
 // UpdateRepository pulls and checkouts given branch from repository
 func UpdateRepository(repo *gogit.Repository, opts RepoOptions, out io.Writer) error {
 	tree, err := repo.Worktree()
@@ -94,6 +143,7 @@ func UpdateRepository(repo *gogit.Repository, opts RepoOptions, out io.Writer) e
 		Auth:       opts.Auth,
 		RefSpecs:   []config.RefSpec{"refs/*:refs/*"},
 		Tags:       gogit.AllTags,
+		Depth:      opts.Depth,
 		Progress:   out,
 		Force:      true,
 	})
@@ -101,6 +151,14 @@ func UpdateRepository(repo *gogit.Repository, opts RepoOptions, out io.Writer) e
 		return err
 	}
 
+	// Pinned deployments check out exactly the requested ref
+	if opts.Ref != "" {
+		if err := CheckoutRef(repo, opts.Ref, out); err != nil {
+			return err
+		}
+		return UpdateWorktree(repo, opts, out)
+	}
+
 	var ref = plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", opts.Branch))
 	fmt.Fprintf(out, "Checking out '%s'...\n", ref)
 	err = tree.Checkout(&gogit.CheckoutOptions{
@@ -116,10 +174,246 @@ func UpdateRepository(repo *gogit.Repository, opts RepoOptions, out io.Writer) e
 		RemoteName:    "origin",
 		ReferenceName: ref,
 		Auth:          opts.Auth,
+		Depth:         opts.Depth,
 		Progress:      out,
 		Force:         true,
+	})
+	if err = SimplifyGitErr(err); err != nil {
+		return err
+	}
+	return UpdateWorktree(repo, opts, out)
+}
+
+// UpdateWorktree prunes the worktree, updates submodules, and fetches Git LFS
+// objects for the checked out commit, if enabled in the given options
+func UpdateWorktree(repo *gogit.Repository, opts RepoOptions, out io.Writer) error {
+	if err := PruneWorktree(repo, opts.PruneTo, out); err != nil {
+		return err
+	}
+	if opts.Submodules {
+		if err := UpdateSubmodules(repo, opts.Auth, opts.PruneTo, out); err != nil {
+			return err
+		}
+	}
+	if opts.LFS {
+		fmt.Fprintln(out, "Fetching LFS objects...")
+		if err := FetchLFSObjects(repo, opts.Auth, opts.PruneTo, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateSubmodules recursively initializes and updates submodules to match the
+// checked out commit. Relative submodule URLs are resolved against origin, and
+// submodules outside the given prune paths are skipped.
+func UpdateSubmodules(repo *gogit.Repository, auth transport.AuthMethod, keep PrunePaths, out io.Writer) error {
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	submodules, err := tree.Submodules()
+	if err != nil {
+		return err
+	}
+	if len(submodules) == 0 {
+		return nil
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return err
+	}
+
+	for _, s := range submodules {
+		var conf = s.Config()
+		if !keep.includesDir(conf.Path) {
+			continue
+		}
+		conf.URL = resolveSubmoduleURL(remote.Config().URLs[0], conf.URL)
+		fmt.Fprintf(out, "Updating submodule '%s' from %s...\n", conf.Path, conf.URL)
+		err := s.Update(&gogit.SubmoduleUpdateOptions{
+			Init:              true,
+			RecurseSubmodules: gogit.DefaultSubmoduleRecursionDepth,
+			Auth:              authForURL(conf.URL, auth),
+		})
+		if err = SimplifyGitErr(err); err != nil {
+			return fmt.Errorf("failed to update submodule '%s': %s", conf.Path, err.Error())
+		}
+	}
+	return nil
+}
+
+// resolveSubmoduleURL resolves the given submodule URL against the URL of the
+// parent repository if it is relative, the same way git does
+func resolveSubmoduleURL(parent, submodule string) string {
+	if !strings.HasPrefix(submodule, "./") && !strings.HasPrefix(submodule, "../") {
+		return submodule
+	}
+	parent = strings.TrimSuffix(parent, "/")
+	if strings.Contains(parent, "://") {
+		u, err := url.Parse(parent)
+		if err != nil {
+			return submodule
+		}
+		u.Path = path.Join(u.Path, submodule)
+		return u.String()
+	}
+	// scp-like URLs, such as git@github.com:ubclaunchpad/inertia.git
+	if i := strings.Index(parent, ":"); i >= 0 {
+		return parent[:i+1] + path.Join(parent[i+1:], submodule)
+	}
+	return path.Join(parent, submodule)
+}
+
+// SetRemoteURL points the repository's origin at the given URL, if it is not
+// already. An empty URL is ignored.
+func SetRemoteURL(repo *gogit.Repository, remoteURL string) error {
+	if remoteURL == "" {
+		return nil
+	}
+	conf, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	origin, found := conf.Remotes["origin"]
+	if !found {
+		return errors.New("repository has no origin remote")
+	}
+	if len(origin.URLs) == 1 && origin.URLs[0] == remoteURL {
+		return nil
+	}
+	origin.URLs = []string{remoteURL}
+	return repo.SetConfig(conf)
+}
 
-		RecurseSubmodules: gogit.DefaultSubmoduleRecursionDepth,
+// IsHTTPRemote checks if the given remote URL is accessed over HTTP or HTTPS
+func IsHTTPRemote(remoteURL string) bool {
+	ep, err := transport.NewEndpoint(remoteURL)
+	return err == nil && (ep.Protocol == "http" || ep.Protocol == "https")
+}
+
+// authForURL returns the given auth method if it can be used with the given
+// URL, since SSH keys cannot be used with HTTP remotes and vice versa
+func authForURL(remoteURL string, auth transport.AuthMethod) transport.AuthMethod {
+	ep, err := transport.NewEndpoint(remoteURL)
+	if err != nil || auth == nil {
+		return auth
+	}
+	if _, isSSH := auth.(gitssh.AuthMethod); isSSH != (ep.Protocol == "ssh") {
+		return nil
+	}
+	return auth
+}
+
+// CheckoutCommit checks out the given commit hash, which must already be
+// available in the repository
+func CheckoutCommit(repo *gogit.Repository, hash string, out io.Writer) error {
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Checking out commit '%s'...\n", hash)
+	return tree.Checkout(&gogit.CheckoutOptions{
+		Hash:  plumbing.NewHash(hash),
+		Force: true,
 	})
-	return SimplifyGitErr(err)
+}
+
+// CheckoutRef resolves the given commit hash, tag, or branch name and checks
+// out the commit it points to. The ref must already be available in the
+// repository.
+func CheckoutRef(repo *gogit.Repository, ref string, out io.Writer) error {
+	hash, err := ResolveRef(repo, ref)
+	if err != nil {
+		return err
+	}
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Checking out ref '%s' (%s)...\n", ref, hash.String())
+	return tree.Checkout(&gogit.CheckoutOptions{
+		Hash:  *hash,
+		Force: true,
+	})
+}
+
+// ResolveRef finds the commit the given commit hash, tag, or branch name
+// points to
+func ResolveRef(repo *gogit.Repository, ref string) (*plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref '%s': %s", ref, err.Error())
+	}
+	return hash, nil
+}
+
+// CommitsBetween lists the commits reachable from the commit to that are not
+// the commit from, most recent first. It stops once it reaches from, or once
+// limit commits have been listed.
+func CommitsBetween(repo *gogit.Repository, from, to plumbing.Hash, limit int) ([]*object.Commit, error) {
+	iter, err := repo.Log(&gogit.LogOptions{From: to})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var commits = make([]*object.Commit, 0)
+	for len(commits) < limit {
+		c, err := iter.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if c.Hash == from {
+			break
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// FileChange describes a file that differs between two commits
+type FileChange struct {
+	// Action is one of "added", "deleted", or "modified"
+	Action string
+	Path   string
+}
+
+// ChangedFiles lists the files that differ between the given commits
+func ChangedFiles(repo *gogit.Repository, from, to plumbing.Hash) ([]FileChange, error) {
+	var trees = make([]*object.Tree, 2)
+	for i, hash := range []plumbing.Hash{from, to} {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		if trees[i], err = commit.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(trees[0], trees[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var files = make([]FileChange, 0, len(changes))
+	for _, c := range changes {
+		action, err := c.Action()
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case merkletrie.Insert:
+			files = append(files, FileChange{"added", c.To.Name})
+		case merkletrie.Delete:
+			files = append(files, FileChange{"deleted", c.From.Name})
+		default:
+			files = append(files, FileChange{"modified", c.To.Name})
+		}
+	}
+	return files, nil
 }
@@ -1,10 +1,17 @@
 package git
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,3 +55,308 @@ func TestUpdateRepositoryIntegration(t *testing.T) {
 	err = UpdateRepository(repo, RepoOptions{Branch: "dev"}, os.Stdout)
 	assert.NoError(t, err)
 }
+
+func TestCheckoutCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-checkout")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	tree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	// Create two commits
+	var commit = func(content string) string {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte(content), 0644))
+		_, err := tree.Add("file")
+		assert.NoError(t, err)
+		hash, err := tree.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "inertia", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash.String()
+	}
+	var first = commit("first")
+	commit("second")
+
+	// Check out the first commit again
+	assert.NoError(t, CheckoutCommit(repo, first, os.Stdout))
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, first, head.Hash().String())
+	content, err := ioutil.ReadFile(filepath.Join(dir, "file"))
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+}
+
+func TestCheckoutRef(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-checkout-ref")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	tree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	// Create a tagged commit followed by an untagged one
+	var commit = func(content string) string {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte(content), 0644))
+		_, err := tree.Add("file")
+		assert.NoError(t, err)
+		hash, err := tree.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "inertia", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash.String()
+	}
+	var first = commit("first")
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", head.Hash(), nil)
+	assert.NoError(t, err)
+	var second = commit("second")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"tag", "v1.0.0", first, false},
+		{"full hash", first, first, false},
+		{"short hash", second[:7], second, false},
+		{"branch", "master", second, false},
+		{"unknown ref", "v2.0.0", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckoutRef(repo, tt.ref, os.Stdout)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			head, err := repo.Head()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, head.Hash().String())
+		})
+	}
+}
+
+func TestCommitsBetweenAndChangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-diff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	tree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	var commit = func(msg string) plumbing.Hash {
+		hash, err := tree.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{Name: "inertia", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash
+	}
+
+	// Set up a base commit, then add, modify, and delete files
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "modified"), []byte("a"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "deleted"), []byte("a"), 0644))
+	tree.Add("modified")
+	tree.Add("deleted")
+	var base = commit("base")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "modified"), []byte("b"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "added"), []byte("b"), 0644))
+	tree.Add("modified")
+	tree.Add("added")
+	commit("second")
+	_, err = tree.Remove("deleted")
+	assert.NoError(t, err)
+	var head = commit("third")
+
+	commits, err := CommitsBetween(repo, base, head, 10)
+	assert.NoError(t, err)
+	assert.Len(t, commits, 2)
+	assert.Equal(t, "third", commits[0].Message)
+	assert.Equal(t, "second", commits[1].Message)
+
+	commits, err = CommitsBetween(repo, base, head, 1)
+	assert.NoError(t, err)
+	assert.Len(t, commits, 1)
+
+	files, err := ChangedFiles(repo, base, head)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []FileChange{
+		{"added", "added"},
+		{"deleted", "deleted"},
+		{"modified", "modified"},
+	}, files)
+}
+
+func Test_resolveSubmoduleURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		parent    string
+		submodule string
+		want      string
+	}{
+		{"absolute", "git@github.com:ubclaunchpad/inertia.git",
+			"https://github.com/ubclaunchpad/shared.git", "https://github.com/ubclaunchpad/shared.git"},
+		{"scp-like", "git@github.com:ubclaunchpad/inertia.git",
+			"../shared.git", "git@github.com:ubclaunchpad/shared.git"},
+		{"url", "https://github.com/ubclaunchpad/inertia.git",
+			"../shared.git", "https://github.com/ubclaunchpad/shared.git"},
+		{"ssh url", "ssh://git@github.com:22/ubclaunchpad/inertia/",
+			"../../bobheadxi/shared.git", "ssh://git@github.com:22/bobheadxi/shared.git"},
+		{"nested", "git@github.com:ubclaunchpad/inertia.git",
+			"./shared", "git@github.com:ubclaunchpad/inertia.git/shared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveSubmoduleURL(tt.parent, tt.submodule))
+		})
+	}
+}
+
+func Test_authForURL(t *testing.T) {
+	var auth = &ssh.PublicKeys{User: "git"}
+	assert.Equal(t, auth, authForURL("git@github.com:ubclaunchpad/inertia.git", auth))
+	assert.Nil(t, authForURL("https://github.com/ubclaunchpad/inertia.git", auth))
+}
+
+func TestSetRemoteURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-remote")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+
+	// No origin to update
+	assert.Error(t, SetRemoteURL(repo, "https://github.com/ubclaunchpad/inertia.git"))
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"git@github.com:ubclaunchpad/inertia.git"},
+	})
+	assert.NoError(t, err)
+
+	// Empty URLs are ignored
+	assert.NoError(t, SetRemoteURL(repo, ""))
+	remote, err := repo.Remote("origin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"git@github.com:ubclaunchpad/inertia.git"}, remote.Config().URLs)
+
+	// Switch to HTTPS
+	assert.NoError(t, SetRemoteURL(repo, "https://github.com/ubclaunchpad/inertia.git"))
+	remote, err = repo.Remote("origin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/ubclaunchpad/inertia.git"}, remote.Config().URLs)
+}
+
+func TestShallowClone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-shallow")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Set up a remote with some history
+	var remoteDir = filepath.Join(dir, "remote")
+	remote, err := git.PlainInit(remoteDir, false)
+	assert.NoError(t, err)
+	tree, err := remote.Worktree()
+	assert.NoError(t, err)
+	var commit = func(content string) string {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(remoteDir, "file"), []byte(content), 0644))
+		_, err := tree.Add("file")
+		assert.NoError(t, err)
+		hash, err := tree.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "inertia", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash.String()
+	}
+	for _, c := range []string{"first", "second", "third"} {
+		commit(c)
+	}
+
+	// Clone only the latest commit
+	var opts = RepoOptions{Directory: filepath.Join(dir, "project"), Branch: "master", Depth: 1}
+	repo, err := clone("file://"+remoteDir, opts, ioutil.Discard)
+	assert.NoError(t, err)
+	var history = func() []string {
+		head, err := repo.Head()
+		assert.NoError(t, err)
+		iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+		assert.NoError(t, err)
+		var messages []string
+		assert.NoError(t, iter.ForEach(func(c *object.Commit) error {
+			messages = append(messages, c.Message)
+			return nil
+		}))
+		return messages
+	}
+	assert.Equal(t, []string{"third"}, history())
+
+	// Updates should work with shallow history, and keep previously fetched
+	// commits around for rollbacks
+	commit("fourth")
+	var fifth = commit("fifth")
+	assert.NoError(t, UpdateRepository(repo, opts, ioutil.Discard))
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, fifth, head.Hash().String())
+	assert.Equal(t, []string{"fifth"}, history())
+	content, err := ioutil.ReadFile(filepath.Join(opts.Directory, "file"))
+	assert.NoError(t, err)
+	assert.Equal(t, "fifth", string(content))
+}
+
+func TestOpenRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-open")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Set up a remote with some history
+	var remoteDir = filepath.Join(dir, "remote")
+	remote, err := git.PlainInit(remoteDir, false)
+	assert.NoError(t, err)
+	tree, err := remote.Worktree()
+	assert.NoError(t, err)
+	for _, c := range []string{"first", "second"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(remoteDir, "file"), []byte(c), 0644))
+		_, err := tree.Add("file")
+		assert.NoError(t, err)
+		_, err = tree.Commit(c, &git.CommitOptions{
+			Author: &object.Signature{Name: "inertia", When: time.Now()},
+		})
+		assert.NoError(t, err)
+	}
+
+	// Nothing to open before the repository is set up
+	var opts = RepoOptions{Directory: filepath.Join(dir, "project"), Branch: "master", Depth: 1}
+	_, err = OpenRepository(opts.Directory)
+	assert.Error(t, err)
+
+	// Reopened shallow clones should still be walkable
+	cloned, err := clone("file://"+remoteDir, opts, ioutil.Discard)
+	assert.NoError(t, err)
+	clonedHead, err := cloned.Head()
+	assert.NoError(t, err)
+	repo, err := OpenRepository(opts.Directory)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, clonedHead.Hash(), head.Hash())
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	assert.NoError(t, err)
+	var messages []string
+	assert.NoError(t, iter.ForEach(func(c *object.Commit) error {
+		messages = append(messages, c.Message)
+		return nil
+	}))
+	assert.Equal(t, []string{"second"}, messages)
+}
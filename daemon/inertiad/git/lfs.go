@@ -0,0 +1,390 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	units "github.com/docker/go-units"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// lfsPointerVersion is the first line of all Git LFS pointer files
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+	// lfsMaxPointerSize is the size that Git LFS pointer files are always
+	// smaller than
+	lfsMaxPointerSize = 1024
+	// lfsMediaType is the content type used by the Git LFS API
+	lfsMediaType = "application/vnd.git-lfs+json"
+)
+
+// lfsObject identifies an object stored with Git LFS
+type lfsObject struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// lfsAction describes how to perform an operation on an object, or how to
+// access the Git LFS API
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+// lfsBatchRequest is a request to the Git LFS batch API
+type lfsBatchRequest struct {
+	Operation string      `json:"operation"`
+	Transfers []string    `json:"transfers"`
+	Objects   []lfsObject `json:"objects"`
+}
+
+// lfsBatchResponse is a response from the Git LFS batch API
+type lfsBatchResponse struct {
+	Objects []struct {
+		lfsObject
+		Actions struct {
+			Download *lfsAction `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+	Message string `json:"message"`
+}
+
+// parseLFSPointer checks if the given file contents are a Git LFS pointer,
+// and returns the object it points to if so
+func parseLFSPointer(data []byte) (lfsObject, bool) {
+	var (
+		object  lfsObject
+		scanner = bufio.NewScanner(bytes.NewReader(data))
+	)
+	if !scanner.Scan() || scanner.Text() != lfsPointerVersion {
+		return object, false
+	}
+	for scanner.Scan() {
+		var parts = strings.SplitN(scanner.Text(), " ", 2)
+		if len(parts) != 2 {
+			return object, false
+		}
+		switch parts[0] {
+		case "oid":
+			var oid = strings.TrimPrefix(parts[1], "sha256:")
+			if _, err := hex.DecodeString(oid); err != nil || len(oid) != sha256.Size*2 {
+				return object, false
+			}
+			object.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil || size < 0 {
+				return object, false
+			}
+			object.Size = size
+		}
+	}
+	return object, object.OID != ""
+}
+
+// FetchLFSObjects replaces the Git LFS pointer files in the checked out commit
+// with the objects they point to. Objects are cached in the repository, so
+// only objects that have not been fetched before are downloaded. Pointer files
+// outside the given prune paths are skipped.
+func FetchLFSObjects(repo *gogit.Repository, auth transport.AuthMethod, keep PrunePaths, out io.Writer) error {
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+
+	// Find pointer files in the commit
+	var (
+		paths   = make(map[string][]string)
+		objects = make([]lfsObject, 0)
+	)
+	if err := files.ForEach(func(f *object.File) error {
+		if f.Size >= lfsMaxPointerSize || !f.Mode.IsFile() || !keep.includesFile(f.Name) {
+			return nil
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		if pointer, ok := parseLFSPointer([]byte(contents)); ok {
+			if _, found := paths[pointer.OID]; !found {
+				objects = append(objects, pointer)
+			}
+			paths[pointer.OID] = append(paths[pointer.OID], f.Name)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+
+	// Download objects that are not yet cached
+	var (
+		root    = tree.Filesystem.Root()
+		cache   = filepath.Join(root, ".git", "lfs", "objects")
+		missing = make([]lfsObject, 0)
+	)
+	for _, o := range objects {
+		if info, err := os.Stat(lfsCachePath(cache, o.OID)); err != nil || info.Size() != o.Size {
+			missing = append(missing, o)
+		}
+	}
+	fmt.Fprintf(out, "Found %d LFS objects, %d to download...\n", len(objects), len(missing))
+	if len(missing) > 0 {
+		remote, err := repo.Remote("origin")
+		if err != nil {
+			return err
+		}
+		endpoint, err := lfsEndpoint(remote.Config().URLs[0], auth)
+		if err != nil {
+			return fmt.Errorf("failed to find LFS server: %s", err.Error())
+		}
+		if err := lfsDownload(endpoint, missing, paths, cache, out); err != nil {
+			return err
+		}
+	}
+
+	// Replace pointer files with their objects
+	for _, o := range objects {
+		for _, p := range paths[o.OID] {
+			if err := copyFile(lfsCachePath(cache, o.OID), filepath.Join(root, p)); err != nil {
+				return fmt.Errorf("failed to check out LFS object for '%s': %s", p, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// lfsCachePath returns the location of the given object in the LFS cache, the
+// same way Git LFS lays out objects
+func lfsCachePath(cache, oid string) string {
+	return filepath.Join(cache, oid[0:2], oid[2:4], oid)
+}
+
+// lfsEndpoint determines the Git LFS API endpoint for the given remote, and
+// the headers required to access it
+func lfsEndpoint(remoteURL string, auth transport.AuthMethod) (lfsAction, error) {
+	ep, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return lfsAction{}, err
+	}
+	var repoPath = strings.TrimPrefix(ep.Path, "/")
+	if !strings.HasSuffix(repoPath, ".git") {
+		repoPath += ".git"
+	}
+
+	switch ep.Protocol {
+	case "http", "https":
+		var host = ep.Host
+		if ep.Port != 0 && !((ep.Protocol == "http" && ep.Port == 80) ||
+			(ep.Protocol == "https" && ep.Port == 443)) {
+			host = net.JoinHostPort(ep.Host, strconv.Itoa(ep.Port))
+		}
+		var action = lfsAction{
+			Href:   (&url.URL{Scheme: ep.Protocol, Host: host, Path: "/" + repoPath + "/info/lfs"}).String(),
+			Header: make(map[string]string),
+		}
+		if basic, ok := auth.(*githttp.BasicAuth); ok {
+			action.Header["Authorization"] = basicAuthHeader(basic.Username, basic.Password)
+		} else if ep.User != "" {
+			action.Header["Authorization"] = basicAuthHeader(ep.User, ep.Password)
+		}
+		return action, nil
+
+	case "ssh":
+		// The LFS server is found by asking the git host over SSH, which also
+		// provides credentials for the API
+		method, ok := auth.(gitssh.AuthMethod)
+		if !ok {
+			return lfsAction{}, errors.New("SSH authentication is required for LFS over SSH")
+		}
+		conf, err := method.ClientConfig()
+		if err != nil {
+			return lfsAction{}, err
+		}
+		var port = ep.Port
+		if port == 0 {
+			port = 22
+		}
+		client, err := ssh.Dial("tcp", net.JoinHostPort(ep.Host, strconv.Itoa(port)), conf)
+		if err != nil {
+			return lfsAction{}, err
+		}
+		defer client.Close()
+		session, err := client.NewSession()
+		if err != nil {
+			return lfsAction{}, err
+		}
+		defer session.Close()
+		output, err := session.Output("git-lfs-authenticate '" +
+			strings.Replace(repoPath, "'", `'\''`, -1) + "' download")
+		if err != nil {
+			return lfsAction{}, fmt.Errorf("git-lfs-authenticate failed: %s", err.Error())
+		}
+		var action lfsAction
+		if err := json.Unmarshal(output, &action); err != nil {
+			return lfsAction{}, fmt.Errorf("invalid git-lfs-authenticate response: %s", err.Error())
+		}
+		if action.Href == "" {
+			action.Href = "https://" + ep.Host + "/" + repoPath + "/info/lfs"
+		}
+		return action, nil
+
+	default:
+		return lfsAction{}, fmt.Errorf("LFS is not supported for %s remotes", ep.Protocol)
+	}
+}
+
+// lfsDownload downloads the given objects into the cache using the Git LFS
+// batch API at the given endpoint
+func lfsDownload(endpoint lfsAction, objects []lfsObject, paths map[string][]string,
+	cache string, out io.Writer) error {
+	body, err := json.Marshal(lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   objects,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(endpoint.Href, "/")+"/objects/batch",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range endpoint.Header {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("LFS batch request failed: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("invalid LFS batch response: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("LFS batch request failed with status %d: %s", resp.StatusCode, batch.Message)
+	}
+
+	for _, o := range batch.Objects {
+		var name = o.OID
+		if p := paths[o.OID]; len(p) > 0 {
+			name = p[0]
+		}
+		if o.Error != nil {
+			return fmt.Errorf("failed to download LFS object for '%s': %s", name, o.Error.Message)
+		}
+		if o.Actions.Download == nil {
+			continue
+		}
+		fmt.Fprintf(out, "Downloading LFS object for '%s' (%s)...\n", name, units.HumanSize(float64(o.Size)))
+		if err := lfsDownloadObject(*o.Actions.Download, o.lfsObject, cache); err != nil {
+			return fmt.Errorf("failed to download LFS object for '%s': %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// lfsDownloadObject downloads an object into the cache, verifying its contents
+func lfsDownloadObject(action lfsAction, object lfsObject, cache string) error {
+	req, err := http.NewRequest("GET", action.Href, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var dest = lfsCachePath(cache, object.OID)
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), object.OID+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	var hash = sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	tmp.Close()
+	if err != nil {
+		return err
+	}
+	if size != object.Size || hex.EncodeToString(hash.Sum(nil)) != object.OID {
+		return errors.New("downloaded object does not match its pointer")
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// copyFile overwrites the contents of dst with the contents of src
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// basicAuthHeader returns an HTTP basic authorization header value
+func basicAuthHeader(username, password string) string {
+	req := http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, password)
+	return req.Header.Get("Authorization")
+}
@@ -0,0 +1,171 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PrunePaths are patterns of paths that a repository's worktree is pruned to
+// after each checkout by PruneWorktree. Each pattern is a path relative to the
+// root of the repository, and may contain wildcards as supported by
+// path.Match - everything within a matching directory is kept. Files at the
+// root of the repository are always kept. An empty set of patterns keeps
+// everything.
+type PrunePaths []string
+
+// Validate checks that each pattern is a valid path within the repository
+func (s PrunePaths) Validate() error {
+	for _, p := range s {
+		if err := ValidatePattern(p); err != nil {
+			return fmt.Errorf("invalid prune path: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// ValidatePattern checks that the given path pattern is a valid path within a
+// repository
+func ValidatePattern(pattern string) error {
+	var clean = path.Clean(pattern)
+	if pattern == "" || clean == "." {
+		return fmt.Errorf("path '%s' must not be empty", pattern)
+	}
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("path '%s' must be within the repository", pattern)
+	}
+	if _, err := path.Match(clean, ""); err != nil {
+		return fmt.Errorf("path '%s' is not a valid pattern: %s", pattern, err.Error())
+	}
+	return nil
+}
+
+// MatchPattern checks if the given path pattern matches the given
+// slash-separated path, or one of its parents
+func MatchPattern(pattern, p string) bool {
+	return matchPattern(strings.Split(path.Clean(pattern), "/"), strings.Split(path.Clean(p), "/"))
+}
+
+func matchPattern(pattern, segments []string) bool {
+	return len(pattern) <= len(segments) && matchSegments(pattern, segments[:len(pattern)])
+}
+
+// includesFile checks if the given file, relative to the root of the
+// repository, should be kept in the worktree
+func (s PrunePaths) includesFile(p string) bool {
+	var segments = strings.Split(path.Clean(p), "/")
+	return len(s) == 0 || len(segments) == 1 || s.matches(segments)
+}
+
+// includesDir checks if the given directory, relative to the root of the
+// repository, should be kept in the worktree in its entirety
+func (s PrunePaths) includesDir(p string) bool {
+	return len(s) == 0 || s.matches(strings.Split(path.Clean(p), "/"))
+}
+
+// matches checks if any pattern matches the given path or one of its parents
+func (s PrunePaths) matches(segments []string) bool {
+	for _, p := range s {
+		if matchPattern(strings.Split(path.Clean(p), "/"), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayContain checks if any pattern could match a path within the given
+// directory
+func (s PrunePaths) mayContain(segments []string) bool {
+	for _, p := range s {
+		var pattern = strings.Split(path.Clean(p), "/")
+		if len(pattern) > len(segments) && matchSegments(pattern[:len(segments)], segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches each path segment against the corresponding pattern
+// segment
+func matchSegments(pattern, segments []string) bool {
+	for i := range pattern {
+		if ok, _ := path.Match(pattern[i], segments[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// PruneWorktree removes files tracked in the checked out commit that are not
+// included in the given paths from the repository's worktree. Untracked files
+// are left alone.
+//
+// Pruning happens after a full checkout, since go-git always checks out every
+// file - pruned files are still written to disk on every checkout before they
+// are removed, so peak disk usage and I/O are not reduced.
+func PruneWorktree(repo *gogit.Repository, paths PrunePaths, out io.Writer) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	tree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	files, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Pruning worktree to %s...\n", strings.Join(paths, ", "))
+	return pruneTree(tree.Filesystem.Root(), files, nil, paths)
+}
+
+// pruneTree removes entries of the given tree, located at dir, that are not
+// included in the given paths. Directories are only removed once they are
+// empty, so that untracked files are kept.
+func pruneTree(root string, tree *object.Tree, dir []string, paths PrunePaths) error {
+	for _, e := range tree.Entries {
+		var segments = append(dir[:len(dir):len(dir)], e.Name)
+		if paths.matches(segments) || (len(segments) == 1 && e.Mode.IsFile()) {
+			continue
+		}
+		var file = filepath.Join(append([]string{root}, segments...)...)
+		switch e.Mode {
+		case filemode.Dir:
+			subtree, err := tree.Tree(e.Name)
+			if err != nil {
+				return err
+			}
+			if err := pruneTree(root, subtree, segments, paths); err != nil {
+				return err
+			}
+			if !paths.mayContain(segments) {
+				os.Remove(file)
+			}
+		case filemode.Submodule:
+			if err := os.RemoveAll(file); err != nil {
+				return err
+			}
+		default:
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
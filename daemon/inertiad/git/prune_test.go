@@ -0,0 +1,133 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrunePaths_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   PrunePaths
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"paths", PrunePaths{"services/api", "libs/*"}, false},
+		{"empty path", PrunePaths{""}, true},
+		{"root", PrunePaths{"."}, true},
+		{"absolute path", PrunePaths{"/etc"}, true},
+		{"outside repository", PrunePaths{"services/../../etc"}, true},
+		{"bad pattern", PrunePaths{"libs/[a"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.paths.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("PrunePaths.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrunePaths_includes(t *testing.T) {
+	var paths = PrunePaths{"services/api", "libs/*/src"}
+	tests := []struct {
+		path     string
+		wantFile bool
+		wantDir  bool
+	}{
+		{"Dockerfile", true, false},
+		{"services/api", true, true},
+		{"services/api/main.go", true, true},
+		{"services/web/main.go", false, false},
+		{"libs/auth/src/auth.go", true, true},
+		{"libs/auth/README.md", false, false},
+		{"docs", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.wantFile, paths.includesFile(tt.path))
+			assert.Equal(t, tt.wantDir, paths.includesDir(tt.path))
+		})
+	}
+
+	// empty paths include everything
+	assert.True(t, PrunePaths{}.includesFile("services/web/main.go"))
+	assert.True(t, PrunePaths{}.includesDir("services/web"))
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"services/api", "services/api", true},
+		{"services/api", "services/api/main.go", true},
+		{"services/api", "services/web/main.go", false},
+		{"services/*", "services/web/main.go", true},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/index.md", false},
+		{"services/api/main.go", "services/api", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPattern(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestPruneWorktree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-prune")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	tree, err := repo.Worktree()
+	assert.NoError(t, err)
+	var files = []string{
+		"Dockerfile",
+		"services/api/main.go",
+		"services/web/main.go",
+		"libs/auth/src/auth.go",
+		"libs/auth/README.md",
+		"docs/index.md",
+	}
+	for _, f := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, f), []byte(f), 0644))
+		_, err := tree.Add(f)
+		assert.NoError(t, err)
+	}
+	_, err = tree.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "inertia", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docs", "untracked"), nil, 0644))
+
+	// nothing is removed without paths to prune to
+	assert.NoError(t, PruneWorktree(repo, nil, ioutil.Discard))
+	for _, f := range files {
+		assert.FileExists(t, filepath.Join(dir, f))
+	}
+
+	assert.NoError(t, PruneWorktree(repo, PrunePaths{"services/api", "libs/*/src"}, ioutil.Discard))
+	for _, f := range []string{"Dockerfile", "services/api/main.go", "libs/auth/src/auth.go", "docs/untracked"} {
+		assert.FileExists(t, filepath.Join(dir, f))
+	}
+	for _, f := range []string{"services/web", "libs/auth/README.md", "docs/index.md"} {
+		_, err := os.Stat(filepath.Join(dir, f))
+		assert.True(t, os.IsNotExist(err), f)
+	}
+
+	// pruned files are restored when checking out again
+	assert.NoError(t, tree.Checkout(&git.CheckoutOptions{Branch: "refs/heads/master", Force: true}))
+	assert.FileExists(t, filepath.Join(dir, "services/web/main.go"))
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"sync"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// plainInit is the same as gogit.PlainInit, but sets up the repository with
+// shallowStorage so that shallow clones can be updated and walked
+func plainInit(dir string) (*gogit.Repository, error) {
+	var worktree = osfs.New(dir)
+	dot, err := worktree.Chroot(gogit.GitDirName)
+	if err != nil {
+		return nil, err
+	}
+	return gogit.Init(newShallowStorage(filesystem.NewStorage(dot, cache.NewObjectLRUDefault())), worktree)
+}
+
+// plainOpen is the same as gogit.PlainOpen, but opens the repository with
+// shallowStorage, as set up by plainInit
+func plainOpen(dir string) (*gogit.Repository, error) {
+	var worktree = osfs.New(dir)
+	dot, err := worktree.Chroot(gogit.GitDirName)
+	if err != nil {
+		return nil, err
+	}
+	return gogit.Open(newShallowStorage(filesystem.NewStorage(dot, cache.NewObjectLRUDefault())), worktree)
+}
+
+// shallowStorage wraps repository storage to treat the boundary commits of a
+// shallow clone as if they had no parents, the same way git does. Otherwise,
+// walking commit history - which go-git does when fetching, pulling, and
+// listing commits - fails once it reaches a parent that was never fetched.
+type shallowStorage struct {
+	*filesystem.Storage
+
+	mux      sync.Mutex
+	shallows map[plumbing.Hash]bool
+}
+
+func newShallowStorage(s *filesystem.Storage) *shallowStorage {
+	return &shallowStorage{Storage: s}
+}
+
+// EncodedObject retrieves the given object, removing parents from shallow
+// commits
+func (s *shallowStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.Storage.EncodedObject(t, h)
+	if err != nil || obj.Type() != plumbing.CommitObject {
+		return obj, err
+	}
+	shallow, err := s.isShallow(h)
+	if err != nil || !shallow {
+		return obj, err
+	}
+
+	var commit object.Commit
+	if err := commit.Decode(obj); err != nil {
+		return nil, err
+	}
+	commit.ParentHashes = nil
+	var grafted = s.Storage.NewEncodedObject()
+	if err := commit.Encode(grafted); err != nil {
+		return nil, err
+	}
+	return &graftedCommit{EncodedObject: grafted, hash: h}, nil
+}
+
+// SetShallow updates the shallow commits of the repository
+func (s *shallowStorage) SetShallow(commits []plumbing.Hash) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.shallows = nil
+	return s.Storage.SetShallow(commits)
+}
+
+func (s *shallowStorage) isShallow(h plumbing.Hash) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.shallows == nil {
+		commits, err := s.Storage.Shallow()
+		if err != nil {
+			return false, err
+		}
+		s.shallows = make(map[plumbing.Hash]bool, len(commits))
+		for _, c := range commits {
+			s.shallows[c] = true
+		}
+	}
+	return s.shallows[h], nil
+}
+
+// graftedCommit is a commit whose contents were modified, but that should
+// still be identified by its original hash
+type graftedCommit struct {
+	plumbing.EncodedObject
+	hash plumbing.Hash
+}
+
+func (c *graftedCommit) Hash() plumbing.Hash { return c.hash }
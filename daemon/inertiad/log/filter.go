@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+)
+
+// lineLevel matches the level of log entries in both text and JSON formats
+var lineLevel = regexp.MustCompile(`(?:^|[\s{,])"?level"?[=:]"?(debug|info|warn|error)\b`)
+
+// LineLevel returns the level of the given line of logs. Lines that are not
+// log entries, such as deploy output, are treated as info.
+func LineLevel(line string) Level {
+	if m := lineLevel.FindStringSubmatch(line); m != nil {
+		level, _ := ParseLevel(m[1])
+		return level
+	}
+	return LevelInfo
+}
+
+// FilterLevel returns a reader of the lines of logs in the given reader that
+// are of at least the given level
+func FilterLevel(r io.Reader, min Level) io.Reader {
+	return &levelFilter{r: bufio.NewReader(r), min: min}
+}
+
+type levelFilter struct {
+	r       *bufio.Reader
+	min     Level
+	pending []byte
+	err     error
+}
+
+func (f *levelFilter) Read(p []byte) (int, error) {
+	for len(f.pending) == 0 {
+		if f.err != nil {
+			return 0, f.err
+		}
+		var line []byte
+		line, f.err = f.r.ReadBytes('\n')
+		if len(line) > 0 && LineLevel(string(line)) >= f.min {
+			f.pending = line
+		}
+	}
+	var n = copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
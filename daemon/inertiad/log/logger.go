@@ -0,0 +1,225 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// Level is the severity of a log entry
+type Level int
+
+// Log levels, from least to most severe. The zero value is LevelInfo.
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l-LevelDebug]
+}
+
+// ParseLevel returns the level with the given name
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("invalid log level '%s' - must be one of %s",
+		name, strings.Join(levelNames, ", "))
+}
+
+// Log entry formats
+const (
+	// FormatText formats entries as key=value pairs
+	FormatText = "text"
+	// FormatJSON formats entries as JSON objects
+	FormatJSON = "json"
+)
+
+// ValidateFormat returns an error if the given log entry format is not
+// supported
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid log format '%s' - must be one of %s, %s",
+		format, FormatText, FormatJSON)
+}
+
+// LoggerOptions defines configuration for a logger
+type LoggerOptions struct {
+	Writer io.Writer
+	Level  Level
+	Format string // defaults to FormatText
+}
+
+// Logger writes structured, levelled log entries. Loggers created with With
+// share their parent's writer, and are safe for concurrent use. A nil Logger
+// discards all entries.
+type Logger struct {
+	out    io.Writer
+	mux    *sync.Mutex
+	level  Level
+	json   bool
+	fields []interface{}
+}
+
+// NewLogger creates a new logger, which writes to stdout by default
+func NewLogger(opts LoggerOptions) *Logger {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+	return &Logger{
+		out:   opts.Writer,
+		mux:   &sync.Mutex{},
+		level: opts.Level,
+		json:  opts.Format == FormatJSON,
+	}
+}
+
+// With returns a logger that adds the given key-value pairs to every entry
+func (l *Logger) With(kvs ...interface{}) *Logger {
+	if l == nil {
+		return nil
+	}
+	var child = *l
+	child.fields = append(append(make([]interface{}, 0, len(l.fields)+len(kvs)), l.fields...), kvs...)
+	return &child
+}
+
+// WithRequest returns a logger that adds the ID of the given request, if it
+// has one, to every entry
+func (l *Logger) WithRequest(r *http.Request) *Logger {
+	if id := middleware.GetReqID(r.Context()); id != "" {
+		return l.With("request_id", id)
+	}
+	return l
+}
+
+// Enabled returns true if entries of the given level are written
+func (l *Logger) Enabled(level Level) bool { return l != nil && level >= l.level }
+
+// Debug writes an entry for information useful when troubleshooting
+func (l *Logger) Debug(msg string, kvs ...interface{}) { l.Log(LevelDebug, msg, kvs...) }
+
+// Info writes an entry for routine events
+func (l *Logger) Info(msg string, kvs ...interface{}) { l.Log(LevelInfo, msg, kvs...) }
+
+// Warn writes an entry for problems that the daemon recovered from
+func (l *Logger) Warn(msg string, kvs ...interface{}) { l.Log(LevelWarn, msg, kvs...) }
+
+// Error writes an entry for failed operations
+func (l *Logger) Error(msg string, kvs ...interface{}) { l.Log(LevelError, msg, kvs...) }
+
+// Log writes an entry with the given level, message, and key-value pairs
+func (l *Logger) Log(level Level, msg string, kvs ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	var fields = append(append([]interface{}{
+		"time", time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		"level", level.String(),
+		"msg", msg,
+	}, l.fields...), kvs...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "(MISSING)")
+	}
+
+	var entry []byte
+	if l.json {
+		entry = formatJSON(fields)
+	} else {
+		entry = formatText(fields)
+	}
+	l.mux.Lock()
+	l.out.Write(entry)
+	l.mux.Unlock()
+}
+
+// formatText formats the given key-value pairs as a line of key=value pairs,
+// quoting values where needed
+func formatText(fields []interface{}) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(fmt.Sprint(fields[i]))
+		buf.WriteByte('=')
+		var value = formatValue(fields[i+1])
+		if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(value)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// formatJSON formats the given key-value pairs as a line containing a JSON
+// object, keeping the order of the pairs
+func formatJSON(fields []interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(fmt.Sprint(fields[i]))
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		var value []byte
+		switch v := fields[i+1].(type) {
+		case error, time.Duration:
+			value, _ = json.Marshal(formatValue(v))
+		default:
+			var err error
+			if value, err = json.Marshal(v); err != nil {
+				value, _ = json.Marshal(formatValue(v))
+			}
+		}
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// formatValue returns the string representation of the given value
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		if v == nil {
+			return "<nil>"
+		}
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"warning", LevelWarn, false},
+		{"error", LevelError, false},
+		{"loud", LevelInfo, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.name)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		var l = NewLogger(LoggerOptions{Writer: &buf, Level: LevelInfo}).With("project", "wow")
+		l.Debug("hidden")
+		l.Info("deploy started", "commit", "abcde", "note", "two words")
+		l.Error("deploy failed", "error", errors.New("oh no"), "duration", time.Second)
+
+		var lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.Contains(t, lines[0], ` level=info msg="deploy started" project=wow commit=abcde note="two words"`)
+			assert.Contains(t, lines[1], ` level=error msg="deploy failed" project=wow error="oh no" duration=1s`)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		var l = NewLogger(LoggerOptions{Writer: &buf, Level: LevelDebug, Format: FormatJSON})
+		l.Debug("request completed", "status", 200, "error", errors.New("oh no"), "odd")
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "debug", entry["level"])
+		assert.Equal(t, "request completed", entry["msg"])
+		assert.Equal(t, float64(200), entry["status"])
+		assert.Equal(t, "oh no", entry["error"])
+		assert.Equal(t, "(MISSING)", entry["odd"])
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var l *Logger
+		assert.False(t, l.Enabled(LevelError))
+		l.With("project", "wow").Error("discarded")
+	})
+}
+
+func TestFilterLevel(t *testing.T) {
+	var logs = strings.Join([]string{
+		`2020-01-01T00:00:00Z time=2020-01-01T00:00:00.000Z level=debug msg=hidden`,
+		`2020-01-01T00:00:00Z time=2020-01-01T00:00:00.000Z level=warn msg="disk space low"`,
+		`2020-01-01T00:00:00Z {"time":"2020-01-01T00:00:00.000Z","level":"error","msg":"deploy failed"}`,
+		`2020-01-01T00:00:00Z Cloning branch master...`,
+	}, "\n")
+
+	filtered, err := ioutil.ReadAll(FilterLevel(strings.NewReader(logs), LevelWarn))
+	assert.NoError(t, err)
+	var lines = strings.Split(strings.TrimSpace(string(filtered)), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], "disk space low")
+		assert.Contains(t, lines[1], "deploy failed")
+	}
+
+	filtered, err = ioutil.ReadAll(FilterLevel(strings.NewReader(logs), LevelInfo))
+	assert.NoError(t, err)
+	assert.Contains(t, string(filtered), "Cloning branch master")
+	assert.NotContains(t, string(filtered), "hidden")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/crypto"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/daemon"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
 )
 
@@ -30,35 +31,51 @@ Example:
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var conf = cfg.New()
+		if err := conf.Load(); err != nil {
+			log.NewLogger(log.LoggerOptions{}).Error("failed to load configuration", "error", err)
+			return
+		}
+		var logger = log.NewLogger(log.LoggerOptions{
+			Writer: os.Stdout,
+			Level:  conf.Logging.Level,
+			Format: conf.Logging.Format,
+		})
 
 		// Init webhook secret
 		var webhookSecret, _ = cmd.Flags().GetString("webhook.secret")
 		conf.WebhookSecret = webhookSecret
 
-		// Set up deployment
-		var projectDatabasePath = path.Join(conf.DataDirectory, "project.db")
+		// Set up deployments - each project gets its own directories, database,
+		// and builder
+		var projectDatabaseDir = path.Join(conf.DataDirectory, "projects")
 		var projectDatabaseKeypath = path.Join(conf.SecretsDirectory, "db.key")
-		deployment, err := project.NewDeployment(
-			conf.ProjectDirectory,
-			conf.PersistDirectory,
-			projectDatabasePath,
-			projectDatabaseKeypath,
-			build.NewBuilder(*conf, containers.StopActiveContainers))
-		if err != nil {
-			println(err.Error())
+		if err := os.MkdirAll(projectDatabaseDir, os.ModePerm); err != nil {
+			logger.Error("failed to create project database directory", "error", err)
 			return
 		}
+		var deployments = project.NewRegistry(func(name string) (project.Deployer, error) {
+			return project.NewDeployment(
+				path.Join(conf.ProjectDirectory, name),
+				path.Join(conf.PersistDirectory, name),
+				path.Join(projectDatabaseDir, name+".db"),
+				projectDatabaseKeypath,
+				build.NewBuilder(*conf, containers.ProjectContainerStopper(name),
+					logger.With("component", "build")),
+				logger.With("component", "project"))
+		})
 
 		// Initialize daemon
-		server, err := daemon.New(Version, *conf, deployment)
+		server, err := daemon.New(Version, *conf, deployments, logger)
 		if err != nil {
-			println(err.Error())
+			logger.Error("failed to initialize daemon", "error", err)
 			return
 		}
 		defer server.Close()
 
 		var port, _ = cmd.Flags().GetString("port")
-		println(server.Run(args[0], port))
+		if err := server.Run(args[0], port); err != nil {
+			logger.Error("daemon stopped", "error", err)
+		}
 	},
 }
 
@@ -0,0 +1,371 @@
+package project
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	units "github.com/docker/go-units"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+const (
+	// DefaultMaxBuildLogSize is the most output, in bytes, saved for each
+	// build
+	DefaultMaxBuildLogSize = 10 * 1024 * 1024
+	// DefaultMaxBuilds is the number of builds of each project to keep logs of
+	DefaultMaxBuilds = 50
+)
+
+// Build triggers
+const (
+	BuildTriggerUp       = "up"
+	BuildTriggerWebhook  = "webhook"
+	BuildTriggerRollback = "rollback"
+	BuildTriggerPreview  = "preview"
+)
+
+// Build statuses
+const (
+	BuildRunning     = "running"
+	BuildSucceeded   = "succeeded"
+	BuildFailed      = "failed"
+	BuildInterrupted = "interrupted"
+)
+
+// ErrBuildNotFound is returned when a requested build does not exist
+var ErrBuildNotFound = errors.New("build not found")
+
+// validBuildID matches IDs generated by newBuildID
+var validBuildID = regexp.MustCompile(`^\d{8}-\d{6}-[0-9a-f]{4}$`)
+
+// newBuildID generates an ID for a build started at the given time. IDs sort
+// in the order builds were started.
+func newBuildID(started time.Time) string {
+	var suffix = make([]byte, 2)
+	rand.Read(suffix)
+	return started.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// BuildLogs saves the output of builds to disk, compressed, with one directory
+// per project. Only the given number of most recent builds of each project
+// are kept.
+type BuildLogs struct {
+	dir       string
+	maxSize   int64
+	maxBuilds int
+
+	mux    sync.Mutex
+	active map[string]*BuildLog
+}
+
+// NewBuildLogs creates a store of build logs in the given directory
+func NewBuildLogs(dir string, maxSize int64, maxBuilds int) *BuildLogs {
+	return &BuildLogs{
+		dir:       dir,
+		maxSize:   maxSize,
+		maxBuilds: maxBuilds,
+		active:    make(map[string]*BuildLog),
+	}
+}
+
+// Start begins recording the output of a build of the given project
+func (b *BuildLogs) Start(project, trigger string) (*BuildLog, error) {
+	var dir = filepath.Join(b.dir, project)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	var started = time.Now()
+	var record = api.BuildRecord{
+		ID:        newBuildID(started),
+		Project:   project,
+		Trigger:   trigger,
+		Status:    BuildRunning,
+		StartedAt: started,
+	}
+	file, err := os.Create(filepath.Join(dir, record.ID+".log.gz"))
+	if err != nil {
+		return nil, err
+	}
+	var l = &BuildLog{
+		store:   b,
+		record:  record,
+		file:    file,
+		gz:      gzip.NewWriter(file),
+		updated: make(chan struct{}),
+	}
+	if err := b.save(record); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	b.mux.Lock()
+	b.active[record.ID] = l
+	b.mux.Unlock()
+
+	if err := b.prune(project); err != nil {
+		fmt.Fprintf(l, "warning: failed to remove old build logs: %s\n", err.Error())
+	}
+	return l, nil
+}
+
+// List returns records of builds of the given project, most recent first
+func (b *BuildLogs) List(project string) ([]api.BuildRecord, error) {
+	files, err := ioutil.ReadDir(filepath.Join(b.dir, project))
+	if os.IsNotExist(err) {
+		return []api.BuildRecord{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var records = make([]api.BuildRecord, 0, len(files))
+	for _, f := range files {
+		var id = strings.TrimSuffix(f.Name(), ".json")
+		if id == f.Name() || !validBuildID.MatchString(id) {
+			continue
+		}
+		record, err := b.Get(project, id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID > records[j].ID })
+	return records, nil
+}
+
+// Get returns the record of the given build of the given project
+func (b *BuildLogs) Get(project, id string) (api.BuildRecord, error) {
+	var record api.BuildRecord
+	if !validBuildID.MatchString(id) {
+		return record, ErrBuildNotFound
+	}
+	b.mux.Lock()
+	l, active := b.active[id]
+	b.mux.Unlock()
+	if active && l.record.Project == project {
+		l.mux.Lock()
+		defer l.mux.Unlock()
+		return l.record, nil
+	}
+
+	bytes, err := ioutil.ReadFile(filepath.Join(b.dir, project, id+".json"))
+	if os.IsNotExist(err) {
+		return record, ErrBuildNotFound
+	} else if err != nil {
+		return record, err
+	}
+	if err := json.Unmarshal(bytes, &record); err != nil {
+		return record, fmt.Errorf("failed to read build record: %s", err.Error())
+	}
+
+	// Builds that are not active were cut off when the daemon stopped
+	if record.Status == BuildRunning {
+		record.Status = BuildInterrupted
+	}
+	return record, nil
+}
+
+// Open returns a reader of the output of the given build of the given project.
+// If follow is set and the build is still running, reads block until more
+// output is available, the build finishes, or the given context is cancelled.
+func (b *BuildLogs) Open(ctx context.Context, project, id string, follow bool) (io.ReadCloser, error) {
+	if _, err := b.Get(project, id); err != nil {
+		return nil, err
+	}
+
+	b.mux.Lock()
+	l, active := b.active[id]
+	b.mux.Unlock()
+	if active && l.record.Project == project {
+		return &buildLogReader{ctx: ctx, log: l, follow: follow}, nil
+	}
+
+	file, err := os.Open(filepath.Join(b.dir, project, id+".log.gz"))
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read build log: %s", err.Error())
+	}
+	return &gzipFileReader{Reader: gz, file: file}, nil
+}
+
+// save writes the given build record to disk
+func (b *BuildLogs) save(record api.BuildRecord) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(b.dir, record.Project, record.ID+".json"), bytes, 0600)
+}
+
+// prune removes logs of all but the most recent builds of the given project
+func (b *BuildLogs) prune(project string) error {
+	if b.maxBuilds <= 0 {
+		return nil
+	}
+	records, err := b.List(project)
+	if err != nil || len(records) <= b.maxBuilds {
+		return err
+	}
+	for _, r := range records[b.maxBuilds:] {
+		if r.Status == BuildRunning {
+			continue
+		}
+		for _, ext := range []string{".log.gz", ".json"} {
+			if err := os.Remove(filepath.Join(b.dir, project, r.ID+ext)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BuildLog records the output of a build. It is safe for concurrent use.
+type BuildLog struct {
+	store  *BuildLogs
+	record api.BuildRecord
+
+	mux  sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+	err  error
+	done bool
+
+	// output is kept in memory while the build is running so that it can be
+	// followed, and updated is closed and replaced whenever it changes
+	output  []byte
+	updated chan struct{}
+}
+
+// ID returns the ID of the build
+func (l *BuildLog) ID() string { return l.record.ID }
+
+// Write records the given build output. Output beyond the size limit of the
+// build log is discarded, and failures to save output are reported when the
+// build finishes, so that writes never fail the build.
+func (l *BuildLog) Write(p []byte) (int, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.done || l.record.Truncated {
+		return len(p), nil
+	}
+
+	var chunk = p
+	if remaining := l.store.maxSize - int64(len(l.output)); int64(len(chunk)) > remaining {
+		chunk = chunk[:remaining]
+		l.record.Truncated = true
+	}
+	if l.record.Truncated {
+		chunk = append(chunk[:len(chunk):len(chunk)], fmt.Sprintf(
+			"\n[output truncated - build log size limit of %s reached]\n",
+			units.BytesSize(float64(l.store.maxSize)))...)
+	}
+	if l.err == nil {
+		_, l.err = l.gz.Write(chunk)
+	}
+	l.output = append(l.output, chunk...)
+	l.record.Size = int64(len(l.output))
+
+	close(l.updated)
+	l.updated = make(chan struct{})
+	return len(p), nil
+}
+
+// Finish records the outcome of the build, given the deployed commit and the
+// error the build failed with, if any, and saves the build log
+func (l *BuildLog) Finish(commit string, buildErr error) error {
+	l.mux.Lock()
+	if l.done {
+		l.mux.Unlock()
+		return nil
+	}
+	l.done = true
+	var finished = time.Now()
+	l.record.FinishedAt = &finished
+	l.record.CommitHash = commit
+	l.record.Status = BuildSucceeded
+	if buildErr != nil {
+		l.record.Status = BuildFailed
+		l.record.Error = buildErr.Error()
+	}
+	var err = l.err
+	if closeErr := l.gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	if saveErr := l.store.save(l.record); err == nil {
+		err = saveErr
+	}
+	close(l.updated)
+	l.mux.Unlock()
+
+	l.store.mux.Lock()
+	delete(l.store.active, l.record.ID)
+	l.store.mux.Unlock()
+	return err
+}
+
+// buildLogReader reads the output of a build that is still running
+type buildLogReader struct {
+	ctx    context.Context
+	log    *BuildLog
+	offset int
+	follow bool
+}
+
+func (r *buildLogReader) Read(p []byte) (int, error) {
+	for {
+		r.log.mux.Lock()
+		if r.offset < len(r.log.output) {
+			var n = copy(p, r.log.output[r.offset:])
+			r.offset += n
+			r.log.mux.Unlock()
+			return n, nil
+		}
+		if r.log.done || !r.follow {
+			r.log.mux.Unlock()
+			return 0, io.EOF
+		}
+		var updated = r.log.updated
+		r.log.mux.Unlock()
+
+		select {
+		case <-updated:
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+}
+
+func (r *buildLogReader) Close() error { return nil }
+
+// gzipFileReader reads a compressed file, closing the file when closed
+type gzipFileReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *gzipFileReader) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
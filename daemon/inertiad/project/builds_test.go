@@ -0,0 +1,181 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newBuildID(t *testing.T) {
+	var started = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	var id = newBuildID(started)
+	assert.True(t, validBuildID.MatchString(id))
+	assert.True(t, strings.HasPrefix(id, "20200102-030405-"))
+	assert.False(t, validBuildID.MatchString("../../etc/passwd"))
+}
+
+func TestBuildLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-builds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	var builds = NewBuildLogs(dir, DefaultMaxBuildLogSize, DefaultMaxBuilds)
+
+	// record a failed build
+	l, err := builds.Start("wow", BuildTriggerUp)
+	require.NoError(t, err)
+	fmt.Fprintln(l, "building project")
+	record, err := builds.Get("wow", l.ID())
+	require.NoError(t, err)
+	assert.Equal(t, BuildRunning, record.Status)
+	assert.Equal(t, BuildTriggerUp, record.Trigger)
+	require.NoError(t, l.Finish("abcde", errors.New("build failed")))
+
+	record, err = builds.Get("wow", l.ID())
+	require.NoError(t, err)
+	assert.Equal(t, BuildFailed, record.Status)
+	assert.Equal(t, "build failed", record.Error)
+	assert.Equal(t, "abcde", record.CommitHash)
+	assert.NotNil(t, record.FinishedAt)
+	assert.Equal(t, int64(len("building project\n")), record.Size)
+
+	// output is compressed on disk and readable after the build
+	_, err = os.Stat(filepath.Join(dir, "wow", l.ID()+".log.gz"))
+	assert.NoError(t, err)
+	r, err := builds.Open(context.Background(), "wow", l.ID(), false)
+	require.NoError(t, err)
+	output, err := ioutil.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "building project\n", string(output))
+
+	// builds are only found under their own project
+	_, err = builds.Get("other", l.ID())
+	assert.Equal(t, ErrBuildNotFound, err)
+	_, err = builds.Open(context.Background(), "wow", "../wow", false)
+	assert.Equal(t, ErrBuildNotFound, err)
+
+	list, err := builds.List("wow")
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	list, err = builds.List("other")
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestBuildLogs_truncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-builds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	var builds = NewBuildLogs(dir, 10, DefaultMaxBuilds)
+
+	l, err := builds.Start("wow", BuildTriggerWebhook)
+	require.NoError(t, err)
+	n, err := l.Write([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, 16, n)
+	l.Write([]byte("more output"))
+	require.NoError(t, l.Finish("abcde", nil))
+
+	record, err := builds.Get("wow", l.ID())
+	require.NoError(t, err)
+	assert.Equal(t, BuildSucceeded, record.Status)
+	assert.True(t, record.Truncated)
+
+	r, err := builds.Open(context.Background(), "wow", l.ID(), false)
+	require.NoError(t, err)
+	output, err := ioutil.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(output), "0123456789\n[output truncated"))
+	assert.NotContains(t, string(output), "more output")
+}
+
+func TestBuildLogs_prune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-builds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	var builds = NewBuildLogs(dir, DefaultMaxBuildLogSize, 2)
+
+	var ids = make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		l, err := builds.Start("wow", BuildTriggerUp)
+		require.NoError(t, err)
+		require.NoError(t, l.Finish("abcde", nil))
+		ids = append(ids, l.ID())
+		// build IDs are ordered by the second they were started in
+		time.Sleep(time.Second)
+	}
+
+	list, err := builds.List("wow")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, ids[2], list[0].ID)
+	assert.Equal(t, ids[1], list[1].ID)
+	_, err = builds.Get("wow", ids[0])
+	assert.Equal(t, ErrBuildNotFound, err)
+}
+
+func TestBuildLogs_follow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-builds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	var builds = NewBuildLogs(dir, DefaultMaxBuildLogSize, DefaultMaxBuilds)
+
+	l, err := builds.Start("wow", BuildTriggerUp)
+	require.NoError(t, err)
+	fmt.Fprintln(l, "step 1")
+
+	// without follow, only output so far is read
+	r, err := builds.Open(context.Background(), "wow", l.ID(), false)
+	require.NoError(t, err)
+	output, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "step 1\n", string(output))
+
+	// with follow, output is read until the build finishes
+	r, err = builds.Open(context.Background(), "wow", l.ID(), true)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprintln(l, "step 2")
+		l.Finish("abcde", nil)
+	}()
+	output, err = ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "step 1\nstep 2\n", string(output))
+
+	// following is stopped when the context is cancelled
+	l, err = builds.Start("wow", BuildTriggerUp)
+	require.NoError(t, err)
+	defer l.Finish("", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err = builds.Open(ctx, "wow", l.ID(), true)
+	require.NoError(t, err)
+	cancel()
+	_, err = ioutil.ReadAll(r)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestBuildLogs_interrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-builds")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := NewBuildLogs(dir, DefaultMaxBuildLogSize, DefaultMaxBuilds).Start("wow", BuildTriggerUp)
+	require.NoError(t, err)
+	defer l.Finish("", nil)
+
+	// a new store, as after a daemon restart, does not know of the build
+	record, err := NewBuildLogs(dir, DefaultMaxBuildLogSize, DefaultMaxBuilds).Get("wow", l.ID())
+	require.NoError(t, err)
+	assert.Equal(t, BuildInterrupted, record.Status)
+}
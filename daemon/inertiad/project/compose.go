@@ -0,0 +1,63 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+var (
+	// validComposeProjectName matches project names accepted by docker-compose
+	validComposeProjectName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	// validComposeProfile matches profile names accepted by docker-compose
+	validComposeProfile = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// ValidateCompose checks if the given docker-compose configuration is valid
+func ValidateCompose(compose *api.Compose) error {
+	if compose == nil {
+		return nil
+	}
+	for _, f := range compose.Files {
+		if f == "" {
+			return errors.New("compose files must not be empty")
+		}
+		var clean = filepath.Clean(f)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("compose file '%s' must be a path within the project", f)
+		}
+	}
+	for _, p := range compose.Profiles {
+		if !validComposeProfile.MatchString(p) {
+			return fmt.Errorf("invalid compose profile '%s'", p)
+		}
+	}
+	if compose.ProjectName != "" && !validComposeProjectName.MatchString(compose.ProjectName) {
+		return fmt.Errorf("invalid compose project name '%s' - compose project names may "+
+			"only contain lowercase alphanumeric characters, '_', and '-'", compose.ProjectName)
+	}
+	return nil
+}
+
+// composeSummary describes the given docker-compose configuration, if there is
+// one
+func composeSummary(compose *api.Compose) string {
+	if compose == nil {
+		return ""
+	}
+	var parts []string
+	if len(compose.Files) > 0 {
+		parts = append(parts, "files "+strings.Join(compose.Files, ", "))
+	}
+	if len(compose.Profiles) > 0 {
+		parts = append(parts, "profiles "+strings.Join(compose.Profiles, ", "))
+	}
+	if compose.ProjectName != "" {
+		parts = append(parts, "project name "+compose.ProjectName)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,53 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+func TestValidateCompose(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose *api.Compose
+		wantErr bool
+	}{
+		{"no configuration", nil, false},
+		{"files", &api.Compose{Files: []string{"docker-compose.yml", "deploy/staging.yml"}}, false},
+		{"empty file", &api.Compose{Files: []string{""}}, true},
+		{"absolute file", &api.Compose{Files: []string{"/etc/docker-compose.yml"}}, true},
+		{"file outside project", &api.Compose{Files: []string{"../docker-compose.yml"}}, true},
+		{"profiles", &api.Compose{Profiles: []string{"workers", "debug.tools"}}, false},
+		{"invalid profile", &api.Compose{Profiles: []string{"-workers"}}, true},
+		{"project name", &api.Compose{ProjectName: "myproject_staging"}, false},
+		{"invalid project name", &api.Compose{ProjectName: "MyProject"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateCompose(tt.compose); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCompose() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeployment_SetConfig_compose(t *testing.T) {
+	var d = &Deployment{project: "myproject"}
+	d.SetConfig(DeploymentConfig{Compose: &api.Compose{
+		Files:       []string{"docker-compose.yml", "docker-compose.staging.yml"},
+		Profiles:    []string{"workers"},
+		ProjectName: "staging",
+	}})
+	assert.Equal(t, "staging", d.composeProject())
+	conf, _ := d.GetBuildConfiguration()
+	assert.Equal(t, []string{"docker-compose.yml", "docker-compose.staging.yml"}, conf.ComposeFiles)
+	assert.Equal(t, []string{"workers"}, conf.ComposeProfiles)
+	assert.Equal(t, "staging", conf.ComposeProjectName)
+
+	// containers of the previous compose project must still be stopped
+	d.SetConfig(DeploymentConfig{})
+	assert.Equal(t, "", d.composeProject())
+	assert.Equal(t, "staging", d.staleComposeProject)
+}
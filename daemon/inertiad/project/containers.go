@@ -0,0 +1,84 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+)
+
+// ContainerAction denotes an operation on an individual project container
+type ContainerAction string
+
+const (
+	// ContainerRestart restarts a container
+	ContainerRestart ContainerAction = "restart"
+	// ContainerStop stops a container
+	ContainerStop ContainerAction = "stop"
+	// ContainerStart starts a stopped container
+	ContainerStart ContainerAction = "start"
+
+	containerStopTimeout = 10 * time.Second
+)
+
+// ErrContainerNotFound indicates that a container does not belong to a project
+var ErrContainerNotFound = errors.New("container not found in project")
+
+// ControlContainer restarts, stops, or starts the named project container.
+// Containers used to build the project cannot be controlled, and containers
+// stopped this way are not restarted by the deployment's restart policy.
+func (d *Deployment) ControlContainer(cli *docker.Client, name string, action ContainerAction) error {
+	var ctx = context.Background()
+	c, err := d.getContainer(ctx, cli, name)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimPrefix(c.Names[0], "/")
+
+	var timeout = containerStopTimeout
+	switch action {
+	case ContainerRestart:
+		if c.State == "running" {
+			d.restarts.expectExit(name)
+		}
+		return cli.ContainerRestart(ctx, c.ID, &timeout)
+	case ContainerStop:
+		if c.State != "running" {
+			return nil
+		}
+		d.restarts.expectExit(name)
+		return cli.ContainerStop(ctx, c.ID, &timeout)
+	case ContainerStart:
+		return cli.ContainerStart(ctx, c.ID, types.ContainerStartOptions{})
+	default:
+		return fmt.Errorf("unknown container action '%s'", action)
+	}
+}
+
+// getContainer retrieves the named project container, including stopped ones.
+// The build container is ignored, as it is by GetStatus, as well as any other
+// intermediary containers created by Inertia.
+func (d *Deployment) getContainer(ctx context.Context, cli *docker.Client, name string) (types.Container, error) {
+	list, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return types.Container{}, err
+	}
+	name = strings.TrimPrefix(name, "/")
+	for _, c := range list {
+		if !containers.BelongsToProject(c.Labels, d.project, d.composeProject()) || c.Labels[containers.LabelStage] != "" {
+			continue
+		}
+		for _, n := range c.Names {
+			if strings.TrimPrefix(n, "/") == name {
+				return c, nil
+			}
+		}
+	}
+	return types.Container{}, ErrContainerNotFound
+}
@@ -17,8 +17,18 @@ var (
 	// database buckets
 	envVariableBucket      = []byte("envVariables")
 	deployedProjectsBucket = []byte("deployedProjects")
+	gitCredentialsBucket   = []byte("gitCredentials")
+	configBucket           = []byte("config")
+
+	// gitCredentialsKey is the key git credentials are stored under
+	gitCredentialsKey = []byte("credentials")
+	// configKey is the key the deployment configuration is stored under
+	configKey = []byte("deployment")
 )
 
+// buildDataKeyFormat is used to generate keys for build metadata records
+const buildDataKeyFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
 // DeploymentDataManager stores persistent deployment configuration
 type DeploymentDataManager struct {
 	// db is a boltdb database, which is an embedded
@@ -60,6 +70,16 @@ func NewDataManager(dbPath string, keyPath string) (*DeploymentDataManager, erro
 		if err != nil {
 			return fmt.Errorf("failed to created deployed projects bucket: %s", err.Error())
 		}
+
+		_, err = tx.CreateBucketIfNotExists(gitCredentialsBucket)
+		if err != nil {
+			return fmt.Errorf("failed to created git credentials bucket: %s", err.Error())
+		}
+
+		_, err = tx.CreateBucketIfNotExists(configBucket)
+		if err != nil {
+			return fmt.Errorf("failed to created config bucket: %s", err.Error())
+		}
 		return err
 	}); err != nil {
 		return nil, fmt.Errorf("failed to instantiate database: %s", err.Error())
@@ -147,29 +167,175 @@ func (c *DeploymentDataManager) GetEnvVariables(decrypt bool) ([]string, error)
 	return envs, err
 }
 
-// AddProjectBuildData stores and tracks metadata from successful builds
-// TODO: Change name, error check, only insert project mdata inside private helper 'update build'
-func (c *DeploymentDataManager) AddProjectBuildData(projectName string, mdata DeploymentMetadata) error {
-	// encode metadata so it can be stored as byte array
-	encodedMdata, err := json.Marshal(mdata)
+// SetGitCredentials encrypts and stores the credentials used to access HTTPS
+// git remotes, replacing any existing credentials
+func (c *DeploymentDataManager) SetGitCredentials(creds GitCredentials) error {
+	if creds.Username == "" || creds.Token == "" {
+		return errors.New("a username and token are required")
+	}
+	bytes, err := json.Marshal(creds)
 	if err != nil {
-		return fmt.Errorf("failure encrypting metadata: %s", err.Error())
+		return err
 	}
-	err = c.db.Update(func(tx *bolt.Tx) error {
-		depProjectsBkt := tx.Bucket(deployedProjectsBucket)
-		// if bkt with project name doesnt exist create new bkt, otherwise update existing bucket
-		if projectBkt := depProjectsBkt.Bucket([]byte(projectName)); projectBkt == nil {
-			projectBkt, err := depProjectsBkt.CreateBucket([]byte(projectName))
-			if err != nil {
-				return fmt.Errorf("failure creating project bkt: %s", err.Error())
+	encrypted, err := crypto.Encrypt(c.symmetricKey, bytes)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(gitCredentialsBucket).Put(gitCredentialsKey, encrypted)
+	})
+}
+
+// GetGitCredentials retrieves the stored git credentials, if there are any
+func (c *DeploymentDataManager) GetGitCredentials() (*GitCredentials, error) {
+	var encrypted []byte
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(gitCredentialsBucket).Get(gitCredentialsKey); v != nil {
+			encrypted = append([]byte{}, v...)
+		}
+		return nil
+	}); err != nil || encrypted == nil {
+		return nil, err
+	}
+
+	decrypted, err := crypto.Decrypt(c.symmetricKey, encrypted)
+	if err != nil {
+		// If decrypt fails, key is no longer valid - remove credentials
+		c.RemoveGitCredentials()
+		return nil, fmt.Errorf("failed to decrypt git credentials: %s", err.Error())
+	}
+	var creds GitCredentials
+	if err := json.Unmarshal(decrypted, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// RemoveGitCredentials removes the stored git credentials
+func (c *DeploymentDataManager) RemoveGitCredentials() error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(gitCredentialsBucket).Delete(gitCredentialsKey)
+	})
+}
+
+// SaveConfig encrypts and stores the given deployment configuration, replacing
+// any existing configuration. The configuration is encrypted since it may
+// contain credentials, such as registry passwords.
+func (c *DeploymentDataManager) SaveConfig(cfg DeploymentConfig) error {
+	bytes, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	encrypted, err := crypto.Encrypt(c.symmetricKey, bytes)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(configBucket).Put(configKey, encrypted)
+	})
+}
+
+// GetConfig retrieves the stored deployment configuration, if there is one
+func (c *DeploymentDataManager) GetConfig() (*DeploymentConfig, error) {
+	var encrypted []byte
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(configBucket).Get(configKey); v != nil {
+			encrypted = append([]byte{}, v...)
+		}
+		return nil
+	}); err != nil || encrypted == nil {
+		return nil, err
+	}
+
+	decrypted, err := crypto.Decrypt(c.symmetricKey, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt deployment configuration: %s", err.Error())
+	}
+	var cfg DeploymentConfig
+	if err := json.Unmarshal(decrypted, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// DeployedProjectNames lists the projects with build data in the database at
+// the given path, without setting up a data manager. The database is opened
+// read-only.
+func DeployedProjectNames(dbPath string) ([]string, error) {
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database at '%s': %s", dbPath, err.Error())
+	}
+	defer db.Close()
+	var names []string
+	err = db.View(func(tx *bolt.Tx) error {
+		var bkt = tx.Bucket(deployedProjectsBucket)
+		if bkt == nil {
+			return nil
+		}
+		return bkt.ForEach(func(k, v []byte) error {
+			// project build data is kept in nested buckets, which have no value
+			if v == nil {
+				names = append(names, string(k))
 			}
+			return nil
+		})
+	})
+	return names, err
+}
 
-			if err := projectBkt.Put([]byte(time.Now().String()), encodedMdata); err != nil {
-				return fmt.Errorf("failure inserting project metadata: %s", err.Error())
+// CopySecrets replaces the environment variables and git credentials stored in
+// the given data manager with the ones stored in this one. Encrypted values are
+// copied as-is, so both data managers must use the same key.
+func (c *DeploymentDataManager) CopySecrets(dst *DeploymentDataManager) error {
+	var buckets = [][]byte{envVariableBucket, gitCredentialsBucket}
+	var values = make([]map[string][]byte, len(buckets))
+	if err := c.db.View(func(tx *bolt.Tx) error {
+		for i, bucket := range buckets {
+			values[i] = make(map[string][]byte)
+			if err := tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+				values[i][string(k)] = append([]byte{}, v...)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return dst.db.Update(func(tx *bolt.Tx) error {
+		for i, bucket := range buckets {
+			if err := tx.DeleteBucket(bucket); err != nil {
+				return err
+			}
+			b, err := tx.CreateBucket(bucket)
+			if err != nil {
+				return err
+			}
+			for k, v := range values[i] {
+				if err := b.Put([]byte(k), v); err != nil {
+					return err
+				}
 			}
 		}
 		return nil
 	})
+}
+
+// AddProjectBuildData stores and tracks metadata from successful builds
+func (c *DeploymentDataManager) AddProjectBuildData(projectName string, mdata DeploymentMetadata) error {
+	// if bkt with project name doesnt exist create new bkt, otherwise update
+	// existing bucket
+	if err := c.db.Update(func(tx *bolt.Tx) error {
+		depProjectsBkt := tx.Bucket(deployedProjectsBucket)
+		if _, err := depProjectsBkt.CreateBucketIfNotExists([]byte(projectName)); err != nil {
+			return fmt.Errorf("failure creating project bkt: %s", err.Error())
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
 	return c.UpdateProjectBuildData(projectName, mdata)
 }
 
@@ -184,8 +350,14 @@ func (c *DeploymentDataManager) UpdateProjectBuildData(projectName string,
 	return c.db.Update(func(tx *bolt.Tx) error {
 		depProjectBkt := tx.Bucket(deployedProjectsBucket)
 		projectBkt := depProjectBkt.Bucket([]byte(projectName))
+		if projectBkt == nil {
+			return fmt.Errorf("no build data found for project '%s'", projectName)
+		}
 
-		if err := projectBkt.Put([]byte(time.Now().String()), encodedMdata); err != nil {
+		// keys are fixed-width timestamps, so records are kept in the order
+		// they were deployed
+		key := []byte(time.Now().UTC().Format(buildDataKeyFormat))
+		if err := projectBkt.Put(key, encodedMdata); err != nil {
 			return fmt.Errorf("failure updating db with project metadata: %s", err.Error())
 		}
 		return nil
@@ -193,6 +365,28 @@ func (c *DeploymentDataManager) UpdateProjectBuildData(projectName string,
 
 }
 
+// GetProjectBuildData retrieves metadata of past builds of the given project,
+// most recent first
+func (c *DeploymentDataManager) GetProjectBuildData(projectName string) ([]DeploymentMetadata, error) {
+	var history = make([]DeploymentMetadata, 0)
+	err := c.db.View(func(tx *bolt.Tx) error {
+		projectBkt := tx.Bucket(deployedProjectsBucket).Bucket([]byte(projectName))
+		if projectBkt == nil {
+			return nil
+		}
+		cursor := projectBkt.Cursor()
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var mdata DeploymentMetadata
+			if err := json.Unmarshal(v, &mdata); err != nil {
+				return fmt.Errorf("failure decoding project metadata: %s", err.Error())
+			}
+			history = append(history, mdata)
+		}
+		return nil
+	})
+	return history, err
+}
+
 // GetNumOfDeployedProjects returns number of projects currently deployed
 func (c *DeploymentDataManager) GetNumOfDeployedProjects(projectName string) (int, error) {
 	var numBkts int
@@ -205,12 +399,22 @@ func (c *DeploymentDataManager) GetNumOfDeployedProjects(projectName string) (in
 	return numBkts, err
 }
 
+// Close releases the database, after which the data manager can no longer be
+// used
+func (c *DeploymentDataManager) Close() error {
+	return c.db.Close()
+}
+
 func (c *DeploymentDataManager) destroy() error {
 	return c.db.Update(func(tx *bolt.Tx) error {
-		if err := tx.DeleteBucket(envVariableBucket); err != nil {
-			return err
+		for _, bucket := range [][]byte{envVariableBucket, gitCredentialsBucket, configBucket} {
+			if err := tx.DeleteBucket(bucket); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(bucket); err != nil {
+				return err
+			}
 		}
-		_, err := tx.CreateBucket(envVariableBucket)
-		return err
+		return nil
 	})
 }
@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
 )
 
 func TestDataManager_EnvVariableOperations(t *testing.T) {
@@ -65,6 +68,135 @@ func TestDataManager_EnvVariableOperations(t *testing.T) {
 	}
 }
 
+func TestDataManager_GitCredentialsOperations(t *testing.T) {
+	dir := "./test_config"
+	err := os.Mkdir(dir, os.ModePerm)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Instantiate
+	c, err := NewDataManager(path.Join(dir, "deployment.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+
+	// Nothing configured
+	creds, err := c.GetGitCredentials()
+	assert.NoError(t, err)
+	assert.Nil(t, creds)
+
+	// Invalid credentials
+	assert.Error(t, c.SetGitCredentials(GitCredentials{Username: "bob"}))
+	assert.Error(t, c.SetGitCredentials(GitCredentials{Token: "sekret"}))
+
+	// Set and retrieve
+	assert.NoError(t, c.SetGitCredentials(GitCredentials{Username: "bob", Token: "sekret"}))
+	creds, err = c.GetGitCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, &GitCredentials{Username: "bob", Token: "sekret"}, creds)
+
+	// Replace
+	assert.NoError(t, c.SetGitCredentials(GitCredentials{Username: "x-access-token", Token: "rotated"}))
+	creds, err = c.GetGitCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", creds.Token)
+
+	// Remove
+	assert.NoError(t, c.RemoveGitCredentials())
+	creds, err = c.GetGitCredentials()
+	assert.NoError(t, err)
+	assert.Nil(t, creds)
+}
+
+func TestDataManager_ConfigOperations(t *testing.T) {
+	dir := "./test_config"
+	err := os.Mkdir(dir, os.ModePerm)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Instantiate
+	c, err := NewDataManager(path.Join(dir, "deployment.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+
+	// Nothing saved
+	cfg, err := c.GetConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	// Save and retrieve - notifiers are registered from the Slack URL, and are
+	// not saved themselves
+	var saved = DeploymentConfig{
+		ProjectName:          "myproject",
+		RemoteURL:            "git@github.com:ubclaunchpad/inertia.git",
+		Branch:               "master",
+		Image:                &api.Image{Name: "myimage", Username: "bob", Password: "sekret"},
+		SlackNotificationURL: "https://hooks.slack.com/services/sekret",
+		Notifiers:            notify.Notifiers{notify.NewSlackNotifier("https://hooks.slack.com/services/sekret")},
+	}
+	assert.NoError(t, c.SaveConfig(saved))
+	cfg, err = c.GetConfig()
+	assert.NoError(t, err)
+	saved.Notifiers = nil
+	assert.Equal(t, &saved, cfg)
+
+	// Cleared along with the rest of the deployment
+	assert.NoError(t, c.destroy())
+	cfg, err = c.GetConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestDeployedProjectNames(t *testing.T) {
+	dir := "./test_config"
+	err := os.Mkdir(dir, os.ModePerm)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewDataManager(path.Join(dir, "deployment.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+	assert.NoError(t, c.AddProjectBuildData("projectA", DeploymentMetadata{Hash: "first"}))
+	assert.NoError(t, c.Close())
+
+	names, err := DeployedProjectNames(path.Join(dir, "deployment.db"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"projectA"}, names)
+
+	_, err = DeployedProjectNames(path.Join(dir, "missing.db"))
+	assert.Error(t, err)
+}
+
+func TestDataManager_CopySecrets(t *testing.T) {
+	dir := "./test_config"
+	err := os.Mkdir(dir, os.ModePerm)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src, err := NewDataManager(path.Join(dir, "src.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+	dst, err := NewDataManager(path.Join(dir, "dst.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+	defer dst.Close()
+
+	assert.NoError(t, src.AddEnvVariable("PUBLIC", "hello", false))
+	assert.NoError(t, src.AddEnvVariable("SECRET", "sekret", true))
+	assert.NoError(t, src.SetGitCredentials(GitCredentials{Username: "bob", Token: "sekret"}))
+	assert.NoError(t, dst.AddEnvVariable("STALE", "old", false))
+
+	// Existing secrets are replaced, and encrypted values stay encrypted
+	assert.NoError(t, src.CopySecrets(dst))
+	env, err := dst.GetEnvVariables(false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"PUBLIC=hello", "SECRET=[ENCRYPTED]"}, env)
+	env, err = dst.GetEnvVariables(true)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"PUBLIC=hello", "SECRET=sekret"}, env)
+	creds, err := dst.GetGitCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, &GitCredentials{Username: "bob", Token: "sekret"}, creds)
+
+	// Closed data managers can't be used
+	assert.NoError(t, src.Close())
+	assert.Error(t, src.CopySecrets(dst))
+}
+
 func TestDataManager_ProjectBuildDataOperations(t *testing.T) {
 	type args struct {
 		projectName string
@@ -76,7 +208,7 @@ func TestDataManager_ProjectBuildDataOperations(t *testing.T) {
 		args    args
 		wantErr bool
 	}{
-		{"valid project build", args{"projectB", DeploymentMetadata{"hash", "ID", "status", "time"}, 2}, false},
+		{"valid project build", args{"projectB", DeploymentMetadata{Hash: "hash", ContainerID: "ID", ContainerStatus: "status", StartedAt: "time"}, 2}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -108,6 +240,33 @@ func TestDataManager_ProjectBuildDataOperations(t *testing.T) {
 	}
 }
 
+func TestDataManager_GetProjectBuildData(t *testing.T) {
+	dir := "./test_config"
+	err := os.Mkdir(dir, os.ModePerm)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewDataManager(path.Join(dir, "deployment.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+
+	// No history for unknown project
+	history, err := c.GetProjectBuildData("projectA")
+	assert.NoError(t, err)
+	assert.Len(t, history, 0)
+
+	// Records should be returned most recent first
+	for _, hash := range []string{"first", "second", "third"} {
+		assert.NoError(t, c.AddProjectBuildData("projectA", DeploymentMetadata{Hash: hash}))
+	}
+	history, err = c.GetProjectBuildData("projectA")
+	assert.NoError(t, err)
+	if assert.Len(t, history, 3) {
+		assert.Equal(t, "third", history[0].Hash)
+		assert.Equal(t, "second", history[1].Hash)
+		assert.Equal(t, "first", history[2].Hash)
+	}
+}
+
 func TestDataManager_destroy(t *testing.T) {
 	dir := "./test_config"
 	err := os.Mkdir(dir, os.ModePerm)
@@ -7,13 +7,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	docker "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 
 	"github.com/ubclaunchpad/inertia/api"
@@ -22,27 +28,44 @@ import (
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/crypto"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/git"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/log"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
 )
 
 // Deployer manages the deployed user project
 type Deployer interface {
-	Deploy(*docker.Client, io.Writer, DeployOptions) (func() error, error)
+	Deploy(context.Context, *docker.Client, io.Writer, DeployOptions) (func() error, error)
 	Initialize(cfg DeploymentConfig, out io.Writer) error
+	Load() error
 	Down(*docker.Client, io.Writer) error
 	Destroy(*docker.Client, io.Writer) error
 	Prune(*docker.Client, io.Writer) error
 	GetStatus(*docker.Client) (api.DeploymentStatus, error)
+	Plan(DeploymentConfig) (api.DeploymentPlan, error)
+
+	CheckHealth(context.Context, *docker.Client, io.Writer) error
+	RollbackFailedDeploy(ctx context.Context, cli *docker.Client, out io.Writer, cause error) error
 
 	SetConfig(DeploymentConfig)
 	GetBranch() string
+	GetImage() string
+	GetWatchPaths() []string
+	GetChangedFiles(from, to string) ([]string, error)
+	GetTriggers() *api.Triggers
 	CompareRemotes(string) error
 
+	IsPreview() bool
+	GetPreviewConfig(pr PullRequest, host string) (DeploymentConfig, error)
+	Notify(msg string, opts notify.Options) error
+
 	UpdateContainerHistory(cli *docker.Client) error
+	GetHistory() ([]api.DeploymentRecord, error)
 
 	GetDataManager() (*DeploymentDataManager, bool)
 
-	Watch(*docker.Client) (<-chan string, <-chan error)
+	ControlContainer(cli *docker.Client, name string, action ContainerAction) error
+
+	Watch(context.Context, *docker.Client) (<-chan string, <-chan error)
 }
 
 // Deployment represents the deployed project
@@ -53,19 +76,67 @@ type Deployment struct {
 
 	project                string
 	branch                 string
+	ref                    string
+	submodules             bool
+	lfs                    bool
+	depth                  int
+	pruneTo                []string
 	buildType              string
 	buildFilePath          string
+	buildContext           string
+	watchPaths             []string
+	triggers               *api.Triggers
+	image                  *api.Image
+	buildArgs              map[string]string
+	buildArgsFromEnv       []string
+	target                 string
+	ports                  []string
+	labels                 map[string]string
+	resources              *api.Resources
+	compose                *api.Compose
+	deployStrategy         string
+	healthCheck            *api.HealthCheck
+	restartPolicy          *api.RestartPolicy
+	hooks                  *api.Hooks
 	intermediaryContainers []string
 
+	// previews configures previews of pull requests to this deployment, and
+	// previewOf and previewURL are set if this deployment is itself a preview
+	previews   *api.Previews
+	previewOf  string
+	previewURL string
+
+	// staleComposeProject is a previous compose project name override, whose
+	// containers must still be stopped
+	staleComposeProject string
+
+	// hookResults records the outcome of hooks run during the latest deploy
+	hookResults []api.HookResult
+
+	// buildID identifies the build log of the latest deploy
+	buildID string
+
+	// restarts tracks exits of project containers since the latest deploy
+	restarts restartTracker
+
 	builder build.ContainerBuilder
 
-	repo *gogit.Repository
-	auth ssh.AuthMethod
-	mux  sync.Mutex
+	repo        *gogit.Repository
+	remoteURL   string
+	pemFilePath string
+	auth        ssh.AuthMethod
+	mux         sync.Mutex
+
+	// configMux guards changes to the configuration and repository, so that
+	// they can be read without waiting for a deploy to release mux
+	configMux sync.RWMutex
 
 	dataManager *DeploymentDataManager
 
-	notifiers notify.Notifiers
+	notifiers            notify.Notifiers
+	slackNotificationURL string
+
+	logger *log.Logger
 }
 
 // DeploymentConfig is used to configure Deployment
@@ -73,22 +144,66 @@ type DeploymentConfig struct {
 	ProjectName            string
 	BuildType              string
 	BuildFilePath          string
+	BuildContext           string
+	WatchPaths             []string
+	Triggers               *api.Triggers
+	Image                  *api.Image
+	BuildArgs              map[string]string
+	BuildArgsFromEnv       []string
+	Target                 string
+	Ports                  []string
+	Labels                 map[string]string
+	Resources              *api.Resources
+	Compose                *api.Compose
+	DeployStrategy         string
+	HealthCheck            *api.HealthCheck
+	RestartPolicy          *api.RestartPolicy
+	Hooks                  *api.Hooks
 	RemoteURL              string
 	Branch                 string
+	Ref                    string
+	Submodules             bool
+	LFS                    bool
+	Depth                  int
+	PruneTo                []string
 	PemFilePath            string
 	IntermediaryContainers []string
+	Previews               *api.Previews
+
+	// PreviewOf is the name of the project a preview deployment previews a
+	// pull request to, and PreviewURL is where the preview is served
+	PreviewOf  string
+	PreviewURL string
 
 	// TODO: maybe improve format for generic notifiers
 	SlackNotificationURL string
+
+	// Notifiers are registered in addition to any Slack notifier, and are not
+	// persisted
+	Notifiers notify.Notifiers `json:"-"`
 }
 
 // DeploymentMetadata is used to store metadata relevant
 // to the most recent deployment
 type DeploymentMetadata struct {
 	Hash            string
+	Branch          string
+	BuildType       string
 	ContainerID     string
 	ContainerStatus string
 	StartedAt       string
+	DeployedAt      time.Time
+	Hooks           []api.HookResult
+	BuildID         string
+
+	// Failed is set if the deploy did not complete, in which case Error
+	// describes why
+	Failed bool
+	Error  string
+
+	// EnvVariables are the names of the environment variables set at the time
+	// of the deployment
+	EnvVariables []string
 }
 
 // NewDeployment creates a new deployment
@@ -100,6 +215,7 @@ func NewDeployment(
 	databaseKeyPath string,
 
 	builder build.ContainerBuilder,
+	logger *log.Logger,
 ) (*Deployment, error) {
 
 	// Set up deployment database
@@ -114,6 +230,7 @@ func NewDeployment(
 		persistDirectory: persistDirectory,
 		builder:          builder,
 		dataManager:      manager,
+		logger:           logger,
 	}, nil
 }
 
@@ -124,14 +241,10 @@ func (d *Deployment) Initialize(cfg DeploymentConfig, out io.Writer) error {
 	}
 
 	d.SetConfig(cfg)
+	d.remoteURL = cfg.RemoteURL
 
 	// Retrieve authentication
-	pemFile, err := os.Open(cfg.PemFilePath)
-	if err != nil {
-		return err
-	}
-	d.auth, err = crypto.GetInertiaKey(pemFile)
-	if err != nil {
+	if err := d.loadAuth(cfg.PemFilePath); err != nil {
 		return err
 	}
 
@@ -144,88 +257,399 @@ func (d *Deployment) Initialize(cfg DeploymentConfig, out io.Writer) error {
 	}
 
 	// Initialize repository
-	d.repo, err = git.InitializeRepository(cfg.RemoteURL, git.RepoOptions{
-		Directory: d.directory,
-		Branch:    cfg.Branch,
-		Auth:      d.auth,
-	}, out)
-	return err
+	opts, err := d.repoOptions()
+	if err != nil {
+		return err
+	}
+	repo, err := git.InitializeRepository(cfg.RemoteURL, opts, out)
+	d.configMux.Lock()
+	d.repo = repo
+	d.configMux.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Keep the configuration around so that the project can be restored if
+	// the daemon restarts
+	d.saveConfig()
+	return nil
+}
+
+// Load restores the configuration saved by a previous Initialize or deploy,
+// and reopens the repository Initialize set up, so that the deployment can
+// pick up where it left off when the daemon restarts. Repositories set up
+// before configurations were saved are restored from their remote and
+// current branch.
+func (d *Deployment) Load() error {
+	if d.dataManager == nil {
+		return errNoDataManager
+	}
+	repo, err := git.OpenRepository(d.directory)
+	if err != nil {
+		return err
+	}
+	cfg, err := d.dataManager.GetConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		if cfg, err = repositoryConfig(repo); err != nil {
+			return fmt.Errorf("no saved configuration: %s", err.Error())
+		}
+	}
+
+	if err := d.loadAuth(cfg.PemFilePath); err != nil {
+		return err
+	}
+	d.SetConfig(*cfg)
+	d.configMux.Lock()
+	d.remoteURL = cfg.RemoteURL
+	d.repo = repo
+	d.configMux.Unlock()
+	return nil
+}
+
+// repositoryConfig derives a configuration from the given repository, using
+// its origin remote and current branch
+func repositoryConfig(repo *gogit.Repository) (*DeploymentConfig, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return nil, err
+	}
+	if len(remote.Config().URLs) == 0 {
+		return nil, errors.New("repository has no origin URL")
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return &DeploymentConfig{
+		RemoteURL:   remote.Config().URLs[0],
+		Branch:      head.Name().Short(),
+		PemFilePath: crypto.DaemonInertiaKeyLocation,
+	}, nil
+}
+
+// loadAuth reads the deploy key at the given path, which is used to access SSH
+// remotes
+func (d *Deployment) loadAuth(pemFilePath string) error {
+	pemFile, err := os.Open(pemFilePath)
+	if err != nil {
+		return err
+	}
+	defer pemFile.Close()
+	if d.auth, err = crypto.GetInertiaKey(pemFile); err != nil {
+		return err
+	}
+	d.pemFilePath = pemFilePath
+	return nil
+}
+
+// repoOptions returns the options used to manage the deployment's repository
+func (d *Deployment) repoOptions() (git.RepoOptions, error) {
+	auth, err := d.getAuth()
+	return git.RepoOptions{
+		Directory:  d.directory,
+		Branch:     d.branch,
+		Auth:       auth,
+		Ref:        d.ref,
+		Submodules: d.submodules,
+		LFS:        d.lfs,
+		Depth:      d.depth,
+		PruneTo:    d.pruneTo,
+	}, err
 }
 
-// SetConfig updates the deployment's configuration. Only supports
-// ProjectName, Branch, and BuildType for now.
+// getAuth returns the credentials used to access the deployment's remote.
+// HTTPS remotes use the deployment's git credentials if any are set, and are
+// accessed anonymously otherwise.
+func (d *Deployment) getAuth() (transport.AuthMethod, error) {
+	if !git.IsHTTPRemote(d.remoteURL) {
+		return d.auth, nil
+	}
+	if d.dataManager == nil {
+		return nil, nil
+	}
+	creds, err := d.dataManager.GetGitCredentials()
+	if err != nil || creds == nil {
+		return nil, err
+	}
+	return &githttp.BasicAuth{Username: creds.Username, Password: creds.Token}, nil
+}
+
+// SetConfig updates the deployment's configuration. All fields except
+// ProjectName, Branch, RemoteURL, BuildType, BuildFilePath, DeployStrategy,
+// and notifiers are overwritten. Those are only updated if they are set,
+// RemoteURL only to another URL of the same repository, and notifiers are
+// only ever added.
 func (d *Deployment) SetConfig(cfg DeploymentConfig) {
+	d.configMux.Lock()
+	defer d.configMux.Unlock()
+
 	if cfg.ProjectName != "" {
 		d.project = cfg.ProjectName
 	}
 	if cfg.Branch != "" {
 		d.branch = cfg.Branch
 	}
+	// Only allow switching between URLs of the same repository, such as its
+	// SSH and HTTPS URLs - other changes require a reset
+	if cfg.RemoteURL != "" && d.remoteURL != "" &&
+		common.GetSSHRemoteURL(cfg.RemoteURL) == common.GetSSHRemoteURL(d.remoteURL) {
+		d.remoteURL = cfg.RemoteURL
+	}
 	if cfg.BuildType != "" {
 		d.buildType = cfg.BuildType
 	}
 	if cfg.BuildFilePath != "" {
 		d.buildFilePath = cfg.BuildFilePath
 	}
+	if cfg.DeployStrategy != "" {
+		d.deployStrategy = cfg.DeployStrategy
+	}
+	d.ref = cfg.Ref
+	d.buildContext = cfg.BuildContext
+	d.watchPaths = cfg.WatchPaths
+	d.triggers = cfg.Triggers
+	d.submodules = cfg.Submodules
+	d.lfs = cfg.LFS
+	d.depth = cfg.Depth
+	d.pruneTo = cfg.PruneTo
+	d.image = cfg.Image
+	d.buildArgs = cfg.BuildArgs
+	d.buildArgsFromEnv = cfg.BuildArgsFromEnv
+	d.target = cfg.Target
+	d.ports = cfg.Ports
+	d.labels = cfg.Labels
+	d.resources = cfg.Resources
+	if previous := d.composeProject(); previous != "" &&
+		(cfg.Compose == nil || cfg.Compose.ProjectName != previous) {
+		d.staleComposeProject = previous
+	}
+	d.compose = cfg.Compose
+	d.healthCheck = cfg.HealthCheck
+	d.restartPolicy = cfg.RestartPolicy
+	d.hooks = cfg.Hooks
 	d.intermediaryContainers = cfg.IntermediaryContainers
+	d.previews = cfg.Previews
+	d.previewOf = cfg.PreviewOf
+	d.previewURL = cfg.PreviewURL
 
 	// register notifiers
 	if len(d.notifiers) == 0 {
 		d.notifiers = notify.Notifiers{}
 	}
 	if cfg.SlackNotificationURL != "" {
+		d.slackNotificationURL = cfg.SlackNotificationURL
 		nt := notify.NewSlackNotifier(cfg.SlackNotificationURL)
 		if !d.notifiers.Exists(nt) {
 			d.notifiers = append(d.notifiers, nt)
 		}
 	}
+	for _, nt := range cfg.Notifiers {
+		if !d.notifiers.Exists(nt) {
+			d.notifiers = append(d.notifiers, nt)
+		}
+	}
+}
+
+// config returns the deployment's current configuration. Notifiers other than
+// the Slack notifier are left out. Requires configMux.
+func (d *Deployment) config() DeploymentConfig {
+	return DeploymentConfig{
+		ProjectName:            d.project,
+		BuildType:              d.buildType,
+		BuildFilePath:          d.buildFilePath,
+		BuildContext:           d.buildContext,
+		WatchPaths:             d.watchPaths,
+		Triggers:               d.triggers,
+		Image:                  d.image,
+		BuildArgs:              d.buildArgs,
+		BuildArgsFromEnv:       d.buildArgsFromEnv,
+		Target:                 d.target,
+		Ports:                  d.ports,
+		Labels:                 d.labels,
+		Resources:              d.resources,
+		Compose:                d.compose,
+		DeployStrategy:         d.deployStrategy,
+		HealthCheck:            d.healthCheck,
+		RestartPolicy:          d.restartPolicy,
+		Hooks:                  d.hooks,
+		RemoteURL:              d.remoteURL,
+		Branch:                 d.branch,
+		Ref:                    d.ref,
+		Submodules:             d.submodules,
+		LFS:                    d.lfs,
+		Depth:                  d.depth,
+		PruneTo:                d.pruneTo,
+		PemFilePath:            d.pemFilePath,
+		IntermediaryContainers: d.intermediaryContainers,
+		Previews:               d.previews,
+		PreviewOf:              d.previewOf,
+		PreviewURL:             d.previewURL,
+		SlackNotificationURL:   d.slackNotificationURL,
+	}
+}
+
+// saveConfig saves the deployment's current configuration, so that it can be
+// restored by Load. Failures are logged, since the deployment itself is not
+// affected.
+func (d *Deployment) saveConfig() {
+	if d.dataManager == nil {
+		return
+	}
+	d.configMux.RLock()
+	var cfg = d.config()
+	d.configMux.RUnlock()
+	if err := d.dataManager.SaveConfig(cfg); err != nil {
+		d.logger.Warn("failed to save configuration", "project", d.project, "error", err)
+	}
+}
+
+// Notify delivers the given message to the deployment's notifiers
+func (d *Deployment) Notify(msg string, opts notify.Options) error {
+	return d.notifiers.Notify(msg, opts)
 }
 
 // DeployOptions is used to configure how the deployment handles the deploy
 type DeployOptions struct {
 	SkipUpdate bool
+
+	// Commit, if set, is checked out and deployed instead of the tip of the
+	// deployment's branch. The commit must already be available locally.
+	Commit string
+
+	// Ref, if set, is fetched and deployed instead of the tip of the
+	// deployment's branch, without pinning the deployment to it. It may be a
+	// commit hash, tag, or branch name.
+	Ref string
+
+	// BuildID, if set, identifies the build log the deploy's output is
+	// recorded in, and is linked from the deployment's history
+	BuildID string
 }
 
-// Deploy will update, build, and deploy the project
+// Deploy will update, build, and deploy the project. Cancelling the given
+// context aborts the build and deploy.
 func (d *Deployment) Deploy(
+	ctx context.Context,
 	cli *docker.Client,
 	out io.Writer,
 	opts DeployOptions,
 ) (func() error, error) {
 	d.mux.Lock()
 	defer d.mux.Unlock()
-	fmt.Println(out, "Preparing to deploy project")
+	fmt.Fprintln(out, "Preparing to deploy project")
+	d.logger.Info("deploying project", "project", d.project,
+		"build_id", opts.BuildID, "commit", opts.Commit, "ref", opts.Ref)
+	d.hookResults = nil
+	d.buildID = opts.BuildID
+
+	// Failed deploys are recorded in the project's history as well, so that
+	// the results of the hooks that ran are kept
+	deploy, err := d.prepareDeploy(ctx, cli, out, opts)
+	if err != nil {
+		d.recordFailedDeploy(err)
+		return func() error { return nil }, err
+	}
+	return func() error {
+		d.mux.Lock()
+		defer d.mux.Unlock()
+		if err := deploy(); err != nil {
+			d.recordFailedDeploy(err)
+			return err
+		}
+		d.saveConfig()
+		return nil
+	}, nil
+}
 
+// prepareDeploy updates and builds the project, and runs pre-deploy hooks
+// against the new build. It returns a function that starts the new build and
+// runs post-deploy hooks.
+func (d *Deployment) prepareDeploy(
+	ctx context.Context,
+	cli *docker.Client,
+	out io.Writer,
+	opts DeployOptions,
+) (func() error, error) {
 	// Update repository
-	if !opts.SkipUpdate {
-		if err := git.UpdateRepository(d.repo, git.RepoOptions{
-			Directory: d.directory,
-			Branch:    d.branch,
-			Auth:      d.auth,
-		}, out); err != nil {
+	repoOpts, err := d.repoOptions()
+	if err != nil {
+		return func() error { return nil }, err
+	}
+	if opts.Commit != "" {
+		if err := git.CheckoutCommit(d.repo, opts.Commit, out); err != nil {
+			return func() error { return nil }, err
+		}
+		if err := git.UpdateWorktree(d.repo, repoOpts, out); err != nil {
+			return func() error { return nil }, err
+		}
+	} else if !opts.SkipUpdate {
+		if err := git.SetRemoteURL(d.repo, d.remoteURL); err != nil {
+			return func() error { return nil }, err
+		}
+		if opts.Ref != "" {
+			repoOpts.Ref = opts.Ref
+		}
+		if err := git.UpdateRepository(d.repo, repoOpts, out); err != nil {
 			return func() error { return nil }, err
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return func() error { return nil }, err
+	}
+
+	// The build context may have been moved, or pruned from the worktree
+	if d.buildContext != "" {
+		if info, err := os.Stat(filepath.Join(d.directory, d.buildContext)); err != nil || !info.IsDir() {
+			return func() error { return nil }, fmt.Errorf(
+				"build context '%s' is not a directory in the repository", d.buildContext)
+		}
+	}
 
 	// Clean up
 	d.builder.Prune(cli, out)
 
-	// Kill active project containers if there are any
-	d.active = false
-	err := d.builder.StopContainers(cli, out)
-	if err != nil {
-		return func() error { return nil }, err
-	}
-
 	// Get config
 	conf, err := d.GetBuildConfiguration()
-	if err != nil {
+	if err == errNoDataManager {
 		fmt.Fprintln(out, err.Error())
 		fmt.Fprintln(out, "Continuing...")
+	} else if err != nil {
+		return func() error { return nil }, fmt.Errorf("invalid build configuration: %s", err.Error())
+	}
+
+	// Previews of docker-compose projects use the project's compose files, so
+	// host ports published in them would conflict with the project's own
+	if d.previewOf != "" && strings.ToLower(d.buildType) == "docker-compose" {
+		published, err := build.ComposePublishedPorts(*conf)
+		if err != nil {
+			return func() error { return nil }, err
+		}
+		if len(published) > 0 {
+			return func() error { return nil }, fmt.Errorf(
+				"previews of docker-compose projects cannot publish host ports, since they would "+
+					"conflict with those of project %s (%s)", d.previewOf, strings.Join(published, ", "))
+		}
+	}
+
+	// Kill active project containers if there are any, unless they are to be
+	// replaced only once the new deployment is ready
+	d.active = false
+	if conf.DeployStrategy == build.StrategyBlueGreen {
+		fmt.Fprintln(out, "Using blue-green deploy - active containers will be retired once the new deployment is ready")
+	} else {
+		if d.deployStrategy == build.StrategyBlueGreen {
+			fmt.Fprintln(out, "Blue-green deploys are only supported for Dockerfile, image, and buildpack projects - falling back to recreate")
+		}
+		if err := d.stopContainers(cli, out); err != nil {
+			return func() error { return nil }, err
+		}
 	}
 
 	// Build project
-	deploy, err := d.builder.Build(strings.ToLower(d.buildType), *conf, cli, out)
+	deploy, err := d.builder.Build(ctx, strings.ToLower(d.buildType), *conf, cli, out)
 	if err != nil {
 		if notifyErr := d.notifiers.Notify(fmt.Sprintf("Build error: %s", err), notify.Options{
 			Color: notify.Red,
@@ -242,10 +666,37 @@ func (d *Deployment) Deploy(
 		fmt.Fprintln(out, notifyErr.Error())
 	}
 
-	// Deploy
+	// Run pre-deploy hooks against the new build before it is started
+	var hooks = d.hooks
+	if hooks == nil {
+		hooks = &api.Hooks{}
+	}
+	if err := d.runHooks(ctx, cli, out, *conf, HookStagePreDeploy, hooks.PreDeploy, true); err != nil {
+		if notifyErr := d.notifiers.Notify(fmt.Sprintf("Pre-deploy hook error: %s", err), notify.Options{
+			Color: notify.Red,
+		}); notifyErr != nil {
+			fmt.Fprintln(out, notifyErr.Error())
+		}
+		return func() error { return nil }, err
+	}
+
+	// Deploy - the project is only marked as active once the deploy completes,
+	// since deploys may retire old containers along the way. Post-deploy hook
+	// failures are reported, but do not fail the deploy.
 	return func() error {
+		if err := deploy(); err != nil {
+			return err
+		}
+		if err := d.runHooks(ctx, cli, out, *conf, HookStagePostDeploy, hooks.PostDeploy, false); err != nil {
+			if notifyErr := d.notifiers.Notify(fmt.Sprintf("Post-deploy hook error: %s", err), notify.Options{
+				Color: notify.Yellow,
+			}); notifyErr != nil {
+				fmt.Fprintln(out, notifyErr.Error())
+			}
+		}
+		d.restarts.reset(d.restartPolicy)
 		d.active = true
-		return deploy()
+		return nil
 	}, nil
 }
 
@@ -258,15 +709,16 @@ func (d *Deployment) Down(cli *docker.Client, out io.Writer) error {
 	// everything anyway in case the docker-compose image is still
 	// active
 	d.active = false
-	_, err := containers.GetActiveContainers(cli)
+	d.restarts.reset(d.restartPolicy)
+	_, err := containers.GetProjectContainers(cli, d.project, d.composeProject())
 	if err != nil {
-		killErr := d.builder.StopContainers(cli, out)
+		killErr := d.stopContainers(cli, out)
 		if killErr != nil {
-			println(err)
+			d.logger.Warn("failed to stop project containers", "project", d.project, "error", killErr)
 		}
 		return err
 	}
-	err = d.builder.StopContainers(cli, out)
+	err = d.stopContainers(cli, out)
 	if err != nil {
 		return err
 	}
@@ -276,9 +728,42 @@ func (d *Deployment) Down(cli *docker.Client, out io.Writer) error {
 	return nil
 }
 
-// Prune clears unused Docker assets
+// Prune removes the Docker assets left behind by the project, such as
+// containers archived by past deployments and unused images
 func (d *Deployment) Prune(cli *docker.Client, out io.Writer) error {
-	return d.builder.PruneAll(cli, out)
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	fmt.Fprintf(out, "Pruning Docker assets for project %s...\n", d.project)
+	return containers.PruneProject(cli, d.project, d.composeProject())
+}
+
+// stopContainers stops the project's active containers. Containers of
+// docker-compose projects with an overridden compose project name, including
+// a previous override, are not recognized by the builder, so they are stopped
+// separately.
+func (d *Deployment) stopContainers(cli *docker.Client, out io.Writer) error {
+	if err := d.builder.StopContainers(cli, out); err != nil {
+		return err
+	}
+	for _, name := range []string{d.staleComposeProject, d.composeProject()} {
+		if name == "" {
+			continue
+		}
+		if err := containers.StopProjectContainers(cli, out, d.project, name); err != nil {
+			return err
+		}
+	}
+	d.staleComposeProject = ""
+	return nil
+}
+
+// composeProject returns the overridden compose project name of the
+// deployment, if there is one
+func (d *Deployment) composeProject() string {
+	if d.compose == nil {
+		return ""
+	}
+	return d.compose.ProjectName
 }
 
 // Destroy shuts down the deployment and removes the repository
@@ -299,10 +784,6 @@ func (d *Deployment) GetStatus(cli *docker.Client) (api.DeploymentStatus, error)
 	var (
 		activeContainers     = make([]string, 0)
 		buildContainerActive = false
-		ignore               = map[string]bool{
-			"/inertia-daemon":                   true,
-			"/" + d.builder.GetBuildStageName(): true,
-		}
 	)
 
 	// No repository set up
@@ -320,28 +801,37 @@ func (d *Deployment) GetStatus(cli *docker.Client) (api.DeploymentStatus, error)
 		return api.DeploymentStatus{Containers: activeContainers}, err
 	}
 
-	// Get containers, filtering out non-project containers
-	c, err := containers.GetActiveContainers(cli)
+	// Get project containers, filtering out the build container
+	c, err := containers.GetProjectContainers(cli, d.project, d.composeProject())
 	if err != nil && err != containers.ErrNoContainers {
 		return api.DeploymentStatus{Containers: activeContainers}, err
 	}
 	for _, container := range c {
-		if !ignore[container.Names[0]] {
-			activeContainers = append(activeContainers, container.Names[0])
+		if container.Labels[containers.LabelStage] == d.builder.GetBuildStageName() {
+			buildContainerActive = true
 		} else {
-			if container.Names[0] == "/docker-compose" {
-				buildContainerActive = true
-			}
+			activeContainers = append(activeContainers, container.Names[0])
 		}
 	}
 
+	// Pinned deployments and rollbacks check out a detached HEAD, so report
+	// the configured branch instead
+	var branch = strings.TrimSpace(head.Name().Short())
+	if !head.Name().IsBranch() && d.branch != "" {
+		branch = d.branch
+	}
+
 	return api.DeploymentStatus{
-		Branch:               strings.TrimSpace(head.Name().Short()),
+		Branch:               branch,
 		CommitHash:           strings.TrimSpace(head.Hash().String()),
 		CommitMessage:        strings.TrimSpace(commit.Message),
 		BuildType:            strings.TrimSpace(d.buildType),
 		Containers:           activeContainers,
 		BuildContainerActive: buildContainerActive,
+		Pinned:               d.ref != "",
+		Ref:                  d.ref,
+		Resources:            d.resources,
+		Failure:              d.restarts.getFailure(),
 	}, nil
 }
 
@@ -350,6 +840,67 @@ func (d *Deployment) GetBranch() string {
 	return d.branch
 }
 
+// GetWatchPaths returns the paths that must be changed by a push for it to be
+// deployed. If no watch paths are configured, changes to the build context are
+// watched - an empty result means every push is deployed.
+func (d *Deployment) GetWatchPaths() []string {
+	if len(d.watchPaths) == 0 && d.buildContext != "" {
+		return []string{d.buildContext}
+	}
+	return d.watchPaths
+}
+
+// GetTriggers returns the rules that determine which pushes are deployed, if
+// any are configured
+func (d *Deployment) GetTriggers() *api.Triggers {
+	return d.triggers
+}
+
+// GetImage returns the name of the image deployed by image builds, if any
+func (d *Deployment) GetImage() string {
+	if d.image == nil || strings.ToLower(d.buildType) != "image" {
+		return ""
+	}
+	return d.image.Name
+}
+
+// ValidateImage checks if the given image configuration is valid for the given
+// build type
+func ValidateImage(buildType string, image *api.Image) error {
+	if strings.ToLower(buildType) != "image" {
+		return nil
+	}
+	if image == nil || image.Name == "" {
+		return errors.New("an image is required for image builds")
+	}
+	if image.Password != "" && image.Username == "" {
+		return errors.New("a registry username is required with a registry password")
+	}
+	return nil
+}
+
+// ValidatePorts checks if the given port mappings are valid
+func ValidatePorts(ports []string) error {
+	if _, _, err := nat.ParsePortSpecs(ports); err != nil {
+		return fmt.Errorf("invalid port mapping: %s", err.Error())
+	}
+	return nil
+}
+
+// ValidateDeployStrategy checks if the given deploy strategy can be used with
+// the given build type and port mappings
+func ValidateDeployStrategy(strategy, buildType string, ports []string) error {
+	if strategy != build.StrategyBlueGreen {
+		return nil
+	}
+	// Other build types fall back to recreate deploys
+	switch strings.ToLower(buildType) {
+	case "dockerfile", "image", "buildpack":
+		return build.ValidateBlueGreenPorts(ports)
+	}
+	return nil
+}
+
 // CompareRemotes will compare the remote of the deployment  with given remote
 // URL and return nil if they don't conflict
 func (d *Deployment) CompareRemotes(remoteURL string) error {
@@ -357,6 +908,9 @@ func (d *Deployment) CompareRemotes(remoteURL string) error {
 	if remoteURL == "" {
 		return nil
 	}
+	if d.repo == nil {
+		return errors.New("no repository set up")
+	}
 	remotes, err := d.repo.Remotes()
 	if err != nil {
 		return err
@@ -371,54 +925,121 @@ func (d *Deployment) CompareRemotes(remoteURL string) error {
 // UpdateContainerHistory will update container bucket with recent build's
 // metadata
 func (d *Deployment) UpdateContainerHistory(cli *docker.Client) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
 
 	// Get project hash
-	head, err := d.repo.Head()
+	metadata, err := d.deploymentMetadata()
 	if err != nil {
 		return fmt.Errorf("failed fetching repo head when updating container history: %s", err.Error())
 	}
-	// Retrieve container for recently deployed project
+
+	// Retrieve container for recently deployed project - note that
+	// docker-compose projects do not have a container named after the project
 	ctx := context.Background()
-	var recentlyBuiltContainer types.Container
-	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{})
+	list, err := cli.ContainerList(ctx, types.ContainerListOptions{})
 	if err != nil {
 		return fmt.Errorf("failure fetching list of containers: %s", err.Error())
 	}
-	for _, container := range containers {
-		if container.Names[0] == d.project {
-			recentlyBuiltContainer = container
+	for _, container := range list {
+		if container.Names[0] != "/"+d.project {
+			continue
 		}
+
+		// Get container metadata
+		containerJSON, err := cli.ContainerInspect(ctx, container.ID)
+		if err != nil {
+			return fmt.Errorf("failure fetching container metadata: %s", err.Error())
+		}
+		metadata.ContainerID = container.ID
+		containerState := containerJSON.ContainerJSONBase.State // similar to running "docker inspect {container}"
+		if containerState != nil {
+			metadata.ContainerStatus = containerState.Status
+			metadata.StartedAt = containerState.StartedAt
+		}
+	}
+
+	// Update db with newly built container metadata
+	err = d.dataManager.AddProjectBuildData(d.project, metadata)
+	if err != nil {
+		return fmt.Errorf("failure adding build metadata: %s", err.Error())
 	}
 
-	// Get container metadata
-	var containerID string
-	if len(recentlyBuiltContainer.ID) > 0 {
-		containerID = recentlyBuiltContainer.ID
+	return nil
+}
+
+// recordFailedDeploy adds the latest deploy to the project's history as a
+// failed deploy. Failures are only logged, since the deploy has failed
+// already. d.mux must be held.
+func (d *Deployment) recordFailedDeploy(cause error) {
+	if d.dataManager == nil {
+		return
 	}
-	containerJSON, err := cli.ContainerInspect(ctx, containerID)
+	metadata, err := d.deploymentMetadata()
 	if err != nil {
-		return fmt.Errorf("failure fetching container metadata: %s", err.Error())
+		d.logger.Warn("failed to record failed deploy", "project", d.project, "error", err)
+		return
 	}
-	containerState := containerJSON.ContainerJSONBase.State // similar to running "docker inspect {container}"
-	var containerStatus, containerStartedAtTime string
-	if containerState != nil {
-		containerStatus = containerState.Status
-		containerStartedAtTime = containerState.StartedAt
+	metadata.Failed = true
+	metadata.Error = cause.Error()
+	if err := d.dataManager.AddProjectBuildData(d.project, metadata); err != nil {
+		d.logger.Warn("failed to record failed deploy", "project", d.project, "error", err)
 	}
+}
 
+// deploymentMetadata describes the latest deploy of the checked out commit.
+// d.mux must be held.
+func (d *Deployment) deploymentMetadata() (DeploymentMetadata, error) {
+	if d.repo == nil {
+		return DeploymentMetadata{}, errors.New("project repository is not set up")
+	}
+	head, err := d.repo.Head()
+	if err != nil {
+		return DeploymentMetadata{}, err
+	}
 	metadata := DeploymentMetadata{
-		Hash:            head.Hash().String(),
-		ContainerID:     containerID,
-		ContainerStatus: containerStatus,
-		StartedAt:       containerStartedAtTime}
+		Hash:       head.Hash().String(),
+		Branch:     d.branch,
+		BuildType:  d.buildType,
+		DeployedAt: time.Now(),
+		Hooks:      d.hookResults,
+		BuildID:    d.buildID,
+	}
+	if d.dataManager != nil {
+		if env, err := d.dataManager.GetEnvVariables(false); err == nil {
+			metadata.EnvVariables = envNames(env)
+		}
+	}
+	return metadata, nil
+}
 
-	// Update db with newly built container metadata
-	err = d.dataManager.AddProjectBuildData(d.project, metadata)
+// GetHistory returns records of past deployments of the project, most recent
+// first
+func (d *Deployment) GetHistory() ([]api.DeploymentRecord, error) {
+	if d.dataManager == nil {
+		return nil, errors.New("no data manager")
+	}
+	history, err := d.dataManager.GetProjectBuildData(d.project)
 	if err != nil {
-		return fmt.Errorf("failure adding build metadata: %s", err.Error())
+		return nil, err
 	}
-
-	return nil
+	var records = make([]api.DeploymentRecord, len(history))
+	for i, h := range history {
+		records[i] = api.DeploymentRecord{
+			CommitHash:      h.Hash,
+			Branch:          h.Branch,
+			BuildType:       h.BuildType,
+			ContainerID:     h.ContainerID,
+			ContainerStatus: h.ContainerStatus,
+			StartedAt:       h.StartedAt,
+			DeployedAt:      h.DeployedAt,
+			Hooks:           h.Hooks,
+			BuildID:         h.BuildID,
+			Failed:          h.Failed,
+			Error:           h.Error,
+		}
+	}
+	return records, nil
 }
 
 // GetDataManager returns the class managing deployment data
@@ -429,14 +1050,54 @@ func (d *Deployment) GetDataManager() (manager *DeploymentDataManager, found boo
 	return d.dataManager, true
 }
 
+// errNoDataManager is returned by GetBuildConfiguration for deployments without
+// a data manager, which can still be built without environment variables
+var errNoDataManager = errors.New("no data manager")
+
 // GetBuildConfiguration returns the build used to build this project. Returns
-// config without env values if error.
+// config without env values if error, and without build args from the
+// environment if any are not set. Only errNoDataManager leaves the config
+// usable for a build.
 func (d *Deployment) GetBuildConfiguration() (*build.Config, error) {
 	conf := &build.Config{
 		Name:             d.project,
 		BuildFilePath:    d.buildFilePath,
-		BuildDirectory:   d.directory,
+		BuildDirectory:   filepath.Join(d.directory, d.buildContext),
 		PersistDirectory: d.persistDirectory,
+		DeployStrategy:   build.StrategyRecreate,
+		Target:           d.target,
+		Ports:            d.ports,
+		Labels:           d.labels,
+	}
+	if d.compose != nil {
+		conf.ComposeFiles = d.compose.Files
+		conf.ComposeProfiles = d.compose.Profiles
+		conf.ComposeProjectName = d.compose.ProjectName
+	}
+	switch strings.ToLower(d.buildType) {
+	case "dockerfile", "image", "buildpack":
+		if d.deployStrategy == build.StrategyBlueGreen {
+			conf.DeployStrategy = build.StrategyBlueGreen
+		}
+	}
+	if d.healthCheck != nil {
+		// Blue-green deploys wait for new containers as long as health checks
+		// do - durations are validated along with the rest of the check
+		conf.ReadyTimeout, _ = time.ParseDuration(d.healthCheck.Timeout)
+		conf.ReadyGracePeriod, _ = time.ParseDuration(d.healthCheck.GracePeriod)
+	}
+	resources, err := parseResources(d.resources)
+	if err != nil {
+		return conf, err
+	}
+	conf.Resources = resources
+	if d.image != nil {
+		auth, err := build.EncodeRegistryAuth(d.image.Server, d.image.Username, d.image.Password)
+		if err != nil {
+			return conf, err
+		}
+		conf.Image = d.image.Name
+		conf.RegistryAuth = auth
 	}
 	if d.dataManager != nil {
 		env, err := d.dataManager.GetEnvVariables(true)
@@ -444,18 +1105,53 @@ func (d *Deployment) GetBuildConfiguration() (*build.Config, error) {
 			return conf, err
 		}
 		conf.EnvValues = env
-	} else {
-		return conf, errors.New("no data manager")
+	}
+
+	// Build args from the environment are resolved last, so that a missing
+	// variable does not prevent the rest of the configuration from being used
+	conf.BuildArgs = make(map[string]*string, len(d.buildArgs)+len(d.buildArgsFromEnv))
+	for k, v := range d.buildArgs {
+		var value = v
+		conf.BuildArgs[k] = &value
+	}
+	var missing []string
+	for _, name := range d.buildArgsFromEnv {
+		if value, found := lookupEnv(conf.EnvValues, name); found {
+			conf.BuildArgs[name] = &value
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return conf, fmt.Errorf("build args not found in project environment: %s",
+			strings.Join(missing, ", "))
+	}
+	if d.dataManager == nil {
+		return conf, errNoDataManager
 	}
 	return conf, nil
 }
 
-// Watch watches for container stops
-func (d *Deployment) Watch(client *docker.Client) (<-chan string, <-chan error) {
+// lookupEnv finds the value of the named variable in a list of NAME=VALUE
+// environment variables
+func lookupEnv(env []string, name string) (string, bool) {
+	for _, e := range env {
+		if pair := strings.SplitN(e, "=", 2); len(pair) == 2 && pair[0] == name {
+			return pair[1], true
+		}
+	}
+	return "", false
+}
+
+// Watch watches for container stops, and restarts project containers that
+// stop unexpectedly according to the deployment's restart policy. Watching
+// stops once the given context is cancelled, after which the error channel is
+// closed.
+func (d *Deployment) Watch(ctx context.Context, client *docker.Client) (<-chan string, <-chan error) {
 	var (
-		ctx    = context.Background()
-		logsCh = make(chan string)
-		errCh  = make(chan error)
+		logsCh    = make(chan string)
+		errCh     = make(chan error)
+		restartCh = make(chan events.Message)
 	)
 
 	// Listen on channels
@@ -470,17 +1166,33 @@ func (d *Deployment) Watch(client *docker.Client) (<-chan string, <-chan error)
 
 		for {
 			select {
+			case <-ctx.Done():
+				return
+
 			case err := <-eventsErrCh:
-				if err != nil {
+				if err != nil && ctx.Err() == nil {
 					errCh <- err
-					break
+				}
+				return
+
+			case status := <-restartCh:
+				var containerName = status.Actor.Attributes["name"]
+				if !d.active || !d.isCurrentContainer(ctx, client, status.ID, containerName) {
+					continue
+				}
+				if err := client.ContainerStart(ctx, status.ID, types.ContainerStartOptions{}); err != nil {
+					logsCh <- fmt.Sprintf("failed to restart container %s: %s", containerName, err.Error())
+				} else {
+					logsCh <- fmt.Sprintf("container %s restarted", containerName)
 				}
 
 			case status := <-eventsCh:
-				var containerName string
-				if status.Actor.Attributes != nil {
-					containerName = status.Actor.Attributes["name"]
+				// Only track this project's containers - container labels are
+				// included in event attributes
+				if !containers.BelongsToProject(status.Actor.Attributes, d.project, d.composeProject()) {
+					continue
 				}
+				var containerName = strings.TrimPrefix(status.Actor.Attributes["name"], "/")
 
 				if containerName != "" {
 					logsCh <- fmt.Sprintf("container %s (%s) has stopped", containerName, status.ID[:11])
@@ -488,26 +1200,54 @@ func (d *Deployment) Watch(client *docker.Client) (<-chan string, <-chan error)
 					logsCh <- fmt.Sprintf("container %s has stopped", status.ID[:11])
 				}
 
-				if d.active {
-					// Check if we should ignore this container's death
-					var ignore bool
-					if len(d.intermediaryContainers) > 0 && containerName == "" {
-						for _, c := range d.intermediaryContainers {
-							if containerName == c {
-								ignore = true
-							}
-						}
+				if !d.active {
+					continue
+				}
+
+				// Intermediary containers are expected to stop
+				var ignore bool
+				for _, c := range d.intermediaryContainers {
+					if containerName == strings.TrimPrefix(c, "/") {
+						ignore = true
 					}
+				}
 
-					// Shut down all containers if one stops while project is active
-					if !ignore {
-						d.active = false
-						logsCh <- "container stoppage was unexpected, project is active"
-						err := containers.StopActiveContainers(client, os.Stdout)
-						if err != nil {
-							logsCh <- ("error shutting down other active containers: " + err.Error())
+				// Containers that have since been archived or removed were
+				// retired deliberately, for example during a blue-green deploy
+				if ignore || !d.isCurrentContainer(ctx, client, status.ID, containerName) {
+					continue
+				}
+
+				// Handle the stoppage according to the restart policy
+				exitCode, _ := strconv.Atoi(status.Actor.Attributes["exitCode"])
+				var decision = d.restarts.exited(containerName, exitCode, time.Now())
+				if decision.Expected {
+					continue
+				}
+				var msg string
+				var color notify.Color
+				switch {
+				case decision.CrashLoop:
+					msg = fmt.Sprintf("Deployment failed: %s, and will not be restarted", decision.Reason)
+					color = notify.Red
+				case decision.Restart:
+					msg = fmt.Sprintf("Container %s exited with code %d, restarting in %s",
+						containerName, exitCode, decision.Delay)
+					color = notify.Yellow
+					var message = status
+					time.AfterFunc(decision.Delay, func() {
+						select {
+						case restartCh <- message:
+						case <-ctx.Done():
 						}
-					}
+					})
+				default:
+					msg = fmt.Sprintf("Container %s exited unexpectedly with code %d", containerName, exitCode)
+					color = notify.Red
+				}
+				logsCh <- msg
+				if err := d.notifiers.Notify(msg, notify.Options{Color: color}); err != nil {
+					logsCh <- ("error sending notification: " + err.Error())
 				}
 			}
 		}
@@ -515,3 +1255,13 @@ func (d *Deployment) Watch(client *docker.Client) (<-chan string, <-chan error)
 
 	return logsCh, errCh
 }
+
+// isCurrentContainer checks if the given container still exists under the
+// given name and is not running
+func (d *Deployment) isCurrentContainer(ctx context.Context, client *docker.Client, id, name string) bool {
+	c, err := client.ContainerInspect(ctx, id)
+	if err != nil || c.Name != "/"+strings.TrimPrefix(name, "/") {
+		return false
+	}
+	return c.State == nil || !c.State.Running
+}
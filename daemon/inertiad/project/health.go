@@ -0,0 +1,282 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
+)
+
+const (
+	// HealthCheckContainer waits for project containers to report themselves
+	// as healthy using their HEALTHCHECK configuration
+	HealthCheckContainer = "container"
+	// HealthCheckHTTP waits for a URL to respond with an expected status
+	HealthCheckHTTP = "http"
+	// HealthCheckTCP waits for a port to accept connections
+	HealthCheckTCP = "tcp"
+
+	defaultHealthCheckTimeout  = time.Minute
+	defaultHealthCheckStatus   = http.StatusOK
+	healthCheckInterval        = 2 * time.Second
+	healthCheckRequestDuration = 5 * time.Second
+)
+
+// errUnhealthy indicates that a health check has definitively failed, and
+// should not be retried
+var errUnhealthy = errors.New("project is unhealthy")
+
+// ValidateHealthCheck checks if the given health check configuration is valid
+func ValidateHealthCheck(check *api.HealthCheck) error {
+	if check == nil {
+		return nil
+	}
+	switch check.Type {
+	case HealthCheckContainer:
+	case HealthCheckHTTP:
+		if _, err := url.ParseRequestURI(check.URL); err != nil {
+			return fmt.Errorf("invalid health check URL: %s", err.Error())
+		}
+	case HealthCheckTCP:
+		if check.Port < 1 || check.Port > 65535 {
+			return fmt.Errorf("invalid health check port %d", check.Port)
+		}
+	default:
+		return fmt.Errorf("unknown health check type '%s'", check.Type)
+	}
+	if check.Timeout != "" {
+		if _, err := time.ParseDuration(check.Timeout); err != nil {
+			return fmt.Errorf("invalid health check timeout: %s", err.Error())
+		}
+	}
+	if check.GracePeriod != "" {
+		if _, err := time.ParseDuration(check.GracePeriod); err != nil {
+			return fmt.Errorf("invalid health check grace period: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// CheckHealth blocks until the deployment passes its configured health check,
+// or until the check times out or the given context is cancelled. Deployments
+// without a health check are always considered healthy.
+func (d *Deployment) CheckHealth(ctx context.Context, cli *docker.Client, out io.Writer) error {
+	var check = d.healthCheck
+	if check == nil {
+		return nil
+	}
+	if err := ValidateHealthCheck(check); err != nil {
+		return err
+	}
+	var timeout = defaultHealthCheckTimeout
+	if check.Timeout != "" {
+		timeout, _ = time.ParseDuration(check.Timeout)
+	}
+
+	// Set up probe
+	var probe func(context.Context) error
+	switch check.Type {
+	case HealthCheckContainer:
+		probe = func(ctx context.Context) error {
+			return probeContainers(ctx, cli, d.project, d.composeProject())
+		}
+	case HealthCheckHTTP, HealthCheckTCP:
+		// Published ports are only reachable from the daemon through the host
+		host, err := containers.GetHostAddress(cli)
+		if err != nil {
+			return fmt.Errorf("failed to determine host address: %s", err.Error())
+		}
+		if check.Type == HealthCheckHTTP {
+			target, err := resolveHealthCheckURL(check.URL, host)
+			if err != nil {
+				return err
+			}
+			var status = check.ExpectedStatus
+			if status == 0 {
+				status = defaultHealthCheckStatus
+			}
+			probe = func(ctx context.Context) error {
+				return probeHTTP(ctx, target, status)
+			}
+		} else {
+			var address = net.JoinHostPort(host, strconv.Itoa(check.Port))
+			probe = func(ctx context.Context) error {
+				return probeTCP(ctx, address)
+			}
+		}
+	}
+
+	// Poll until healthy
+	fmt.Fprintf(out, "Waiting up to %s for project to pass %s health check...\n",
+		timeout, check.Type)
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := probe(checkCtx)
+		if err == nil {
+			fmt.Fprintln(out, "Health check passed")
+			return nil
+		}
+		if errors.Is(err, errUnhealthy) {
+			return err
+		}
+
+		select {
+		case <-checkCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("health check timed out after %s: %s", timeout, err.Error())
+		case <-time.After(healthCheckInterval):
+		}
+	}
+}
+
+// RollbackFailedDeploy redeploys the most recently recorded deployment after
+// a deploy has failed for the given reason, and sends a notification about it.
+// Cancelling the given context aborts the rollback.
+func (d *Deployment) RollbackFailedDeploy(ctx context.Context, cli *docker.Client, out io.Writer, cause error) error {
+	var msg = fmt.Sprintf("Deployment of project %s failed: %s", d.project, cause.Error())
+
+	// Record the failed deploy before the rollback replaces its results
+	d.mux.Lock()
+	d.recordFailedDeploy(cause)
+	d.mux.Unlock()
+
+	history, err := d.GetHistory()
+	var target string
+	for _, h := range history {
+		if !h.Failed {
+			target = h.CommitHash
+			break
+		}
+	}
+	if err != nil || target == "" {
+		if notifyErr := d.notifiers.Notify(msg+" - no previous deployment to roll back to",
+			notify.Options{Color: notify.Red}); notifyErr != nil {
+			fmt.Fprintln(out, notifyErr.Error())
+		}
+		return errors.New("no previous deployment to roll back to")
+	}
+
+	// Redeploy last successfully deployed commit
+	fmt.Fprintf(out, "Rolling back to previously deployed commit %s\n", target)
+	deploy, err := d.Deploy(ctx, cli, out, DeployOptions{Commit: target})
+	if err == nil {
+		err = deploy()
+	}
+	if err == nil {
+		err = d.CheckHealth(ctx, cli, out)
+	}
+
+	// Report outcome
+	if err != nil {
+		msg = fmt.Sprintf("%s - rollback to %s failed: %s", msg, target, err.Error())
+		if notifyErr := d.notifiers.Notify(msg, notify.Options{
+			Color: notify.Red,
+		}); notifyErr != nil {
+			fmt.Fprintln(out, notifyErr.Error())
+		}
+		return err
+	}
+	if err := d.UpdateContainerHistory(cli); err != nil {
+		fmt.Fprintln(out, "warning: failed to update container history:", err)
+	}
+	if notifyErr := d.notifiers.Notify(msg+" - rolled back to "+target, notify.Options{
+		Color: notify.Yellow,
+	}); notifyErr != nil {
+		fmt.Fprintln(out, notifyErr.Error())
+	}
+	return nil
+}
+
+// resolveHealthCheckURL points URLs referring to the local machine at the
+// given host address instead, since the daemon runs in its own container
+func resolveHealthCheckURL(raw, host string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid health check URL: %s", err.Error())
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "0.0.0.0":
+		if port := u.Port(); port != "" {
+			u.Host = net.JoinHostPort(host, port)
+		} else {
+			u.Host = host
+		}
+	}
+	return u.String(), nil
+}
+
+// probeContainers checks that all project containers with a HEALTHCHECK have
+// reported themselves as healthy
+func probeContainers(ctx context.Context, cli *docker.Client, project, composeProject string) error {
+	list, err := containers.GetProjectContainers(cli, project, composeProject)
+	if err != nil {
+		return err
+	}
+	var checked int
+	for _, c := range list {
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		if info.State == nil || info.State.Health == nil {
+			continue
+		}
+		checked++
+		switch info.State.Health.Status {
+		case types.Healthy:
+		case types.Unhealthy:
+			return fmt.Errorf("container %s: %w", c.Names[0], errUnhealthy)
+		default:
+			return fmt.Errorf("container %s is %s", c.Names[0], info.State.Health.Status)
+		}
+	}
+	if checked == 0 {
+		return errors.New("no project containers have a HEALTHCHECK configured")
+	}
+	return nil
+}
+
+// probeHTTP checks that the given URL responds with the expected status
+func probeHTTP(ctx context.Context, target string, expected int) error {
+	reqCtx, cancel := context.WithTimeout(ctx, healthCheckRequestDuration)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(reqCtx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != expected {
+		return fmt.Errorf("%s responded with status %d, expected %d",
+			target, resp.StatusCode, expected)
+	}
+	return nil
+}
+
+// probeTCP checks that the given address accepts connections
+func probeTCP(ctx context.Context, address string) error {
+	var dialer = net.Dialer{Timeout: healthCheckRequestDuration}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
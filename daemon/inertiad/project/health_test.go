@@ -0,0 +1,155 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/git"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify/mocks"
+)
+
+func TestValidateHealthCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   *api.HealthCheck
+		wantErr bool
+	}{
+		{"no check", nil, false},
+		{"container", &api.HealthCheck{Type: "container"}, false},
+		{"http", &api.HealthCheck{Type: "http", URL: "http://localhost:8080/health"}, false},
+		{"http without url", &api.HealthCheck{Type: "http"}, true},
+		{"tcp", &api.HealthCheck{Type: "tcp", Port: 8080, Timeout: "30s"}, false},
+		{"tcp with invalid port", &api.HealthCheck{Type: "tcp", Port: 70000}, true},
+		{"invalid timeout", &api.HealthCheck{Type: "container", Timeout: "soon"}, true},
+		{"grace period", &api.HealthCheck{Type: "container", GracePeriod: "10s"}, false},
+		{"invalid grace period", &api.HealthCheck{Type: "container", GracePeriod: "soon"}, true},
+		{"unknown type", &api.HealthCheck{Type: "vibes"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHealthCheck(tt.check)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_resolveHealthCheckURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"localhost with port", "http://localhost:8080/health", "http://172.17.0.1:8080/health"},
+		{"loopback without port", "http://127.0.0.1/health", "http://172.17.0.1/health"},
+		{"remote host", "https://example.com/health", "https://example.com/health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveHealthCheckURL(tt.raw, "172.17.0.1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_probeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, probeHTTP(context.Background(), server.URL+"/health", http.StatusOK))
+	assert.Error(t, probeHTTP(context.Background(), server.URL+"/broken", http.StatusOK))
+	assert.NoError(t, probeHTTP(context.Background(), server.URL+"/broken", http.StatusServiceUnavailable))
+}
+
+func Test_probeTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	var address = l.Addr().String()
+	assert.NoError(t, probeTCP(context.Background(), address))
+
+	l.Close()
+	assert.Error(t, probeTCP(context.Background(), address))
+}
+
+func TestDeployment_CheckHealthNoCheck(t *testing.T) {
+	var d = &Deployment{}
+	assert.NoError(t, d.CheckHealth(context.Background(), nil, os.Stdout))
+}
+
+func TestDeployment_RollbackFailedDeployNoHistory(t *testing.T) {
+	var notifier = &mocks.FakeNotifier{}
+	var d = &Deployment{project: "wow", notifiers: notify.Notifiers{notifier}}
+
+	err := d.RollbackFailedDeploy(context.Background(), nil, os.Stdout, errors.New("oh no"))
+	assert.Error(t, err)
+	assert.Equal(t, 1, notifier.NotifyCallCount())
+	msg, opts := notifier.NotifyArgsForCall(0)
+	assert.Contains(t, msg, "oh no")
+	assert.Equal(t, notify.Red, opts.Color)
+}
+
+func TestDeployment_RollbackFailedDeployCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-rollback")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Set up a deployment with a previous deployment to roll back to
+	var remoteDir = path.Join(dir, "remote")
+	remote, err := gogit.PlainInit(remoteDir, false)
+	assert.NoError(t, err)
+	tree, err := remote.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path.Join(remoteDir, "Dockerfile"), []byte("FROM alpine"), 0644))
+	_, err = tree.Add("Dockerfile")
+	assert.NoError(t, err)
+	hash, err := tree.Commit("first", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "bob", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	repo, err := git.InitializeRepository(remoteDir, git.RepoOptions{
+		Directory: path.Join(dir, "project"),
+		Branch:    "master",
+	}, ioutil.Discard)
+	assert.NoError(t, err)
+	manager, err := NewDataManager(path.Join(dir, "deployment.db"), path.Join(dir, "key"))
+	assert.NoError(t, err)
+	defer manager.Close()
+	assert.NoError(t, manager.AddProjectBuildData("wow", DeploymentMetadata{Hash: hash.String()}))
+
+	var fakeBuilder = newDefaultFakeBuilder(func() error { return nil }, func() error { return nil })
+	var d = &Deployment{
+		project:     "wow",
+		directory:   path.Join(dir, "project"),
+		builder:     fakeBuilder,
+		repo:        repo,
+		dataManager: manager,
+	}
+	d.SetConfig(DeploymentConfig{BuildType: "dockerfile"})
+
+	// Rollbacks should be aborted along with the deploy that triggered them
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = d.RollbackFailedDeploy(ctx, nil, ioutil.Discard, errors.New("oh no"))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 0, fakeBuilder.BuildCallCount())
+}
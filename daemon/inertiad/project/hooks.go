@@ -0,0 +1,88 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	docker "github.com/docker/docker/client"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/build"
+)
+
+const (
+	// HookStagePreDeploy denotes hooks run after the project is built, but
+	// before it is started
+	HookStagePreDeploy = "pre_deploy"
+	// HookStagePostDeploy denotes hooks run after the project is started
+	HookStagePostDeploy = "post_deploy"
+)
+
+// ValidateHooks checks if the given hook configuration is valid
+func ValidateHooks(hooks *api.Hooks) error {
+	if hooks == nil {
+		return nil
+	}
+	for _, stage := range [][]api.Hook{hooks.PreDeploy, hooks.PostDeploy} {
+		for _, hook := range stage {
+			if hook.Name == "" {
+				return fmt.Errorf("hook with command '%s' has no name",
+					strings.Join(hook.Command, " "))
+			}
+			if len(hook.Command) == 0 {
+				return fmt.Errorf("hook '%s' has no command", hook.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// runHooks runs the given hooks in order and records their results. If
+// abortOnFailure is set, no further hooks are run once one fails.
+func (d *Deployment) runHooks(
+	ctx context.Context,
+	cli *docker.Client,
+	out io.Writer,
+	conf build.Config,
+	stage string,
+	hooks []api.Hook,
+	abortOnFailure bool,
+) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "Running %d %s hook(s)...\n", len(hooks), strings.Replace(stage, "_", "-", 1))
+
+	var failed error
+	for _, hook := range hooks {
+		var start = time.Now()
+		err := d.builder.RunHook(ctx, strings.ToLower(d.buildType), build.Hook{
+			Name:    hook.Name,
+			Command: hook.Command,
+			Service: hook.Service,
+		}, conf, cli, out)
+
+		var result = api.HookResult{
+			Name:     hook.Name,
+			Stage:    stage,
+			Success:  err == nil,
+			Duration: time.Since(start).Round(time.Millisecond).String(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			fmt.Fprintln(out, err.Error())
+			if failed == nil {
+				failed = err
+			}
+		}
+		d.hookResults = append(d.hookResults, result)
+
+		if failed != nil && abortOnFailure {
+			break
+		}
+	}
+	return failed
+}
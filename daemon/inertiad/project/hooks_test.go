@@ -0,0 +1,190 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/build"
+)
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   *api.Hooks
+		wantErr bool
+	}{
+		{"no hooks", nil, false},
+		{"valid hooks", &api.Hooks{
+			PreDeploy:  []api.Hook{{Name: "migrate", Command: []string{"make", "migrate"}}},
+			PostDeploy: []api.Hook{{Name: "purge", Command: []string{"make", "purge"}, Service: "web"}},
+		}, false},
+		{"no name", &api.Hooks{
+			PreDeploy: []api.Hook{{Command: []string{"make", "migrate"}}},
+		}, true},
+		{"no command", &api.Hooks{
+			PostDeploy: []api.Hook{{Name: "purge"}},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHooks(tt.hooks)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeployMockHooks(t *testing.T) {
+	var hooks = &api.Hooks{
+		PreDeploy: []api.Hook{
+			{Name: "migrate", Command: []string{"make", "migrate"}},
+			{Name: "seed", Command: []string{"make", "seed"}},
+		},
+		PostDeploy: []api.Hook{
+			{Name: "purge", Command: []string{"make", "purge"}},
+			{Name: "warm", Command: []string{"make", "warm"}},
+		},
+	}
+	tests := []struct {
+		name        string
+		failingHook string
+		wantErr     bool
+		wantResults []api.HookResult
+	}{
+		{"all hooks succeed", "", false, []api.HookResult{
+			{Name: "migrate", Stage: HookStagePreDeploy, Success: true},
+			{Name: "seed", Stage: HookStagePreDeploy, Success: true},
+			{Name: "purge", Stage: HookStagePostDeploy, Success: true},
+			{Name: "warm", Stage: HookStagePostDeploy, Success: true},
+		}},
+		{"pre-deploy hook fails", "migrate", true, []api.HookResult{
+			{Name: "migrate", Stage: HookStagePreDeploy, Success: false, Error: "oh no"},
+		}},
+		{"post-deploy hook fails", "purge", false, []api.HookResult{
+			{Name: "migrate", Stage: HookStagePreDeploy, Success: true},
+			{Name: "seed", Stage: HookStagePreDeploy, Success: true},
+			{Name: "purge", Stage: HookStagePostDeploy, Success: false, Error: "oh no"},
+			{Name: "warm", Stage: HookStagePostDeploy, Success: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deployed bool
+			var fakeBuilder = newDefaultFakeBuilder(
+				func() error { deployed = true; return nil },
+				func() error { return nil })
+			fakeBuilder.RunHookStub = func(
+				_ context.Context, _ string, hook build.Hook, _ build.Config, _ *docker.Client, _ io.Writer,
+			) error {
+				if hook.Name == tt.failingHook {
+					return errors.New("oh no")
+				}
+				return nil
+			}
+			var d = Deployment{
+				directory: "./test/",
+				builder:   fakeBuilder,
+			}
+			d.SetConfig(DeploymentConfig{BuildType: "dockerfile", Hooks: hooks})
+
+			deploy, err := d.Deploy(context.Background(), nil, os.Stdout, DeployOptions{SkipUpdate: true})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NoError(t, deploy())
+				assert.True(t, deployed)
+			}
+
+			// durations vary, so only compare everything else
+			for i := range d.hookResults {
+				d.hookResults[i].Duration = ""
+			}
+			assert.Equal(t, tt.wantResults, d.hookResults)
+		})
+	}
+}
+
+func TestDeployment_failedDeployHistory(t *testing.T) {
+	repo, err := gogit.PlainOpen("../../../")
+	assert.NoError(t, err)
+	var hooks = &api.Hooks{
+		PreDeploy: []api.Hook{{Name: "migrate", Command: []string{"make", "migrate"}}},
+	}
+	tests := []struct {
+		name        string
+		failingHook string
+		deployErr   error
+		wantError   string
+	}{
+		{"pre-deploy hook fails", "migrate", nil, "oh no"},
+		{"deploy fails", "", errors.New("port is already allocated"), "port is already allocated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "inertia-failed-deploy")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			manager, err := NewDataManager(path.Join(dir, "deployment.db"), path.Join(dir, "key"))
+			assert.NoError(t, err)
+			defer manager.destroy()
+
+			var fakeBuilder = newDefaultFakeBuilder(
+				func() error { return tt.deployErr },
+				func() error { return nil })
+			fakeBuilder.RunHookStub = func(
+				_ context.Context, _ string, hook build.Hook, _ build.Config, _ *docker.Client, _ io.Writer,
+			) error {
+				if hook.Name == tt.failingHook {
+					return errors.New("oh no")
+				}
+				return nil
+			}
+			var d = Deployment{
+				project:     "wow",
+				directory:   "./test/",
+				builder:     fakeBuilder,
+				repo:        repo,
+				dataManager: manager,
+			}
+			d.SetConfig(DeploymentConfig{BuildType: "dockerfile", Hooks: hooks})
+
+			deploy, err := d.Deploy(context.Background(), nil, ioutil.Discard, DeployOptions{
+				SkipUpdate: true,
+				BuildID:    "20200102-150405-abcd",
+			})
+			if err == nil {
+				err = deploy()
+			}
+			assert.Error(t, err)
+
+			// Failed deploys should be recorded along with their hooks
+			history, err := d.GetHistory()
+			assert.NoError(t, err)
+			if assert.Len(t, history, 1) {
+				assert.True(t, history[0].Failed)
+				assert.Equal(t, tt.wantError, history[0].Error)
+				assert.Equal(t, "20200102-150405-abcd", history[0].BuildID)
+				if assert.Len(t, history[0].Hooks, 1) {
+					assert.Equal(t, tt.failingHook == "", history[0].Hooks[0].Success)
+				}
+			}
+
+			// Failed deploys should never be rolled back to
+			assert.Error(t, d.RollbackFailedDeploy(context.Background(), nil, ioutil.Discard, errors.New("oh no")))
+		})
+	}
+}
@@ -2,15 +2,30 @@
 package mocks
 
 import (
+	"context"
 	"io"
 	"sync"
 
 	"github.com/docker/docker/client"
 	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
 	"github.com/ubclaunchpad/inertia/daemon/inertiad/project"
 )
 
 type FakeDeployer struct {
+	CheckHealthStub        func(context.Context, *client.Client, io.Writer) error
+	checkHealthMutex       sync.RWMutex
+	checkHealthArgsForCall []struct {
+		arg1 context.Context
+		arg2 *client.Client
+		arg3 io.Writer
+	}
+	checkHealthReturns struct {
+		result1 error
+	}
+	checkHealthReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CompareRemotesStub        func(string) error
 	compareRemotesMutex       sync.RWMutex
 	compareRemotesArgsForCall []struct {
@@ -22,12 +37,26 @@ type FakeDeployer struct {
 	compareRemotesReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeployStub        func(*client.Client, io.Writer, project.DeployOptions) (func() error, error)
+	ControlContainerStub        func(*client.Client, string, project.ContainerAction) error
+	controlContainerMutex       sync.RWMutex
+	controlContainerArgsForCall []struct {
+		arg1 *client.Client
+		arg2 string
+		arg3 project.ContainerAction
+	}
+	controlContainerReturns struct {
+		result1 error
+	}
+	controlContainerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeployStub        func(context.Context, *client.Client, io.Writer, project.DeployOptions) (func() error, error)
 	deployMutex       sync.RWMutex
 	deployArgsForCall []struct {
-		arg1 *client.Client
-		arg2 io.Writer
-		arg3 project.DeployOptions
+		arg1 context.Context
+		arg2 *client.Client
+		arg3 io.Writer
+		arg4 project.DeployOptions
 	}
 	deployReturns struct {
 		result1 func() error
@@ -71,6 +100,20 @@ type FakeDeployer struct {
 	getBranchReturnsOnCall map[int]struct {
 		result1 string
 	}
+	GetChangedFilesStub        func(string, string) ([]string, error)
+	getChangedFilesMutex       sync.RWMutex
+	getChangedFilesArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getChangedFilesReturns struct {
+		result1 []string
+		result2 error
+	}
+	getChangedFilesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	GetDataManagerStub        func() (*project.DeploymentDataManager, bool)
 	getDataManagerMutex       sync.RWMutex
 	getDataManagerArgsForCall []struct {
@@ -83,6 +126,42 @@ type FakeDeployer struct {
 		result1 *project.DeploymentDataManager
 		result2 bool
 	}
+	GetHistoryStub        func() ([]api.DeploymentRecord, error)
+	getHistoryMutex       sync.RWMutex
+	getHistoryArgsForCall []struct {
+	}
+	getHistoryReturns struct {
+		result1 []api.DeploymentRecord
+		result2 error
+	}
+	getHistoryReturnsOnCall map[int]struct {
+		result1 []api.DeploymentRecord
+		result2 error
+	}
+	GetImageStub        func() string
+	getImageMutex       sync.RWMutex
+	getImageArgsForCall []struct {
+	}
+	getImageReturns struct {
+		result1 string
+	}
+	getImageReturnsOnCall map[int]struct {
+		result1 string
+	}
+	GetPreviewConfigStub        func(project.PullRequest, string) (project.DeploymentConfig, error)
+	getPreviewConfigMutex       sync.RWMutex
+	getPreviewConfigArgsForCall []struct {
+		arg1 project.PullRequest
+		arg2 string
+	}
+	getPreviewConfigReturns struct {
+		result1 project.DeploymentConfig
+		result2 error
+	}
+	getPreviewConfigReturnsOnCall map[int]struct {
+		result1 project.DeploymentConfig
+		result2 error
+	}
 	GetStatusStub        func(*client.Client) (api.DeploymentStatus, error)
 	getStatusMutex       sync.RWMutex
 	getStatusArgsForCall []struct {
@@ -96,6 +175,26 @@ type FakeDeployer struct {
 		result1 api.DeploymentStatus
 		result2 error
 	}
+	GetTriggersStub        func() *api.Triggers
+	getTriggersMutex       sync.RWMutex
+	getTriggersArgsForCall []struct {
+	}
+	getTriggersReturns struct {
+		result1 *api.Triggers
+	}
+	getTriggersReturnsOnCall map[int]struct {
+		result1 *api.Triggers
+	}
+	GetWatchPathsStub        func() []string
+	getWatchPathsMutex       sync.RWMutex
+	getWatchPathsArgsForCall []struct {
+	}
+	getWatchPathsReturns struct {
+		result1 []string
+	}
+	getWatchPathsReturnsOnCall map[int]struct {
+		result1 []string
+	}
 	InitializeStub        func(project.DeploymentConfig, io.Writer) error
 	initializeMutex       sync.RWMutex
 	initializeArgsForCall []struct {
@@ -108,6 +207,51 @@ type FakeDeployer struct {
 	initializeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	IsPreviewStub        func() bool
+	isPreviewMutex       sync.RWMutex
+	isPreviewArgsForCall []struct {
+	}
+	isPreviewReturns struct {
+		result1 bool
+	}
+	isPreviewReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	LoadStub        func() error
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct {
+	}
+	loadReturns struct {
+		result1 error
+	}
+	loadReturnsOnCall map[int]struct {
+		result1 error
+	}
+	NotifyStub        func(string, notify.Options) error
+	notifyMutex       sync.RWMutex
+	notifyArgsForCall []struct {
+		arg1 string
+		arg2 notify.Options
+	}
+	notifyReturns struct {
+		result1 error
+	}
+	notifyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PlanStub        func(project.DeploymentConfig) (api.DeploymentPlan, error)
+	planMutex       sync.RWMutex
+	planArgsForCall []struct {
+		arg1 project.DeploymentConfig
+	}
+	planReturns struct {
+		result1 api.DeploymentPlan
+		result2 error
+	}
+	planReturnsOnCall map[int]struct {
+		result1 api.DeploymentPlan
+		result2 error
+	}
 	PruneStub        func(*client.Client, io.Writer) error
 	pruneMutex       sync.RWMutex
 	pruneArgsForCall []struct {
@@ -120,6 +264,20 @@ type FakeDeployer struct {
 	pruneReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RollbackFailedDeployStub        func(context.Context, *client.Client, io.Writer, error) error
+	rollbackFailedDeployMutex       sync.RWMutex
+	rollbackFailedDeployArgsForCall []struct {
+		arg1 context.Context
+		arg2 *client.Client
+		arg3 io.Writer
+		arg4 error
+	}
+	rollbackFailedDeployReturns struct {
+		result1 error
+	}
+	rollbackFailedDeployReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetConfigStub        func(project.DeploymentConfig)
 	setConfigMutex       sync.RWMutex
 	setConfigArgsForCall []struct {
@@ -136,10 +294,11 @@ type FakeDeployer struct {
 	updateContainerHistoryReturnsOnCall map[int]struct {
 		result1 error
 	}
-	WatchStub        func(*client.Client) (<-chan string, <-chan error)
+	WatchStub        func(context.Context, *client.Client) (<-chan string, <-chan error)
 	watchMutex       sync.RWMutex
 	watchArgsForCall []struct {
-		arg1 *client.Client
+		arg1 context.Context
+		arg2 *client.Client
 	}
 	watchReturns struct {
 		result1 <-chan string
@@ -153,21 +312,85 @@ type FakeDeployer struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeDeployer) CheckHealth(arg1 context.Context, arg2 *client.Client, arg3 io.Writer) error {
+	fake.checkHealthMutex.Lock()
+	ret, specificReturn := fake.checkHealthReturnsOnCall[len(fake.checkHealthArgsForCall)]
+	fake.checkHealthArgsForCall = append(fake.checkHealthArgsForCall, struct {
+		arg1 context.Context
+		arg2 *client.Client
+		arg3 io.Writer
+	}{arg1, arg2, arg3})
+	stub := fake.CheckHealthStub
+	fakeReturns := fake.checkHealthReturns
+	fake.recordInvocation("CheckHealth", []interface{}{arg1, arg2, arg3})
+	fake.checkHealthMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) CheckHealthCallCount() int {
+	fake.checkHealthMutex.RLock()
+	defer fake.checkHealthMutex.RUnlock()
+	return len(fake.checkHealthArgsForCall)
+}
+
+func (fake *FakeDeployer) CheckHealthCalls(stub func(context.Context, *client.Client, io.Writer) error) {
+	fake.checkHealthMutex.Lock()
+	defer fake.checkHealthMutex.Unlock()
+	fake.CheckHealthStub = stub
+}
+
+func (fake *FakeDeployer) CheckHealthArgsForCall(i int) (context.Context, *client.Client, io.Writer) {
+	fake.checkHealthMutex.RLock()
+	defer fake.checkHealthMutex.RUnlock()
+	argsForCall := fake.checkHealthArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDeployer) CheckHealthReturns(result1 error) {
+	fake.checkHealthMutex.Lock()
+	defer fake.checkHealthMutex.Unlock()
+	fake.CheckHealthStub = nil
+	fake.checkHealthReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) CheckHealthReturnsOnCall(i int, result1 error) {
+	fake.checkHealthMutex.Lock()
+	defer fake.checkHealthMutex.Unlock()
+	fake.CheckHealthStub = nil
+	if fake.checkHealthReturnsOnCall == nil {
+		fake.checkHealthReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkHealthReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeDeployer) CompareRemotes(arg1 string) error {
 	fake.compareRemotesMutex.Lock()
 	ret, specificReturn := fake.compareRemotesReturnsOnCall[len(fake.compareRemotesArgsForCall)]
 	fake.compareRemotesArgsForCall = append(fake.compareRemotesArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.CompareRemotesStub
+	fakeReturns := fake.compareRemotesReturns
 	fake.recordInvocation("CompareRemotes", []interface{}{arg1})
 	fake.compareRemotesMutex.Unlock()
-	if fake.CompareRemotesStub != nil {
-		return fake.CompareRemotesStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.compareRemotesReturns
 	return fakeReturns.result1
 }
 
@@ -213,23 +436,88 @@ func (fake *FakeDeployer) CompareRemotesReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeDeployer) Deploy(arg1 *client.Client, arg2 io.Writer, arg3 project.DeployOptions) (func() error, error) {
+func (fake *FakeDeployer) ControlContainer(arg1 *client.Client, arg2 string, arg3 project.ContainerAction) error {
+	fake.controlContainerMutex.Lock()
+	ret, specificReturn := fake.controlContainerReturnsOnCall[len(fake.controlContainerArgsForCall)]
+	fake.controlContainerArgsForCall = append(fake.controlContainerArgsForCall, struct {
+		arg1 *client.Client
+		arg2 string
+		arg3 project.ContainerAction
+	}{arg1, arg2, arg3})
+	stub := fake.ControlContainerStub
+	fakeReturns := fake.controlContainerReturns
+	fake.recordInvocation("ControlContainer", []interface{}{arg1, arg2, arg3})
+	fake.controlContainerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) ControlContainerCallCount() int {
+	fake.controlContainerMutex.RLock()
+	defer fake.controlContainerMutex.RUnlock()
+	return len(fake.controlContainerArgsForCall)
+}
+
+func (fake *FakeDeployer) ControlContainerCalls(stub func(*client.Client, string, project.ContainerAction) error) {
+	fake.controlContainerMutex.Lock()
+	defer fake.controlContainerMutex.Unlock()
+	fake.ControlContainerStub = stub
+}
+
+func (fake *FakeDeployer) ControlContainerArgsForCall(i int) (*client.Client, string, project.ContainerAction) {
+	fake.controlContainerMutex.RLock()
+	defer fake.controlContainerMutex.RUnlock()
+	argsForCall := fake.controlContainerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDeployer) ControlContainerReturns(result1 error) {
+	fake.controlContainerMutex.Lock()
+	defer fake.controlContainerMutex.Unlock()
+	fake.ControlContainerStub = nil
+	fake.controlContainerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) ControlContainerReturnsOnCall(i int, result1 error) {
+	fake.controlContainerMutex.Lock()
+	defer fake.controlContainerMutex.Unlock()
+	fake.ControlContainerStub = nil
+	if fake.controlContainerReturnsOnCall == nil {
+		fake.controlContainerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.controlContainerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) Deploy(arg1 context.Context, arg2 *client.Client, arg3 io.Writer, arg4 project.DeployOptions) (func() error, error) {
 	fake.deployMutex.Lock()
 	ret, specificReturn := fake.deployReturnsOnCall[len(fake.deployArgsForCall)]
 	fake.deployArgsForCall = append(fake.deployArgsForCall, struct {
-		arg1 *client.Client
-		arg2 io.Writer
-		arg3 project.DeployOptions
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("Deploy", []interface{}{arg1, arg2, arg3})
+		arg1 context.Context
+		arg2 *client.Client
+		arg3 io.Writer
+		arg4 project.DeployOptions
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.DeployStub
+	fakeReturns := fake.deployReturns
+	fake.recordInvocation("Deploy", []interface{}{arg1, arg2, arg3, arg4})
 	fake.deployMutex.Unlock()
-	if fake.DeployStub != nil {
-		return fake.DeployStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.deployReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -239,17 +527,17 @@ func (fake *FakeDeployer) DeployCallCount() int {
 	return len(fake.deployArgsForCall)
 }
 
-func (fake *FakeDeployer) DeployCalls(stub func(*client.Client, io.Writer, project.DeployOptions) (func() error, error)) {
+func (fake *FakeDeployer) DeployCalls(stub func(context.Context, *client.Client, io.Writer, project.DeployOptions) (func() error, error)) {
 	fake.deployMutex.Lock()
 	defer fake.deployMutex.Unlock()
 	fake.DeployStub = stub
 }
 
-func (fake *FakeDeployer) DeployArgsForCall(i int) (*client.Client, io.Writer, project.DeployOptions) {
+func (fake *FakeDeployer) DeployArgsForCall(i int) (context.Context, *client.Client, io.Writer, project.DeployOptions) {
 	fake.deployMutex.RLock()
 	defer fake.deployMutex.RUnlock()
 	argsForCall := fake.deployArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeDeployer) DeployReturns(result1 func() error, result2 error) {
@@ -285,15 +573,16 @@ func (fake *FakeDeployer) Destroy(arg1 *client.Client, arg2 io.Writer) error {
 		arg1 *client.Client
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.DestroyStub
+	fakeReturns := fake.destroyReturns
 	fake.recordInvocation("Destroy", []interface{}{arg1, arg2})
 	fake.destroyMutex.Unlock()
-	if fake.DestroyStub != nil {
-		return fake.DestroyStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.destroyReturns
 	return fakeReturns.result1
 }
 
@@ -346,15 +635,16 @@ func (fake *FakeDeployer) Down(arg1 *client.Client, arg2 io.Writer) error {
 		arg1 *client.Client
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.DownStub
+	fakeReturns := fake.downReturns
 	fake.recordInvocation("Down", []interface{}{arg1, arg2})
 	fake.downMutex.Unlock()
-	if fake.DownStub != nil {
-		return fake.DownStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.downReturns
 	return fakeReturns.result1
 }
 
@@ -405,15 +695,16 @@ func (fake *FakeDeployer) GetBranch() string {
 	ret, specificReturn := fake.getBranchReturnsOnCall[len(fake.getBranchArgsForCall)]
 	fake.getBranchArgsForCall = append(fake.getBranchArgsForCall, struct {
 	}{})
+	stub := fake.GetBranchStub
+	fakeReturns := fake.getBranchReturns
 	fake.recordInvocation("GetBranch", []interface{}{})
 	fake.getBranchMutex.Unlock()
-	if fake.GetBranchStub != nil {
-		return fake.GetBranchStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.getBranchReturns
 	return fakeReturns.result1
 }
 
@@ -452,20 +743,86 @@ func (fake *FakeDeployer) GetBranchReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeDeployer) GetChangedFiles(arg1 string, arg2 string) ([]string, error) {
+	fake.getChangedFilesMutex.Lock()
+	ret, specificReturn := fake.getChangedFilesReturnsOnCall[len(fake.getChangedFilesArgsForCall)]
+	fake.getChangedFilesArgsForCall = append(fake.getChangedFilesArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetChangedFilesStub
+	fakeReturns := fake.getChangedFilesReturns
+	fake.recordInvocation("GetChangedFiles", []interface{}{arg1, arg2})
+	fake.getChangedFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDeployer) GetChangedFilesCallCount() int {
+	fake.getChangedFilesMutex.RLock()
+	defer fake.getChangedFilesMutex.RUnlock()
+	return len(fake.getChangedFilesArgsForCall)
+}
+
+func (fake *FakeDeployer) GetChangedFilesCalls(stub func(string, string) ([]string, error)) {
+	fake.getChangedFilesMutex.Lock()
+	defer fake.getChangedFilesMutex.Unlock()
+	fake.GetChangedFilesStub = stub
+}
+
+func (fake *FakeDeployer) GetChangedFilesArgsForCall(i int) (string, string) {
+	fake.getChangedFilesMutex.RLock()
+	defer fake.getChangedFilesMutex.RUnlock()
+	argsForCall := fake.getChangedFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDeployer) GetChangedFilesReturns(result1 []string, result2 error) {
+	fake.getChangedFilesMutex.Lock()
+	defer fake.getChangedFilesMutex.Unlock()
+	fake.GetChangedFilesStub = nil
+	fake.getChangedFilesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeployer) GetChangedFilesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.getChangedFilesMutex.Lock()
+	defer fake.getChangedFilesMutex.Unlock()
+	fake.GetChangedFilesStub = nil
+	if fake.getChangedFilesReturnsOnCall == nil {
+		fake.getChangedFilesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.getChangedFilesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDeployer) GetDataManager() (*project.DeploymentDataManager, bool) {
 	fake.getDataManagerMutex.Lock()
 	ret, specificReturn := fake.getDataManagerReturnsOnCall[len(fake.getDataManagerArgsForCall)]
 	fake.getDataManagerArgsForCall = append(fake.getDataManagerArgsForCall, struct {
 	}{})
+	stub := fake.GetDataManagerStub
+	fakeReturns := fake.getDataManagerReturns
 	fake.recordInvocation("GetDataManager", []interface{}{})
 	fake.getDataManagerMutex.Unlock()
-	if fake.GetDataManagerStub != nil {
-		return fake.GetDataManagerStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getDataManagerReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -507,21 +864,196 @@ func (fake *FakeDeployer) GetDataManagerReturnsOnCall(i int, result1 *project.De
 	}{result1, result2}
 }
 
+func (fake *FakeDeployer) GetHistory() ([]api.DeploymentRecord, error) {
+	fake.getHistoryMutex.Lock()
+	ret, specificReturn := fake.getHistoryReturnsOnCall[len(fake.getHistoryArgsForCall)]
+	fake.getHistoryArgsForCall = append(fake.getHistoryArgsForCall, struct {
+	}{})
+	stub := fake.GetHistoryStub
+	fakeReturns := fake.getHistoryReturns
+	fake.recordInvocation("GetHistory", []interface{}{})
+	fake.getHistoryMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDeployer) GetHistoryCallCount() int {
+	fake.getHistoryMutex.RLock()
+	defer fake.getHistoryMutex.RUnlock()
+	return len(fake.getHistoryArgsForCall)
+}
+
+func (fake *FakeDeployer) GetHistoryCalls(stub func() ([]api.DeploymentRecord, error)) {
+	fake.getHistoryMutex.Lock()
+	defer fake.getHistoryMutex.Unlock()
+	fake.GetHistoryStub = stub
+}
+
+func (fake *FakeDeployer) GetHistoryReturns(result1 []api.DeploymentRecord, result2 error) {
+	fake.getHistoryMutex.Lock()
+	defer fake.getHistoryMutex.Unlock()
+	fake.GetHistoryStub = nil
+	fake.getHistoryReturns = struct {
+		result1 []api.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeployer) GetHistoryReturnsOnCall(i int, result1 []api.DeploymentRecord, result2 error) {
+	fake.getHistoryMutex.Lock()
+	defer fake.getHistoryMutex.Unlock()
+	fake.GetHistoryStub = nil
+	if fake.getHistoryReturnsOnCall == nil {
+		fake.getHistoryReturnsOnCall = make(map[int]struct {
+			result1 []api.DeploymentRecord
+			result2 error
+		})
+	}
+	fake.getHistoryReturnsOnCall[i] = struct {
+		result1 []api.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeployer) GetImage() string {
+	fake.getImageMutex.Lock()
+	ret, specificReturn := fake.getImageReturnsOnCall[len(fake.getImageArgsForCall)]
+	fake.getImageArgsForCall = append(fake.getImageArgsForCall, struct {
+	}{})
+	stub := fake.GetImageStub
+	fakeReturns := fake.getImageReturns
+	fake.recordInvocation("GetImage", []interface{}{})
+	fake.getImageMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) GetImageCallCount() int {
+	fake.getImageMutex.RLock()
+	defer fake.getImageMutex.RUnlock()
+	return len(fake.getImageArgsForCall)
+}
+
+func (fake *FakeDeployer) GetImageCalls(stub func() string) {
+	fake.getImageMutex.Lock()
+	defer fake.getImageMutex.Unlock()
+	fake.GetImageStub = stub
+}
+
+func (fake *FakeDeployer) GetImageReturns(result1 string) {
+	fake.getImageMutex.Lock()
+	defer fake.getImageMutex.Unlock()
+	fake.GetImageStub = nil
+	fake.getImageReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDeployer) GetImageReturnsOnCall(i int, result1 string) {
+	fake.getImageMutex.Lock()
+	defer fake.getImageMutex.Unlock()
+	fake.GetImageStub = nil
+	if fake.getImageReturnsOnCall == nil {
+		fake.getImageReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.getImageReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeDeployer) GetPreviewConfig(arg1 project.PullRequest, arg2 string) (project.DeploymentConfig, error) {
+	fake.getPreviewConfigMutex.Lock()
+	ret, specificReturn := fake.getPreviewConfigReturnsOnCall[len(fake.getPreviewConfigArgsForCall)]
+	fake.getPreviewConfigArgsForCall = append(fake.getPreviewConfigArgsForCall, struct {
+		arg1 project.PullRequest
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPreviewConfigStub
+	fakeReturns := fake.getPreviewConfigReturns
+	fake.recordInvocation("GetPreviewConfig", []interface{}{arg1, arg2})
+	fake.getPreviewConfigMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDeployer) GetPreviewConfigCallCount() int {
+	fake.getPreviewConfigMutex.RLock()
+	defer fake.getPreviewConfigMutex.RUnlock()
+	return len(fake.getPreviewConfigArgsForCall)
+}
+
+func (fake *FakeDeployer) GetPreviewConfigCalls(stub func(project.PullRequest, string) (project.DeploymentConfig, error)) {
+	fake.getPreviewConfigMutex.Lock()
+	defer fake.getPreviewConfigMutex.Unlock()
+	fake.GetPreviewConfigStub = stub
+}
+
+func (fake *FakeDeployer) GetPreviewConfigArgsForCall(i int) (project.PullRequest, string) {
+	fake.getPreviewConfigMutex.RLock()
+	defer fake.getPreviewConfigMutex.RUnlock()
+	argsForCall := fake.getPreviewConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDeployer) GetPreviewConfigReturns(result1 project.DeploymentConfig, result2 error) {
+	fake.getPreviewConfigMutex.Lock()
+	defer fake.getPreviewConfigMutex.Unlock()
+	fake.GetPreviewConfigStub = nil
+	fake.getPreviewConfigReturns = struct {
+		result1 project.DeploymentConfig
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeployer) GetPreviewConfigReturnsOnCall(i int, result1 project.DeploymentConfig, result2 error) {
+	fake.getPreviewConfigMutex.Lock()
+	defer fake.getPreviewConfigMutex.Unlock()
+	fake.GetPreviewConfigStub = nil
+	if fake.getPreviewConfigReturnsOnCall == nil {
+		fake.getPreviewConfigReturnsOnCall = make(map[int]struct {
+			result1 project.DeploymentConfig
+			result2 error
+		})
+	}
+	fake.getPreviewConfigReturnsOnCall[i] = struct {
+		result1 project.DeploymentConfig
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDeployer) GetStatus(arg1 *client.Client) (api.DeploymentStatus, error) {
 	fake.getStatusMutex.Lock()
 	ret, specificReturn := fake.getStatusReturnsOnCall[len(fake.getStatusArgsForCall)]
 	fake.getStatusArgsForCall = append(fake.getStatusArgsForCall, struct {
 		arg1 *client.Client
 	}{arg1})
+	stub := fake.GetStatusStub
+	fakeReturns := fake.getStatusReturns
 	fake.recordInvocation("GetStatus", []interface{}{arg1})
 	fake.getStatusMutex.Unlock()
-	if fake.GetStatusStub != nil {
-		return fake.GetStatusStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getStatusReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -570,6 +1102,112 @@ func (fake *FakeDeployer) GetStatusReturnsOnCall(i int, result1 api.DeploymentSt
 	}{result1, result2}
 }
 
+func (fake *FakeDeployer) GetTriggers() *api.Triggers {
+	fake.getTriggersMutex.Lock()
+	ret, specificReturn := fake.getTriggersReturnsOnCall[len(fake.getTriggersArgsForCall)]
+	fake.getTriggersArgsForCall = append(fake.getTriggersArgsForCall, struct {
+	}{})
+	stub := fake.GetTriggersStub
+	fakeReturns := fake.getTriggersReturns
+	fake.recordInvocation("GetTriggers", []interface{}{})
+	fake.getTriggersMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) GetTriggersCallCount() int {
+	fake.getTriggersMutex.RLock()
+	defer fake.getTriggersMutex.RUnlock()
+	return len(fake.getTriggersArgsForCall)
+}
+
+func (fake *FakeDeployer) GetTriggersCalls(stub func() *api.Triggers) {
+	fake.getTriggersMutex.Lock()
+	defer fake.getTriggersMutex.Unlock()
+	fake.GetTriggersStub = stub
+}
+
+func (fake *FakeDeployer) GetTriggersReturns(result1 *api.Triggers) {
+	fake.getTriggersMutex.Lock()
+	defer fake.getTriggersMutex.Unlock()
+	fake.GetTriggersStub = nil
+	fake.getTriggersReturns = struct {
+		result1 *api.Triggers
+	}{result1}
+}
+
+func (fake *FakeDeployer) GetTriggersReturnsOnCall(i int, result1 *api.Triggers) {
+	fake.getTriggersMutex.Lock()
+	defer fake.getTriggersMutex.Unlock()
+	fake.GetTriggersStub = nil
+	if fake.getTriggersReturnsOnCall == nil {
+		fake.getTriggersReturnsOnCall = make(map[int]struct {
+			result1 *api.Triggers
+		})
+	}
+	fake.getTriggersReturnsOnCall[i] = struct {
+		result1 *api.Triggers
+	}{result1}
+}
+
+func (fake *FakeDeployer) GetWatchPaths() []string {
+	fake.getWatchPathsMutex.Lock()
+	ret, specificReturn := fake.getWatchPathsReturnsOnCall[len(fake.getWatchPathsArgsForCall)]
+	fake.getWatchPathsArgsForCall = append(fake.getWatchPathsArgsForCall, struct {
+	}{})
+	stub := fake.GetWatchPathsStub
+	fakeReturns := fake.getWatchPathsReturns
+	fake.recordInvocation("GetWatchPaths", []interface{}{})
+	fake.getWatchPathsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) GetWatchPathsCallCount() int {
+	fake.getWatchPathsMutex.RLock()
+	defer fake.getWatchPathsMutex.RUnlock()
+	return len(fake.getWatchPathsArgsForCall)
+}
+
+func (fake *FakeDeployer) GetWatchPathsCalls(stub func() []string) {
+	fake.getWatchPathsMutex.Lock()
+	defer fake.getWatchPathsMutex.Unlock()
+	fake.GetWatchPathsStub = stub
+}
+
+func (fake *FakeDeployer) GetWatchPathsReturns(result1 []string) {
+	fake.getWatchPathsMutex.Lock()
+	defer fake.getWatchPathsMutex.Unlock()
+	fake.GetWatchPathsStub = nil
+	fake.getWatchPathsReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeDeployer) GetWatchPathsReturnsOnCall(i int, result1 []string) {
+	fake.getWatchPathsMutex.Lock()
+	defer fake.getWatchPathsMutex.Unlock()
+	fake.GetWatchPathsStub = nil
+	if fake.getWatchPathsReturnsOnCall == nil {
+		fake.getWatchPathsReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.getWatchPathsReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
 func (fake *FakeDeployer) Initialize(arg1 project.DeploymentConfig, arg2 io.Writer) error {
 	fake.initializeMutex.Lock()
 	ret, specificReturn := fake.initializeReturnsOnCall[len(fake.initializeArgsForCall)]
@@ -577,15 +1215,16 @@ func (fake *FakeDeployer) Initialize(arg1 project.DeploymentConfig, arg2 io.Writ
 		arg1 project.DeploymentConfig
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.InitializeStub
+	fakeReturns := fake.initializeReturns
 	fake.recordInvocation("Initialize", []interface{}{arg1, arg2})
 	fake.initializeMutex.Unlock()
-	if fake.InitializeStub != nil {
-		return fake.InitializeStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.initializeReturns
 	return fakeReturns.result1
 }
 
@@ -631,6 +1270,238 @@ func (fake *FakeDeployer) InitializeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeDeployer) IsPreview() bool {
+	fake.isPreviewMutex.Lock()
+	ret, specificReturn := fake.isPreviewReturnsOnCall[len(fake.isPreviewArgsForCall)]
+	fake.isPreviewArgsForCall = append(fake.isPreviewArgsForCall, struct {
+	}{})
+	stub := fake.IsPreviewStub
+	fakeReturns := fake.isPreviewReturns
+	fake.recordInvocation("IsPreview", []interface{}{})
+	fake.isPreviewMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) IsPreviewCallCount() int {
+	fake.isPreviewMutex.RLock()
+	defer fake.isPreviewMutex.RUnlock()
+	return len(fake.isPreviewArgsForCall)
+}
+
+func (fake *FakeDeployer) IsPreviewCalls(stub func() bool) {
+	fake.isPreviewMutex.Lock()
+	defer fake.isPreviewMutex.Unlock()
+	fake.IsPreviewStub = stub
+}
+
+func (fake *FakeDeployer) IsPreviewReturns(result1 bool) {
+	fake.isPreviewMutex.Lock()
+	defer fake.isPreviewMutex.Unlock()
+	fake.IsPreviewStub = nil
+	fake.isPreviewReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDeployer) IsPreviewReturnsOnCall(i int, result1 bool) {
+	fake.isPreviewMutex.Lock()
+	defer fake.isPreviewMutex.Unlock()
+	fake.IsPreviewStub = nil
+	if fake.isPreviewReturnsOnCall == nil {
+		fake.isPreviewReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.isPreviewReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeDeployer) Load() error {
+	fake.loadMutex.Lock()
+	ret, specificReturn := fake.loadReturnsOnCall[len(fake.loadArgsForCall)]
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct {
+	}{})
+	stub := fake.LoadStub
+	fakeReturns := fake.loadReturns
+	fake.recordInvocation("Load", []interface{}{})
+	fake.loadMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *FakeDeployer) LoadCalls(stub func() error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = stub
+}
+
+func (fake *FakeDeployer) LoadReturns(result1 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) LoadReturnsOnCall(i int, result1 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	if fake.loadReturnsOnCall == nil {
+		fake.loadReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.loadReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) Notify(arg1 string, arg2 notify.Options) error {
+	fake.notifyMutex.Lock()
+	ret, specificReturn := fake.notifyReturnsOnCall[len(fake.notifyArgsForCall)]
+	fake.notifyArgsForCall = append(fake.notifyArgsForCall, struct {
+		arg1 string
+		arg2 notify.Options
+	}{arg1, arg2})
+	stub := fake.NotifyStub
+	fakeReturns := fake.notifyReturns
+	fake.recordInvocation("Notify", []interface{}{arg1, arg2})
+	fake.notifyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) NotifyCallCount() int {
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	return len(fake.notifyArgsForCall)
+}
+
+func (fake *FakeDeployer) NotifyCalls(stub func(string, notify.Options) error) {
+	fake.notifyMutex.Lock()
+	defer fake.notifyMutex.Unlock()
+	fake.NotifyStub = stub
+}
+
+func (fake *FakeDeployer) NotifyArgsForCall(i int) (string, notify.Options) {
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	argsForCall := fake.notifyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDeployer) NotifyReturns(result1 error) {
+	fake.notifyMutex.Lock()
+	defer fake.notifyMutex.Unlock()
+	fake.NotifyStub = nil
+	fake.notifyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) NotifyReturnsOnCall(i int, result1 error) {
+	fake.notifyMutex.Lock()
+	defer fake.notifyMutex.Unlock()
+	fake.NotifyStub = nil
+	if fake.notifyReturnsOnCall == nil {
+		fake.notifyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.notifyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) Plan(arg1 project.DeploymentConfig) (api.DeploymentPlan, error) {
+	fake.planMutex.Lock()
+	ret, specificReturn := fake.planReturnsOnCall[len(fake.planArgsForCall)]
+	fake.planArgsForCall = append(fake.planArgsForCall, struct {
+		arg1 project.DeploymentConfig
+	}{arg1})
+	stub := fake.PlanStub
+	fakeReturns := fake.planReturns
+	fake.recordInvocation("Plan", []interface{}{arg1})
+	fake.planMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDeployer) PlanCallCount() int {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	return len(fake.planArgsForCall)
+}
+
+func (fake *FakeDeployer) PlanCalls(stub func(project.DeploymentConfig) (api.DeploymentPlan, error)) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = stub
+}
+
+func (fake *FakeDeployer) PlanArgsForCall(i int) project.DeploymentConfig {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	argsForCall := fake.planArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDeployer) PlanReturns(result1 api.DeploymentPlan, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	fake.planReturns = struct {
+		result1 api.DeploymentPlan
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeployer) PlanReturnsOnCall(i int, result1 api.DeploymentPlan, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	if fake.planReturnsOnCall == nil {
+		fake.planReturnsOnCall = make(map[int]struct {
+			result1 api.DeploymentPlan
+			result2 error
+		})
+	}
+	fake.planReturnsOnCall[i] = struct {
+		result1 api.DeploymentPlan
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDeployer) Prune(arg1 *client.Client, arg2 io.Writer) error {
 	fake.pruneMutex.Lock()
 	ret, specificReturn := fake.pruneReturnsOnCall[len(fake.pruneArgsForCall)]
@@ -638,15 +1509,16 @@ func (fake *FakeDeployer) Prune(arg1 *client.Client, arg2 io.Writer) error {
 		arg1 *client.Client
 		arg2 io.Writer
 	}{arg1, arg2})
+	stub := fake.PruneStub
+	fakeReturns := fake.pruneReturns
 	fake.recordInvocation("Prune", []interface{}{arg1, arg2})
 	fake.pruneMutex.Unlock()
-	if fake.PruneStub != nil {
-		return fake.PruneStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.pruneReturns
 	return fakeReturns.result1
 }
 
@@ -692,14 +1564,79 @@ func (fake *FakeDeployer) PruneReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeDeployer) RollbackFailedDeploy(arg1 context.Context, arg2 *client.Client, arg3 io.Writer, arg4 error) error {
+	fake.rollbackFailedDeployMutex.Lock()
+	ret, specificReturn := fake.rollbackFailedDeployReturnsOnCall[len(fake.rollbackFailedDeployArgsForCall)]
+	fake.rollbackFailedDeployArgsForCall = append(fake.rollbackFailedDeployArgsForCall, struct {
+		arg1 context.Context
+		arg2 *client.Client
+		arg3 io.Writer
+		arg4 error
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RollbackFailedDeployStub
+	fakeReturns := fake.rollbackFailedDeployReturns
+	fake.recordInvocation("RollbackFailedDeploy", []interface{}{arg1, arg2, arg3, arg4})
+	fake.rollbackFailedDeployMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeployer) RollbackFailedDeployCallCount() int {
+	fake.rollbackFailedDeployMutex.RLock()
+	defer fake.rollbackFailedDeployMutex.RUnlock()
+	return len(fake.rollbackFailedDeployArgsForCall)
+}
+
+func (fake *FakeDeployer) RollbackFailedDeployCalls(stub func(context.Context, *client.Client, io.Writer, error) error) {
+	fake.rollbackFailedDeployMutex.Lock()
+	defer fake.rollbackFailedDeployMutex.Unlock()
+	fake.RollbackFailedDeployStub = stub
+}
+
+func (fake *FakeDeployer) RollbackFailedDeployArgsForCall(i int) (context.Context, *client.Client, io.Writer, error) {
+	fake.rollbackFailedDeployMutex.RLock()
+	defer fake.rollbackFailedDeployMutex.RUnlock()
+	argsForCall := fake.rollbackFailedDeployArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDeployer) RollbackFailedDeployReturns(result1 error) {
+	fake.rollbackFailedDeployMutex.Lock()
+	defer fake.rollbackFailedDeployMutex.Unlock()
+	fake.RollbackFailedDeployStub = nil
+	fake.rollbackFailedDeployReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeployer) RollbackFailedDeployReturnsOnCall(i int, result1 error) {
+	fake.rollbackFailedDeployMutex.Lock()
+	defer fake.rollbackFailedDeployMutex.Unlock()
+	fake.RollbackFailedDeployStub = nil
+	if fake.rollbackFailedDeployReturnsOnCall == nil {
+		fake.rollbackFailedDeployReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rollbackFailedDeployReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeDeployer) SetConfig(arg1 project.DeploymentConfig) {
 	fake.setConfigMutex.Lock()
 	fake.setConfigArgsForCall = append(fake.setConfigArgsForCall, struct {
 		arg1 project.DeploymentConfig
 	}{arg1})
+	stub := fake.SetConfigStub
 	fake.recordInvocation("SetConfig", []interface{}{arg1})
 	fake.setConfigMutex.Unlock()
-	if fake.SetConfigStub != nil {
+	if stub != nil {
 		fake.SetConfigStub(arg1)
 	}
 }
@@ -729,15 +1666,16 @@ func (fake *FakeDeployer) UpdateContainerHistory(arg1 *client.Client) error {
 	fake.updateContainerHistoryArgsForCall = append(fake.updateContainerHistoryArgsForCall, struct {
 		arg1 *client.Client
 	}{arg1})
+	stub := fake.UpdateContainerHistoryStub
+	fakeReturns := fake.updateContainerHistoryReturns
 	fake.recordInvocation("UpdateContainerHistory", []interface{}{arg1})
 	fake.updateContainerHistoryMutex.Unlock()
-	if fake.UpdateContainerHistoryStub != nil {
-		return fake.UpdateContainerHistoryStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.updateContainerHistoryReturns
 	return fakeReturns.result1
 }
 
@@ -783,21 +1721,23 @@ func (fake *FakeDeployer) UpdateContainerHistoryReturnsOnCall(i int, result1 err
 	}{result1}
 }
 
-func (fake *FakeDeployer) Watch(arg1 *client.Client) (<-chan string, <-chan error) {
+func (fake *FakeDeployer) Watch(arg1 context.Context, arg2 *client.Client) (<-chan string, <-chan error) {
 	fake.watchMutex.Lock()
 	ret, specificReturn := fake.watchReturnsOnCall[len(fake.watchArgsForCall)]
 	fake.watchArgsForCall = append(fake.watchArgsForCall, struct {
-		arg1 *client.Client
-	}{arg1})
-	fake.recordInvocation("Watch", []interface{}{arg1})
+		arg1 context.Context
+		arg2 *client.Client
+	}{arg1, arg2})
+	stub := fake.WatchStub
+	fakeReturns := fake.watchReturns
+	fake.recordInvocation("Watch", []interface{}{arg1, arg2})
 	fake.watchMutex.Unlock()
-	if fake.WatchStub != nil {
-		return fake.WatchStub(arg1)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.watchReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -807,17 +1747,17 @@ func (fake *FakeDeployer) WatchCallCount() int {
 	return len(fake.watchArgsForCall)
 }
 
-func (fake *FakeDeployer) WatchCalls(stub func(*client.Client) (<-chan string, <-chan error)) {
+func (fake *FakeDeployer) WatchCalls(stub func(context.Context, *client.Client) (<-chan string, <-chan error)) {
 	fake.watchMutex.Lock()
 	defer fake.watchMutex.Unlock()
 	fake.WatchStub = stub
 }
 
-func (fake *FakeDeployer) WatchArgsForCall(i int) *client.Client {
+func (fake *FakeDeployer) WatchArgsForCall(i int) (context.Context, *client.Client) {
 	fake.watchMutex.RLock()
 	defer fake.watchMutex.RUnlock()
 	argsForCall := fake.watchArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeDeployer) WatchReturns(result1 <-chan string, result2 <-chan error) {
@@ -849,8 +1789,12 @@ func (fake *FakeDeployer) WatchReturnsOnCall(i int, result1 <-chan string, resul
 func (fake *FakeDeployer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.checkHealthMutex.RLock()
+	defer fake.checkHealthMutex.RUnlock()
 	fake.compareRemotesMutex.RLock()
 	defer fake.compareRemotesMutex.RUnlock()
+	fake.controlContainerMutex.RLock()
+	defer fake.controlContainerMutex.RUnlock()
 	fake.deployMutex.RLock()
 	defer fake.deployMutex.RUnlock()
 	fake.destroyMutex.RLock()
@@ -859,14 +1803,36 @@ func (fake *FakeDeployer) Invocations() map[string][][]interface{} {
 	defer fake.downMutex.RUnlock()
 	fake.getBranchMutex.RLock()
 	defer fake.getBranchMutex.RUnlock()
+	fake.getChangedFilesMutex.RLock()
+	defer fake.getChangedFilesMutex.RUnlock()
 	fake.getDataManagerMutex.RLock()
 	defer fake.getDataManagerMutex.RUnlock()
+	fake.getHistoryMutex.RLock()
+	defer fake.getHistoryMutex.RUnlock()
+	fake.getImageMutex.RLock()
+	defer fake.getImageMutex.RUnlock()
+	fake.getPreviewConfigMutex.RLock()
+	defer fake.getPreviewConfigMutex.RUnlock()
 	fake.getStatusMutex.RLock()
 	defer fake.getStatusMutex.RUnlock()
+	fake.getTriggersMutex.RLock()
+	defer fake.getTriggersMutex.RUnlock()
+	fake.getWatchPathsMutex.RLock()
+	defer fake.getWatchPathsMutex.RUnlock()
 	fake.initializeMutex.RLock()
 	defer fake.initializeMutex.RUnlock()
+	fake.isPreviewMutex.RLock()
+	defer fake.isPreviewMutex.RUnlock()
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
 	fake.pruneMutex.RLock()
 	defer fake.pruneMutex.RUnlock()
+	fake.rollbackFailedDeployMutex.RLock()
+	defer fake.rollbackFailedDeployMutex.RUnlock()
 	fake.setConfigMutex.RLock()
 	defer fake.setConfigMutex.RUnlock()
 	fake.updateContainerHistoryMutex.RLock()
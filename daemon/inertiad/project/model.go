@@ -5,3 +5,10 @@ type envVariable struct {
 	Value     []byte
 	Encrypted bool
 }
+
+// GitCredentials are used to access HTTPS git remotes. Token is a personal
+// access token, or another credential accepted as a password by the git host.
+type GitCredentials struct {
+	Username string
+	Token    string
+}
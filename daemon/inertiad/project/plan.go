@@ -0,0 +1,176 @@
+package project
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/git"
+)
+
+// maxPlanCommits is the maximum number of commits listed in a plan
+const maxPlanCommits = 50
+
+// Plan describes what deploying the project with the given configuration
+// would change. The latest changes are fetched from the project's remote, but
+// the deployment is otherwise left untouched. Plans can be made while the
+// project is being deployed.
+func (d *Deployment) Plan(cfg DeploymentConfig) (api.DeploymentPlan, error) {
+	// Only the configuration is locked, since a deploy holds mux until its
+	// build is done
+	d.configMux.RLock()
+	var (
+		repo    = d.repo
+		branch  = d.branch
+		project = d.project
+		changes = []api.ConfigChange{
+			{Field: "build type", From: d.buildType, To: cfg.BuildType},
+			{Field: "build file", From: d.buildFilePath, To: cfg.BuildFilePath},
+			{Field: "build context", From: d.buildContext, To: cfg.BuildContext},
+			{Field: "image", From: imageName(d.image), To: imageName(cfg.Image)},
+			{Field: "target", From: d.target, To: cfg.Target},
+			{Field: "ports", From: strings.Join(d.ports, ", "), To: strings.Join(cfg.Ports, ", ")},
+			{Field: "compose", From: composeSummary(d.compose), To: composeSummary(cfg.Compose)},
+			{Field: "prune paths", From: strings.Join(d.pruneTo, ", "), To: strings.Join(cfg.PruneTo, ", ")},
+		}
+	)
+	auth, err := d.getAuth()
+	d.configMux.RUnlock()
+
+	var plan = api.DeploymentPlan{Project: project}
+	if repo == nil {
+		plan.NewDeployment = true
+		return plan, nil
+	}
+	if err != nil {
+		return plan, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return plan, err
+	}
+	plan.CurrentCommit = head.Hash().String()
+
+	// Find the target commit, preferring freshly fetched remote branches over
+	// local ones
+	if err := git.FetchRemote(repo, auth, ioutil.Discard); err != nil {
+		return plan, err
+	}
+	plan.TargetRef = cfg.Ref
+	if plan.TargetRef == "" {
+		plan.TargetRef = cfg.Branch
+		if plan.TargetRef == "" {
+			plan.TargetRef = branch
+		}
+	}
+	target, err := git.ResolveRef(repo, "origin/"+plan.TargetRef)
+	if err != nil {
+		if target, err = git.ResolveRef(repo, plan.TargetRef); err != nil {
+			return plan, err
+		}
+	}
+	plan.TargetCommit = target.String()
+
+	// Diff current and target commits
+	if err := planCommits(repo, &plan, head.Hash(), *target); err != nil {
+		return plan, err
+	}
+
+	// Compare configuration
+	for _, c := range changes {
+		if c.To != "" && c.To != c.From {
+			plan.ConfigChanges = append(plan.ConfigChanges, c)
+		}
+	}
+
+	// Compare environment variables with those of the last deploy
+	if d.dataManager != nil {
+		history, err := d.dataManager.GetProjectBuildData(project)
+		if err != nil {
+			return plan, err
+		}
+		env, err := d.dataManager.GetEnvVariables(false)
+		if err != nil {
+			return plan, err
+		}
+		// deploys recorded before env variables were tracked can't be compared
+		if len(history) == 0 || history[0].EnvVariables != nil {
+			var previous []string
+			if len(history) > 0 {
+				previous = history[0].EnvVariables
+			}
+			plan.EnvAdded, plan.EnvRemoved = diffNames(previous, envNames(env))
+		}
+	}
+
+	return plan, nil
+}
+
+// planCommits adds the commits and files between the given commits to plan
+func planCommits(repo *gogit.Repository, plan *api.DeploymentPlan, from, to plumbing.Hash) error {
+	if from == to {
+		return nil
+	}
+	commits, err := git.CommitsBetween(repo, from, to, maxPlanCommits)
+	if err != nil {
+		return err
+	}
+	for _, c := range commits {
+		plan.Commits = append(plan.Commits, api.CommitSummary{
+			Hash:    c.Hash.String(),
+			Author:  c.Author.Name,
+			Message: strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+		})
+	}
+	files, err := git.ChangedFiles(repo, from, to)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		plan.Files = append(plan.Files, api.FileChange{Action: f.Action, Path: f.Path})
+	}
+	return nil
+}
+
+// imageName returns the name of the given image, if there is one
+func imageName(image *api.Image) string {
+	if image == nil {
+		return ""
+	}
+	return image.Name
+}
+
+// envNames extracts variable names from the given NAME=VALUE pairs
+func envNames(env []string) []string {
+	var names = make([]string, len(env))
+	for i, e := range env {
+		names[i] = strings.SplitN(e, "=", 2)[0]
+	}
+	return names
+}
+
+// diffNames returns the names present only in next, and those present only in
+// previous, sorted
+func diffNames(previous, next []string) (added, removed []string) {
+	var seen = make(map[string]bool, len(previous))
+	for _, n := range previous {
+		seen[n] = true
+	}
+	for _, n := range next {
+		if seen[n] {
+			delete(seen, n)
+		} else {
+			added = append(added, n)
+		}
+	}
+	for n := range seen {
+		removed = append(removed, n)
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,162 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/git"
+)
+
+func TestDeployment_Plan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-plan")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Set up a remote repository
+	var remoteDir = filepath.Join(dir, "remote")
+	remote, err := gogit.PlainInit(remoteDir, false)
+	assert.NoError(t, err)
+	tree, err := remote.Worktree()
+	assert.NoError(t, err)
+	var commit = func(file, msg string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(remoteDir, file), []byte(msg), 0644))
+		_, err := tree.Add(file)
+		assert.NoError(t, err)
+		_, err = tree.Commit(msg, &gogit.CommitOptions{
+			Author: &object.Signature{Name: "bob", When: time.Now()},
+		})
+		assert.NoError(t, err)
+	}
+	commit("Dockerfile", "initial commit")
+
+	// Deployments without a repository are new
+	var d = &Deployment{project: "wow", branch: "master", buildType: "dockerfile"}
+	plan, err := d.Plan(DeploymentConfig{})
+	assert.NoError(t, err)
+	assert.True(t, plan.NewDeployment)
+
+	// Set up deployment, then push more commits to the remote
+	d.repo, err = git.InitializeRepository(remoteDir, git.RepoOptions{
+		Directory: filepath.Join(dir, "project"),
+		Branch:    "master",
+	}, ioutil.Discard)
+	assert.NoError(t, err)
+	head, err := d.repo.Head()
+	assert.NoError(t, err)
+	commit("main.go", "add server\n\nwith a longer description")
+	commit("docker-compose.yml", "switch to compose")
+
+	plan, err = d.Plan(DeploymentConfig{BuildType: "docker-compose"})
+	assert.NoError(t, err)
+	assert.False(t, plan.NewDeployment)
+	assert.Equal(t, head.Hash().String(), plan.CurrentCommit)
+	assert.Equal(t, "master", plan.TargetRef)
+	assert.NotEqual(t, plan.CurrentCommit, plan.TargetCommit)
+	assert.Len(t, plan.Commits, 2)
+	assert.Equal(t, "switch to compose", plan.Commits[0].Message)
+	assert.Equal(t, "add server", plan.Commits[1].Message)
+	assert.Equal(t, "bob", plan.Commits[0].Author)
+	assert.ElementsMatch(t, []api.FileChange{
+		{Action: "added", Path: "main.go"},
+		{Action: "added", Path: "docker-compose.yml"},
+	}, plan.Files)
+	assert.Equal(t, []api.ConfigChange{
+		{Field: "build type", From: "dockerfile", To: "docker-compose"},
+	}, plan.ConfigChanges)
+
+	// The deployment itself should not have changed
+	after, err := d.repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash(), after.Hash())
+
+	// Plans should not wait for deploys in progress
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	var done = make(chan error)
+	go func() {
+		_, err := d.Plan(DeploymentConfig{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("plan blocked by deploy in progress")
+	}
+}
+
+func TestDeployment_GetChangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-changes")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var remoteDir = filepath.Join(dir, "remote")
+	remote, err := gogit.PlainInit(remoteDir, false)
+	assert.NoError(t, err)
+	tree, err := remote.Worktree()
+	assert.NoError(t, err)
+	var commit = func(file string) string {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(remoteDir, file)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(remoteDir, file), []byte(file), 0644))
+		_, err := tree.Add(file)
+		assert.NoError(t, err)
+		hash, err := tree.Commit("add "+file, &gogit.CommitOptions{
+			Author: &object.Signature{Name: "bob", When: time.Now()},
+		})
+		assert.NoError(t, err)
+		return hash.String()
+	}
+	var before = commit("Dockerfile")
+
+	// Changes can't be determined without a repository
+	var d = &Deployment{project: "wow", branch: "master"}
+	_, err = d.GetChangedFiles(before, before)
+	assert.Error(t, err)
+
+	// Commits pushed after the deployment was set up should be fetched
+	d.repo, err = git.InitializeRepository(remoteDir, git.RepoOptions{
+		Directory: filepath.Join(dir, "project"),
+		Branch:    "master",
+	}, ioutil.Discard)
+	assert.NoError(t, err)
+	commit("services/api/main.go")
+	var after = commit("README.md")
+
+	files, err := d.GetChangedFiles(before, after)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"services/api/main.go", "README.md"}, files)
+
+	// Unknown commits should error
+	_, err = d.GetChangedFiles(before, "88d0d4becc199c8c2005faebca0fe3c446c88f50")
+	assert.Error(t, err)
+}
+
+func Test_diffNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    []string
+		next        []string
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		{"no changes", []string{"A", "B"}, []string{"B", "A"}, nil, nil},
+		{"added", []string{"A"}, []string{"A", "C", "B"}, []string{"B", "C"}, nil},
+		{"removed", []string{"A", "B"}, []string{"A"}, nil, []string{"B"}},
+		{"no previous", nil, []string{"A"}, []string{"A"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := diffNames(tt.previous, tt.next)
+			assert.Equal(t, tt.wantAdded, added)
+			assert.Equal(t, tt.wantRemoved, removed)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+)
+
+// DefaultPreviewPortOffset is added to published host ports, along with the
+// pull request number, if previews do not configure a port offset
+const DefaultPreviewPortOffset = 10000
+
+// validDomain matches domains that preview hostnames can be derived from
+var validDomain = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?$`)
+
+// PullRequest describes a pull request to deploy a preview of
+type PullRequest struct {
+	Number int
+	Branch string
+}
+
+// PreviewName returns the name of the project that previews the given pull
+// request to the given project
+func PreviewName(project string, number int) string {
+	return fmt.Sprintf("%s-pr-%d", project, number)
+}
+
+// ValidatePreviews checks if the given preview configuration is valid
+func ValidatePreviews(previews *api.Previews) error {
+	if previews == nil {
+		return nil
+	}
+	if previews.PortOffset < 0 || previews.PortOffset > 65535 {
+		return fmt.Errorf("invalid preview port offset %d", previews.PortOffset)
+	}
+	if previews.Domain != "" && !validDomain.MatchString(previews.Domain) {
+		return fmt.Errorf("invalid preview domain '%s'", previews.Domain)
+	}
+	return nil
+}
+
+// IsPreview returns true if the deployment is a preview of a pull request
+func (d *Deployment) IsPreview() bool {
+	return d.previewOf != ""
+}
+
+// GetPreviewConfig returns the configuration of a preview of the given pull
+// request. Previews are deployed from the pull request's branch with the same
+// build configuration as this deployment, but are isolated in their own
+// project, and publish ports offset by the pull request number. Host is the
+// address of the remote, used for the preview URL if previews are not given a
+// domain.
+func (d *Deployment) GetPreviewConfig(pr PullRequest, host string) (DeploymentConfig, error) {
+	if d.previews == nil || !d.previews.Enabled {
+		return DeploymentConfig{}, errors.New("previews are not enabled")
+	}
+	if strings.ToLower(d.buildType) == "image" {
+		return DeploymentConfig{}, errors.New("previews are not supported for image builds")
+	}
+	// Without configured ports, every port exposed by the project's image is
+	// published on the same host port, which would conflict with the project
+	if len(d.ports) == 0 && strings.ToLower(d.buildType) != "docker-compose" {
+		return DeploymentConfig{}, errors.New("previews require ports to be configured, " +
+			"since ports exposed by the project's image would conflict with the project's own")
+	}
+
+	var offset = d.previews.PortOffset
+	if offset == 0 {
+		offset = DefaultPreviewPortOffset
+	}
+	ports, published, err := previewPorts(d.ports, offset+pr.Number)
+	if err != nil {
+		return DeploymentConfig{}, err
+	}
+
+	// Previews get their own compose project, named after the preview
+	var compose *api.Compose
+	if d.compose != nil {
+		var c = *d.compose
+		c.ProjectName = ""
+		compose = &c
+	}
+
+	var labels = make(map[string]string, len(d.labels)+1)
+	for k, v := range d.labels {
+		labels[k] = v
+	}
+	var url string
+	if d.previews.Domain != "" {
+		var hostname = fmt.Sprintf("pr-%d.%s", pr.Number, d.previews.Domain)
+		labels[containers.LabelPreviewHost] = hostname
+		url = "http://" + hostname
+	} else if host != "" && published != "" {
+		url = fmt.Sprintf("http://%s:%s", host, published)
+	}
+
+	return DeploymentConfig{
+		ProjectName:            PreviewName(d.project, pr.Number),
+		BuildType:              d.buildType,
+		BuildFilePath:          d.buildFilePath,
+		BuildContext:           d.buildContext,
+		BuildArgs:              d.buildArgs,
+		BuildArgsFromEnv:       d.buildArgsFromEnv,
+		Target:                 d.target,
+		Ports:                  ports,
+		Labels:                 labels,
+		Resources:              d.resources,
+		Compose:                compose,
+		DeployStrategy:         d.deployStrategy,
+		HealthCheck:            d.healthCheck,
+		RestartPolicy:          d.restartPolicy,
+		Hooks:                  d.hooks,
+		RemoteURL:              d.remoteURL,
+		Branch:                 pr.Branch,
+		Submodules:             d.submodules,
+		LFS:                    d.lfs,
+		Depth:                  d.depth,
+		PruneTo:                d.pruneTo,
+		IntermediaryContainers: d.intermediaryContainers,
+		PreviewOf:              d.project,
+		PreviewURL:             url,
+		SlackNotificationURL:   d.slackNotificationURL,
+		Notifiers:              d.notifiers,
+	}, nil
+}
+
+// previewPorts offsets the host ports of the given port mappings, and returns
+// the first offset host port
+func previewPorts(ports []string, offset int) ([]string, string, error) {
+	var (
+		previews  = make([]string, 0, len(ports))
+		published string
+	)
+	for _, spec := range ports {
+		mappings, err := nat.ParsePortSpec(spec)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid port mapping: %s", err.Error())
+		}
+		for _, m := range mappings {
+			var hostIP = m.Binding.HostIP
+			if strings.Contains(hostIP, ":") {
+				hostIP = "[" + hostIP + "]"
+			}
+
+			// Mappings without a host port are published on a random port
+			if m.Binding.HostPort == "" {
+				if hostIP != "" {
+					previews = append(previews, hostIP+"::"+string(m.Port))
+				} else {
+					previews = append(previews, string(m.Port))
+				}
+				continue
+			}
+			port, err := strconv.Atoi(m.Binding.HostPort)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid port mapping: %s", err.Error())
+			}
+			if port+offset > 65535 {
+				return nil, "", fmt.Errorf("preview port for mapping '%s' exceeds 65535 - "+
+					"try a smaller preview port offset", spec)
+			}
+			var hostPort = strconv.Itoa(port + offset)
+			if published == "" {
+				published = hostPort
+			}
+			if hostIP != "" {
+				hostPort = hostIP + ":" + hostPort
+			}
+			previews = append(previews, hostPort+":"+string(m.Port))
+		}
+	}
+	return previews, published, nil
+}
@@ -0,0 +1,190 @@
+package project
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/containers"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/notify"
+)
+
+func TestValidatePreviews(t *testing.T) {
+	tests := []struct {
+		name     string
+		previews *api.Previews
+		wantErr  bool
+	}{
+		{"none", nil, false},
+		{"defaults", &api.Previews{Enabled: true}, false},
+		{"domain and offset", &api.Previews{Enabled: true, Domain: "preview.example.com", PortOffset: 2000}, false},
+		{"negative offset", &api.Previews{Enabled: true, PortOffset: -1}, true},
+		{"offset too large", &api.Previews{Enabled: true, PortOffset: 70000}, true},
+		{"invalid domain", &api.Previews{Enabled: true, Domain: "https://example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePreviews(tt.previews); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePreviews() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_previewPorts(t *testing.T) {
+	tests := []struct {
+		name          string
+		ports         []string
+		wantPorts     []string
+		wantPublished string
+		wantErr       bool
+	}{
+		{"none", nil, []string{}, "", false},
+		{"host ports", []string{"80:8080", "9090:9090/udp"},
+			[]string{"10092:8080/tcp", "19102:9090/udp"}, "10092", false},
+		{"host IP", []string{"127.0.0.1:80:8080"}, []string{"127.0.0.1:10092:8080/tcp"}, "10092", false},
+		{"random host port", []string{"8080", "127.0.0.1::8080"},
+			[]string{"8080/tcp", "127.0.0.1::8080/tcp"}, "", false},
+		{"range", []string{"80-81:80-81"}, []string{"10092:80/tcp", "10093:81/tcp"}, "10092", false},
+		{"too large", []string{"60000:80"}, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, published, err := previewPorts(tt.ports, 10012)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("previewPorts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.wantPorts, ports)
+			assert.Equal(t, tt.wantPublished, published)
+		})
+	}
+}
+
+func TestDeployment_GetPreviewConfig(t *testing.T) {
+	var slack = notify.NewSlackNotifier("https://hooks.slack.com/test")
+	var d = &Deployment{notifiers: notify.Notifiers{slack}}
+	d.SetConfig(DeploymentConfig{
+		ProjectName: "myproject",
+		Branch:      "master",
+		BuildType:   "dockerfile",
+		Ports:       []string{"80:8080"},
+		Labels:      map[string]string{"team": "launchpad"},
+		Compose:     &api.Compose{ProjectName: "shared"},
+		WatchPaths:  []string{"services/api"},
+	})
+	d.remoteURL = "git@github.com:ubclaunchpad/inertia.git"
+	var pr = PullRequest{Number: 12, Branch: "feature"}
+
+	// previews must be enabled
+	_, err := d.GetPreviewConfig(pr, "1.2.3.4")
+	assert.Error(t, err)
+
+	// published on offset ports
+	d.previews = &api.Previews{Enabled: true}
+	conf, err := d.GetPreviewConfig(pr, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, "myproject-pr-12", conf.ProjectName)
+	assert.Equal(t, "feature", conf.Branch)
+	assert.Empty(t, conf.Ref)
+	assert.Empty(t, conf.WatchPaths)
+	assert.Equal(t, d.remoteURL, conf.RemoteURL)
+	assert.Equal(t, []string{"10092:8080/tcp"}, conf.Ports)
+	assert.Equal(t, "", conf.Compose.ProjectName)
+	assert.Equal(t, "shared", d.compose.ProjectName)
+	assert.Equal(t, "myproject", conf.PreviewOf)
+	assert.Equal(t, "http://1.2.3.4:10092", conf.PreviewURL)
+	assert.True(t, conf.Notifiers.Exists(slack))
+
+	// served on a hostname
+	d.previews = &api.Previews{Enabled: true, Domain: "preview.example.com", PortOffset: 100}
+	conf, err = d.GetPreviewConfig(pr, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192:8080/tcp"}, conf.Ports)
+	assert.Equal(t, "http://pr-12.preview.example.com", conf.PreviewURL)
+	assert.Equal(t, "pr-12.preview.example.com", conf.Labels[containers.LabelPreviewHost])
+	assert.Equal(t, "launchpad", conf.Labels["team"])
+	assert.NotContains(t, d.labels, containers.LabelPreviewHost)
+
+	// previews know they are previews
+	var preview = &Deployment{}
+	preview.SetConfig(conf)
+	assert.True(t, preview.IsPreview())
+	assert.False(t, d.IsPreview())
+	assert.True(t, preview.notifiers.Exists(slack))
+
+	// without ports, exposed ports would be published on the project's ports
+	for _, buildType := range []string{"dockerfile", "herokuish"} {
+		d.buildType = buildType
+		d.ports = nil
+		_, err = d.GetPreviewConfig(pr, "1.2.3.4")
+		assert.Error(t, err)
+	}
+
+	// compose files are checked for published ports when the preview deploys
+	d.buildType = "docker-compose"
+	conf, err = d.GetPreviewConfig(pr, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Empty(t, conf.Ports)
+
+	// image builds have nothing to preview
+	d.buildType = "image"
+	_, err = d.GetPreviewConfig(pr, "1.2.3.4")
+	assert.Error(t, err)
+}
+
+func TestDeployment_DeployComposePreview(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inertia-compose-preview")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(`
+services:
+  web:
+    build: .
+    ports: ["80"]
+`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "published.yml"), []byte(`
+services:
+  web:
+    build: .
+    ports: ["8080:80"]
+`), 0644))
+
+	tests := []struct {
+		name      string
+		buildFile string
+		wantErr   bool
+	}{
+		{"random host ports", "", false},
+		{"published host ports", "published.yml", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fakeBuilder = newDefaultFakeBuilder(
+				func() error { return nil },
+				func() error { return nil })
+			var d = Deployment{directory: dir, builder: fakeBuilder}
+			d.SetConfig(DeploymentConfig{
+				ProjectName:   "myproject-pr-12",
+				BuildType:     "docker-compose",
+				BuildFilePath: tt.buildFile,
+				PreviewOf:     "myproject",
+			})
+
+			_, err := d.Deploy(context.Background(), nil, ioutil.Discard, DeployOptions{SkipUpdate: true})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "web: 8080:80")
+				assert.Equal(t, 0, fakeBuilder.BuildCallCount())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, fakeBuilder.BuildCallCount())
+			}
+		})
+	}
+}
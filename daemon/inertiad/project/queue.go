@@ -0,0 +1,126 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrDeploySuperseded is returned for queued deploys that were replaced by
+	// a more recent deploy request before they could start
+	ErrDeploySuperseded = errors.New("deploy superseded by a more recent deploy request")
+	// ErrDeployCancelled is returned for deploys that were cancelled
+	ErrDeployCancelled = errors.New("deploy cancelled")
+)
+
+// DeployJob executes a deploy. Implementations should stop as soon as possible
+// once the given context is cancelled.
+type DeployJob func(ctx context.Context) error
+
+// queuedDeploy tracks a submitted DeployJob
+type queuedDeploy struct {
+	job    DeployJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// DeployQueue runs the deploys of a project one at a time. At most one deploy
+// waits while another is running - submitting a deploy while one is already
+// waiting supersedes the waiting deploy, so that a burst of deploy requests
+// only results in a deploy of the most recent request.
+type DeployQueue struct {
+	running *queuedDeploy
+	pending *queuedDeploy
+	working bool
+	mux     sync.Mutex
+
+	// hold, if set, is read-locked while a deploy runs, so that deploys can be
+	// held off by write-locking it
+	hold *sync.RWMutex
+}
+
+// NewDeployQueue creates an empty deploy queue
+func NewDeployQueue() *DeployQueue { return &DeployQueue{} }
+
+// Submit queues the given job and blocks until it has been executed, returning
+// its error. ErrDeploySuperseded is returned if the job is replaced by a more
+// recent submission before it starts, and ErrDeployCancelled is returned if the
+// job is cancelled before it completes successfully.
+func (q *DeployQueue) Submit(job DeployJob) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var d = &queuedDeploy{
+		job:    job,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan error, 1),
+	}
+
+	q.mux.Lock()
+	if q.pending != nil {
+		q.pending.done <- ErrDeploySuperseded
+	}
+	q.pending = d
+	if !q.working {
+		q.working = true
+		go q.work()
+	}
+	q.mux.Unlock()
+
+	return <-d.done
+}
+
+// Active returns true if a deploy is running or waiting to run
+func (q *DeployQueue) Active() bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	return q.working
+}
+
+// Cancel aborts the running deploy and drops the waiting deploy, if there are
+// any. It returns false if there was nothing to cancel.
+func (q *DeployQueue) Cancel() bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	var cancelled bool
+	if q.pending != nil {
+		q.pending.done <- ErrDeployCancelled
+		q.pending = nil
+		cancelled = true
+	}
+	if q.running != nil {
+		q.running.cancel()
+		cancelled = true
+	}
+	return cancelled
+}
+
+// work executes queued deploys until there are none left
+func (q *DeployQueue) work() {
+	for {
+		q.mux.Lock()
+		var d = q.pending
+		q.pending = nil
+		q.running = d
+		if d == nil {
+			q.working = false
+			q.mux.Unlock()
+			return
+		}
+		q.mux.Unlock()
+
+		if q.hold != nil {
+			q.hold.RLock()
+		}
+		var err = d.job(d.ctx)
+		if q.hold != nil {
+			q.hold.RUnlock()
+		}
+		if err != nil && d.ctx.Err() != nil {
+			err = ErrDeployCancelled
+		}
+		d.done <- err
+	}
+}
@@ -0,0 +1,96 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployQueue_Submit(t *testing.T) {
+	var q = NewDeployQueue()
+	assert.False(t, q.Active())
+	assert.NoError(t, q.Submit(func(context.Context) error { return nil }))
+	assert.Equal(t, "oh no", q.Submit(func(context.Context) error { return errors.New("oh no") }).Error())
+	assert.False(t, q.Active())
+}
+
+func TestDeployQueue_Coalesce(t *testing.T) {
+	var (
+		q       = NewDeployQueue()
+		started = make(chan struct{})
+		release = make(chan struct{})
+		ran     = make([]int, 0)
+		results = make([]error, 4)
+		wg      sync.WaitGroup
+		mux     sync.Mutex
+	)
+
+	// Block the queue with a running deploy
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0] = q.Submit(func(context.Context) error {
+			close(started)
+			<-release
+			mux.Lock()
+			ran = append(ran, 0)
+			mux.Unlock()
+			return nil
+		})
+	}()
+	<-started
+	assert.True(t, q.Active())
+
+	// Queue up more deploys, one at a time - only the last should run
+	for i := 1; i < 4; i++ {
+		var i = i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = q.Submit(func(context.Context) error {
+				mux.Lock()
+				ran = append(ran, i)
+				mux.Unlock()
+				return nil
+			})
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []int{0, 3}, ran)
+	assert.Equal(t, []error{nil, ErrDeploySuperseded, ErrDeploySuperseded, nil}, results)
+	assert.False(t, q.Active())
+}
+
+func TestDeployQueue_Cancel(t *testing.T) {
+	var (
+		q       = NewDeployQueue()
+		started = make(chan struct{})
+		results = make(chan error, 2)
+	)
+	assert.False(t, q.Cancel())
+
+	// Start a deploy that runs until it is cancelled, and queue another
+	go func() {
+		results <- q.Submit(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+	go func() {
+		results <- q.Submit(func(context.Context) error { return nil })
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	assert.True(t, q.Cancel())
+	assert.Equal(t, ErrDeployCancelled, <-results)
+	assert.Equal(t, ErrDeployCancelled, <-results)
+}
@@ -0,0 +1,166 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrNoDeployments is returned when no projects have been deployed yet
+	ErrNoDeployments = errors.New("no projects have been deployed")
+	// ErrProjectNotSpecified is returned when a project is required to
+	// disambiguate between multiple deployments but none was given
+	ErrProjectNotSpecified = errors.New("multiple projects are deployed - a project must be specified")
+
+	// validProjectName restricts project names to those that are safe to use
+	// in paths and as Docker container names
+	validProjectName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// DeployerFactory creates a Deployer for the project with the given name
+type DeployerFactory func(name string) (Deployer, error)
+
+// Registry manages the set of named deployments hosted by the daemon, and the
+// queues their deploys are run through
+type Registry struct {
+	factory     DeployerFactory
+	deployments map[string]Deployer
+	queues      map[string]*DeployQueue
+	mux         sync.RWMutex
+
+	// hold is shared by all deploy queues - see HoldDeploys
+	hold sync.RWMutex
+}
+
+// NewRegistry creates a new registry that uses the given factory to set up
+// new deployments
+func NewRegistry(factory DeployerFactory) *Registry {
+	return &Registry{
+		factory:     factory,
+		deployments: make(map[string]Deployer),
+		queues:      make(map[string]*DeployQueue),
+	}
+}
+
+// ValidateProjectName checks if the given project name can be hosted
+func ValidateProjectName(name string) error {
+	if !validProjectName.MatchString(name) {
+		return fmt.Errorf("invalid project name '%s' - project names may only "+
+			"contain alphanumeric characters, '_', '.', and '-'", name)
+	}
+	return nil
+}
+
+// Get retrieves the deployment with the given name
+func (r *Registry) Get(name string) (Deployer, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	d, found := r.deployments[name]
+	return d, found
+}
+
+// GetOrCreate retrieves the deployment with the given name, setting up a new
+// one if none exists. The returned boolean indicates whether a new deployment
+// was created.
+func (r *Registry) GetOrCreate(name string) (Deployer, bool, error) {
+	if err := ValidateProjectName(name); err != nil {
+		return nil, false, err
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if d, found := r.deployments[name]; found {
+		return d, false, nil
+	}
+	d, err := r.factory(name)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to set up deployment for project '%s': %s",
+			name, err.Error())
+	}
+	d.SetConfig(DeploymentConfig{ProjectName: name})
+	r.deployments[name] = d
+	return d, true, nil
+}
+
+// Remove removes the deployment with the given name, and its deploy queue,
+// from the registry. The deployment itself is left untouched.
+func (r *Registry) Remove(name string) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	_, found := r.deployments[name]
+	delete(r.deployments, name)
+	delete(r.queues, name)
+	return found
+}
+
+// Resolve retrieves the name and deployment of the project with the given
+// name. If no name is given and only one project is deployed, that deployment
+// is returned.
+func (r *Registry) Resolve(name string) (string, Deployer, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	if name != "" {
+		d, found := r.deployments[name]
+		if !found {
+			return "", nil, fmt.Errorf("project '%s' not found", name)
+		}
+		return name, d, nil
+	}
+
+	switch len(r.deployments) {
+	case 0:
+		return "", nil, ErrNoDeployments
+	case 1:
+		for name, d := range r.deployments {
+			return name, d, nil
+		}
+	}
+	return "", nil, ErrProjectNotSpecified
+}
+
+// Queue retrieves the deploy queue of the project with the given name. All
+// deploys of a project should be run through its queue.
+func (r *Registry) Queue(name string) *DeployQueue {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	q, found := r.queues[name]
+	if !found {
+		q = &DeployQueue{hold: &r.hold}
+		r.queues[name] = q
+	}
+	return q
+}
+
+// HoldDeploys waits for any deploys in progress to complete, and then prevents
+// deploys of all projects from starting until the returned function is called.
+// Deploys submitted in the meantime wait in their queues.
+func (r *Registry) HoldDeploys() (release func()) {
+	r.hold.Lock()
+	return r.hold.Unlock
+}
+
+// Names returns the names of all registered deployments, sorted
+// alphabetically
+func (r *Registry) Names() []string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	var names = make([]string, 0, len(r.deployments))
+	for name := range r.deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForEach calls the given function on each registered deployment, in
+// alphabetical order
+func (r *Registry) ForEach(fn func(name string, d Deployer)) {
+	for _, name := range r.Names() {
+		if d, found := r.Get(name); found {
+			fn(name, d)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRegistry() *Registry {
+	return NewRegistry(func(name string) (Deployer, error) {
+		if name == "broken" {
+			return nil, errors.New("oh no")
+		}
+		return &Deployment{}, nil
+	})
+}
+
+func TestValidateProjectName(t *testing.T) {
+	assert.NoError(t, ValidateProjectName("my-project_1.0"))
+	assert.Error(t, ValidateProjectName(""))
+	assert.Error(t, ValidateProjectName("-project"))
+	assert.Error(t, ValidateProjectName("../project"))
+	assert.Error(t, ValidateProjectName("my project"))
+}
+
+func TestRegistry_GetOrCreate(t *testing.T) {
+	var r = newTestRegistry()
+
+	d, created, err := r.GetOrCreate("wow")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "wow", d.(*Deployment).project)
+
+	again, created, err := r.GetOrCreate("wow")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, d, again)
+
+	_, _, err = r.GetOrCreate("broken")
+	assert.Error(t, err)
+	_, _, err = r.GetOrCreate("../wow")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"wow"}, r.Names())
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	var r = newTestRegistry()
+
+	_, _, err := r.Resolve("")
+	assert.Equal(t, ErrNoDeployments, err)
+
+	wow, _, _ := r.GetOrCreate("wow")
+	name, d, err := r.Resolve("")
+	assert.NoError(t, err)
+	assert.Equal(t, "wow", name)
+	assert.Equal(t, wow, d)
+
+	amazing, _, _ := r.GetOrCreate("amazing")
+	_, _, err = r.Resolve("")
+	assert.Equal(t, ErrProjectNotSpecified, err)
+	name, d, err = r.Resolve("amazing")
+	assert.NoError(t, err)
+	assert.Equal(t, "amazing", name)
+	assert.Equal(t, amazing, d)
+
+	_, _, err = r.Resolve("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"amazing", "wow"}, r.Names())
+}
+
+func TestRegistry_Queue(t *testing.T) {
+	var r = newTestRegistry()
+	r.GetOrCreate("wow")
+	assert.NotNil(t, r.Queue("wow"))
+	assert.True(t, r.Queue("wow") == r.Queue("wow"))
+	assert.True(t, r.Queue("wow") != r.Queue("amazing"))
+}
+
+func TestRegistry_HoldDeploys(t *testing.T) {
+	var r = newTestRegistry()
+	var release = r.HoldDeploys()
+
+	// Deploys should wait while deploys are held
+	var ran = make(chan struct{})
+	go r.Queue("wow").Submit(func(context.Context) error {
+		close(ran)
+		return nil
+	})
+	select {
+	case <-ran:
+		t.Fatal("deploy ran while deploys were held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deploy did not run once deploys were released")
+	}
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	var r = newTestRegistry()
+	r.GetOrCreate("wow")
+	var q = r.Queue("wow")
+	assert.True(t, r.Remove("wow"))
+	assert.False(t, r.Remove("wow"))
+	_, found := r.Get("wow")
+	assert.False(t, found)
+	assert.True(t, q != r.Queue("wow"))
+}
@@ -0,0 +1,91 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/ubclaunchpad/inertia/api"
+	"github.com/ubclaunchpad/inertia/daemon/inertiad/git"
+)
+
+// ValidateGitOptions checks if the given repository options are valid
+func ValidateGitOptions(opts api.GitOptions) error {
+	if opts.Depth < 0 {
+		return errors.New("clone depth must not be negative")
+	}
+	return git.PrunePaths(opts.PruneTo).Validate()
+}
+
+// ValidateBuildContext checks if the given build context is a directory within
+// the repository
+func ValidateBuildContext(context string) error {
+	if context == "" {
+		return nil
+	}
+	var clean = path.Clean(context)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("build context '%s' must be a directory within the repository", context)
+	}
+	return nil
+}
+
+// ValidateWatchPaths checks if the given watch paths are valid
+func ValidateWatchPaths(watch []string) error {
+	for _, p := range watch {
+		if err := git.ValidatePattern(p); err != nil {
+			return fmt.Errorf("invalid watch path: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// MatchesWatchPaths checks if any of the given changed files are matched by
+// the given watch paths. Every change matches if there are no watch paths.
+func MatchesWatchPaths(watch []string, files []string) bool {
+	if len(watch) == 0 {
+		return true
+	}
+	for _, f := range files {
+		for _, p := range watch {
+			if git.MatchPattern(p, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetChangedFiles fetches the latest changes from the project's remote and
+// lists the files changed between the given commits. This is used to check
+// pushes against watch paths when webhook payloads don't list changed files.
+func (d *Deployment) GetChangedFiles(from, to string) ([]string, error) {
+	// Only the configuration is locked, as in Plan
+	d.configMux.RLock()
+	var repo = d.repo
+	auth, err := d.getAuth()
+	d.configMux.RUnlock()
+	if repo == nil {
+		return nil, errors.New("project repository is not set up")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := git.FetchRemote(repo, auth, ioutil.Discard); err != nil {
+		return nil, err
+	}
+	changes, err := git.ChangedFiles(repo, plumbing.NewHash(from), plumbing.NewHash(to))
+	if err != nil {
+		return nil, err
+	}
+	var files = make([]string, len(changes))
+	for i, c := range changes {
+		files[i] = c.Path
+	}
+	return files, nil
+}
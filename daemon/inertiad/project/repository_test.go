@@ -0,0 +1,84 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+func TestValidateGitOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    api.GitOptions
+		wantErr bool
+	}{
+		{"defaults", api.GitOptions{}, false},
+		{"shallow", api.GitOptions{Depth: 1}, false},
+		{"negative depth", api.GitOptions{Depth: -1}, true},
+		{"prune to", api.GitOptions{PruneTo: []string{"services/api", "libs/*"}}, false},
+		{"prune path outside repository", api.GitOptions{PruneTo: []string{"../secrets"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateGitOptions(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGitOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBuildContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		context string
+		wantErr bool
+	}{
+		{"repository root", "", false},
+		{"subdirectory", "services/api", false},
+		{"absolute path", "/services/api", true},
+		{"outside repository", "services/../../api", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBuildContext(tt.context); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBuildContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchesWatchPaths(t *testing.T) {
+	var files = []string{"docs/index.md", "services/api/main.go"}
+	tests := []struct {
+		name  string
+		watch []string
+		want  bool
+	}{
+		{"no watch paths", nil, true},
+		{"directory", []string{"services/api"}, true},
+		{"wildcard", []string{"services/*"}, true},
+		{"file", []string{"docs/index.md"}, true},
+		{"unchanged directory", []string{"services/web", "libs"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchesWatchPaths(tt.watch, files))
+		})
+	}
+	assert.Error(t, ValidateWatchPaths([]string{"../services"}))
+	assert.NoError(t, ValidateWatchPaths([]string{"services/*"}))
+}
+
+func TestDeployment_GetWatchPaths(t *testing.T) {
+	var d = &Deployment{directory: "/app/project"}
+	assert.Empty(t, d.GetWatchPaths())
+
+	// watch paths default to the build context
+	d.SetConfig(DeploymentConfig{BuildContext: "services/api"})
+	assert.Equal(t, []string{"services/api"}, d.GetWatchPaths())
+
+	d.SetConfig(DeploymentConfig{BuildContext: "services/api", WatchPaths: []string{"services/api", "libs"}})
+	assert.Equal(t, []string{"services/api", "libs"}, d.GetWatchPaths())
+}
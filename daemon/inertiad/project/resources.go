@@ -0,0 +1,76 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+// cpuPeriod is the CFS scheduler period used to convert CPU limits to quotas,
+// the same way the Docker CLI's --cpus flag does
+const cpuPeriod = 100000
+
+// ValidateResources checks if the given resource limits are valid
+func ValidateResources(r *api.Resources) error {
+	_, err := parseResources(r)
+	return err
+}
+
+// parseResources converts the given resource limits to their Docker API
+// representation
+func parseResources(r *api.Resources) (container.Resources, error) {
+	var res container.Resources
+	if r == nil {
+		return res, nil
+	}
+
+	if r.Memory != "" {
+		memory, err := units.RAMInBytes(r.Memory)
+		if err != nil {
+			return res, fmt.Errorf("invalid memory limit: %s", err.Error())
+		}
+		res.Memory = memory
+	}
+	if r.MemorySwap != "" {
+		if r.MemorySwap == "-1" {
+			res.MemorySwap = -1
+		} else {
+			swap, err := units.RAMInBytes(r.MemorySwap)
+			if err != nil {
+				return res, fmt.Errorf("invalid memory swap limit: %s", err.Error())
+			}
+			res.MemorySwap = swap
+		}
+		if res.Memory == 0 {
+			return res, errors.New("a memory limit is required with a memory swap limit")
+		}
+		if res.MemorySwap > 0 && res.MemorySwap < res.Memory {
+			return res, errors.New("memory swap limit must be larger than memory limit")
+		}
+	}
+
+	if r.CPUShares < 0 {
+		return res, errors.New("cpu shares must not be negative")
+	}
+	res.CPUShares = r.CPUShares
+	if r.CPUs < 0 {
+		return res, errors.New("cpus must not be negative")
+	}
+	if r.CPUs > 0 && r.CPUs < 0.01 {
+		return res, errors.New("cpus must be at least 0.01")
+	}
+	if r.CPUs > 0 {
+		res.CPUPeriod = cpuPeriod
+		res.CPUQuota = int64(r.CPUs * cpuPeriod)
+	}
+
+	if r.PidsLimit < 0 {
+		return res, errors.New("pids limit must not be negative")
+	}
+	res.PidsLimit = r.PidsLimit
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+func Test_parseResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *api.Resources
+		want      container.Resources
+		wantErr   bool
+	}{
+		{"no limits", nil, container.Resources{}, false},
+		{"memory", &api.Resources{Memory: "512m"},
+			container.Resources{Memory: 512 * 1024 * 1024}, false},
+		{"memory and swap", &api.Resources{Memory: "512m", MemorySwap: "1g"},
+			container.Resources{Memory: 512 * 1024 * 1024, MemorySwap: 1024 * 1024 * 1024}, false},
+		{"unlimited swap", &api.Resources{Memory: "512m", MemorySwap: "-1"},
+			container.Resources{Memory: 512 * 1024 * 1024, MemorySwap: -1}, false},
+		{"cpus", &api.Resources{CPUs: 1.5, CPUShares: 512},
+			container.Resources{CPUShares: 512, CPUPeriod: 100000, CPUQuota: 150000}, false},
+		{"pids", &api.Resources{PidsLimit: 100}, container.Resources{PidsLimit: 100}, false},
+		{"invalid memory", &api.Resources{Memory: "lots"}, container.Resources{}, true},
+		{"swap without memory", &api.Resources{MemorySwap: "1g"}, container.Resources{}, true},
+		{"swap smaller than memory", &api.Resources{Memory: "1g", MemorySwap: "512m"}, container.Resources{}, true},
+		{"negative cpus", &api.Resources{CPUs: -1}, container.Resources{}, true},
+		{"too few cpus", &api.Resources{CPUs: 0.001}, container.Resources{}, true},
+		{"negative pids", &api.Resources{PidsLimit: -1}, container.Resources{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseResources(tt.resources)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Error(t, ValidateResources(tt.resources))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+const (
+	// RestartNo leaves project containers stopped when they exit
+	RestartNo = "no"
+	// RestartOnFailure restarts project containers that exit with a non-zero
+	// exit code
+	RestartOnFailure = "on-failure"
+	// RestartAlways restarts project containers whenever they exit
+	RestartAlways = "always"
+
+	defaultRestartBackoff     = 5 * time.Second
+	maxRestartBackoff         = 5 * time.Minute
+	defaultCrashLoopThreshold = 5
+	defaultCrashLoopWindow    = 10 * time.Minute
+)
+
+// ValidateRestartPolicy checks if the given restart policy is valid
+func ValidateRestartPolicy(policy *api.RestartPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	switch policy.Policy {
+	case RestartNo, RestartOnFailure, RestartAlways:
+	default:
+		return fmt.Errorf("unknown restart policy '%s'", policy.Policy)
+	}
+	if policy.MaxRetries < 0 {
+		return errors.New("restart max retries must not be negative")
+	}
+	if policy.MaxRetries > 0 && policy.Policy != RestartOnFailure {
+		return errors.New("restart max retries are only supported by the on-failure restart policy")
+	}
+	if policy.CrashLoopThreshold < 0 {
+		return errors.New("crash loop threshold must not be negative")
+	}
+	for name, d := range map[string]string{
+		"restart backoff":   policy.Backoff,
+		"crash loop window": policy.CrashLoopWindow,
+	} {
+		if d == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid %s: %s", name, err.Error())
+		} else if duration <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+	return nil
+}
+
+// restartDecision describes how a container exit should be handled
+type restartDecision struct {
+	// Restart is set if the container should be restarted after Delay
+	Restart bool
+	Delay   time.Duration
+
+	// CrashLoop is set if the container has exited too many times, and should
+	// no longer be restarted
+	CrashLoop bool
+	Reason    string
+
+	// Expected is set if the container was stopped deliberately
+	Expected bool
+}
+
+// restartTracker tracks exits of project containers to decide whether they
+// should be restarted, and whether the deployment is crash looping. The zero
+// value never restarts containers.
+type restartTracker struct {
+	mux sync.Mutex
+
+	policy    string
+	retries   int
+	backoff   time.Duration
+	threshold int
+	window    time.Duration
+
+	// exits records recent exits of each container that counted towards
+	// crash loop detection, and restarts counts all restarts of each container
+	exits    map[string][]time.Time
+	restarts map[string]int
+
+	// expected records containers that are being stopped deliberately
+	expected map[string]bool
+
+	// failure is set once a container is crash looping
+	failure string
+}
+
+// reset clears tracked exits and failures, and applies the given policy
+func (t *restartTracker) reset(policy *api.RestartPolicy) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.exits = make(map[string][]time.Time)
+	t.restarts = make(map[string]int)
+	t.expected = make(map[string]bool)
+	t.failure = ""
+
+	t.policy, t.retries = RestartNo, 0
+	t.backoff, t.threshold, t.window = defaultRestartBackoff, defaultCrashLoopThreshold, defaultCrashLoopWindow
+	if policy == nil || ValidateRestartPolicy(policy) != nil {
+		return
+	}
+	t.policy, t.retries = policy.Policy, policy.MaxRetries
+	if policy.Backoff != "" {
+		t.backoff, _ = time.ParseDuration(policy.Backoff)
+	}
+	if policy.CrashLoopThreshold > 0 {
+		t.threshold = policy.CrashLoopThreshold
+	}
+	if policy.CrashLoopWindow != "" {
+		t.window, _ = time.ParseDuration(policy.CrashLoopWindow)
+	}
+}
+
+// exited records that the named container exited with the given exit code, and
+// decides how to handle it
+func (t *restartTracker) exited(name string, exitCode int, now time.Time) restartDecision {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.expected[name] {
+		delete(t.expected, name)
+		return restartDecision{Expected: true}
+	}
+	if t.policy == "" || t.policy == RestartNo ||
+		(t.policy == RestartOnFailure && exitCode == 0) {
+		return restartDecision{}
+	}
+	if t.exits == nil {
+		t.exits = make(map[string][]time.Time)
+		t.restarts = make(map[string]int)
+	}
+
+	// Only count exits within the crash loop window
+	var recent []time.Time
+	for _, exit := range t.exits[name] {
+		if now.Sub(exit) < t.window {
+			recent = append(recent, exit)
+		}
+	}
+	recent = append(recent, now)
+	t.exits[name] = recent
+
+	if len(recent) >= t.threshold {
+		t.failure = fmt.Sprintf("container %s exited %d times within %s", name, len(recent), t.window)
+		return restartDecision{CrashLoop: true, Reason: t.failure}
+	}
+	if t.retries > 0 && t.restarts[name] >= t.retries {
+		t.failure = fmt.Sprintf("container %s exceeded %d restart attempts", name, t.retries)
+		return restartDecision{CrashLoop: true, Reason: t.failure}
+	}
+	t.restarts[name]++
+
+	// Back off exponentially with each recent exit
+	var delay = t.backoff
+	for i := 1; i < len(recent) && delay < maxRestartBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRestartBackoff {
+		delay = maxRestartBackoff
+	}
+	return restartDecision{Restart: true, Delay: delay}
+}
+
+// expectExit records that the named container is about to be stopped
+// deliberately, so that its next exit is not handled by the restart policy
+func (t *restartTracker) expectExit(name string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.expected == nil {
+		t.expected = make(map[string]bool)
+	}
+	t.expected[name] = true
+}
+
+// getFailure returns the reason the deployment is considered failed, if any
+func (t *restartTracker) getFailure() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.failure
+}
@@ -0,0 +1,99 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+func TestValidateRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *api.RestartPolicy
+		wantErr bool
+	}{
+		{"no policy", nil, false},
+		{"no", &api.RestartPolicy{Policy: RestartNo}, false},
+		{"always", &api.RestartPolicy{Policy: RestartAlways, Backoff: "1s", CrashLoopWindow: "1m"}, false},
+		{"on-failure with retries", &api.RestartPolicy{Policy: RestartOnFailure, MaxRetries: 3}, false},
+		{"unknown policy", &api.RestartPolicy{Policy: "sometimes"}, true},
+		{"retries with always", &api.RestartPolicy{Policy: RestartAlways, MaxRetries: 3}, true},
+		{"negative retries", &api.RestartPolicy{Policy: RestartOnFailure, MaxRetries: -1}, true},
+		{"invalid backoff", &api.RestartPolicy{Policy: RestartAlways, Backoff: "soon"}, true},
+		{"negative window", &api.RestartPolicy{Policy: RestartAlways, CrashLoopWindow: "-1m"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRestartPolicy(tt.policy)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_restartTracker(t *testing.T) {
+	var now = time.Now()
+
+	t.Run("no policy", func(t *testing.T) {
+		var tracker restartTracker
+		assert.Equal(t, restartDecision{}, tracker.exited("web", 1, now))
+		tracker.reset(&api.RestartPolicy{Policy: RestartNo})
+		assert.Equal(t, restartDecision{}, tracker.exited("web", 1, now))
+	})
+
+	t.Run("on-failure ignores clean exits", func(t *testing.T) {
+		var tracker restartTracker
+		tracker.reset(&api.RestartPolicy{Policy: RestartOnFailure})
+		assert.False(t, tracker.exited("web", 0, now).Restart)
+		assert.True(t, tracker.exited("web", 1, now).Restart)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		var tracker restartTracker
+		tracker.reset(&api.RestartPolicy{Policy: RestartAlways, Backoff: "1s", CrashLoopThreshold: 10})
+		assert.Equal(t, time.Second, tracker.exited("web", 0, now).Delay)
+		assert.Equal(t, 2*time.Second, tracker.exited("web", 0, now).Delay)
+		assert.Equal(t, 4*time.Second, tracker.exited("web", 0, now).Delay)
+		// containers are tracked separately
+		assert.Equal(t, time.Second, tracker.exited("worker", 0, now).Delay)
+	})
+
+	t.Run("crash loop", func(t *testing.T) {
+		var tracker restartTracker
+		tracker.reset(&api.RestartPolicy{Policy: RestartAlways, CrashLoopThreshold: 3, CrashLoopWindow: "1m"})
+		assert.True(t, tracker.exited("web", 1, now).Restart)
+		// exits outside the window are forgotten
+		assert.True(t, tracker.exited("web", 1, now.Add(2*time.Minute)).Restart)
+		assert.True(t, tracker.exited("web", 1, now.Add(2*time.Minute)).Restart)
+		assert.Empty(t, tracker.getFailure())
+		var decision = tracker.exited("web", 1, now.Add(2*time.Minute))
+		assert.True(t, decision.CrashLoop)
+		assert.False(t, decision.Restart)
+		assert.Contains(t, tracker.getFailure(), "web")
+
+		tracker.reset(nil)
+		assert.Empty(t, tracker.getFailure())
+	})
+
+	t.Run("expected exits", func(t *testing.T) {
+		var tracker restartTracker
+		tracker.reset(&api.RestartPolicy{Policy: RestartAlways})
+		tracker.expectExit("web")
+		assert.True(t, tracker.exited("web", 0, now).Expected)
+		assert.True(t, tracker.exited("web", 0, now).Restart)
+	})
+
+	t.Run("max retries", func(t *testing.T) {
+		var tracker restartTracker
+		tracker.reset(&api.RestartPolicy{Policy: RestartOnFailure, MaxRetries: 2, CrashLoopWindow: "1s"})
+		assert.True(t, tracker.exited("web", 1, now).Restart)
+		assert.True(t, tracker.exited("web", 1, now.Add(time.Minute)).Restart)
+		assert.True(t, tracker.exited("web", 1, now.Add(2*time.Minute)).CrashLoop)
+	})
+}
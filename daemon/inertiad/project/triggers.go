@@ -0,0 +1,75 @@
+package project
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+// DefaultSkipDirectives prevent pushes from being deployed if triggers do not
+// declare their own skip directives
+var DefaultSkipDirectives = []string{"[skip deploy]"}
+
+// ValidateTriggers checks if the given trigger configuration is valid
+func ValidateTriggers(triggers *api.Triggers) error {
+	if triggers == nil {
+		return nil
+	}
+	for _, p := range append(append([]string{}, triggers.Branches...), triggers.Tags...) {
+		if p == "" {
+			return fmt.Errorf("trigger patterns must not be empty")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid trigger pattern '%s': %s", p, err.Error())
+		}
+	}
+	for _, s := range triggers.Skip {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("skip directives must not be empty")
+		}
+	}
+	return nil
+}
+
+// MatchesBranchTriggers checks if pushes to the given branch should be
+// deployed. If no branch patterns are configured, only pushes to the deployed
+// branch are deployed.
+func MatchesBranchTriggers(triggers *api.Triggers, deployed, branch string) bool {
+	if triggers == nil || len(triggers.Branches) == 0 {
+		return branch == deployed
+	}
+	return matchesAny(triggers.Branches, branch)
+}
+
+// MatchesTagTriggers checks if pushes of the given tag should be deployed
+func MatchesTagTriggers(triggers *api.Triggers, tag string) bool {
+	return triggers != nil && matchesAny(triggers.Tags, tag)
+}
+
+// GetSkipDirective returns the skip directive contained in the given commit
+// message, if there is one. Directives are matched case-insensitively.
+func GetSkipDirective(triggers *api.Triggers, message string) (string, bool) {
+	var directives = DefaultSkipDirectives
+	if triggers != nil && len(triggers.Skip) > 0 {
+		directives = triggers.Skip
+	}
+	message = strings.ToLower(message)
+	for _, d := range directives {
+		if strings.Contains(message, strings.ToLower(d)) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// matchesAny checks if the given name matches any of the given patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
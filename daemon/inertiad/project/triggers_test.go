@@ -0,0 +1,85 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ubclaunchpad/inertia/api"
+)
+
+func TestValidateTriggers(t *testing.T) {
+	tests := []struct {
+		name     string
+		triggers *api.Triggers
+		wantErr  bool
+	}{
+		{"none", nil, false},
+		{"patterns", &api.Triggers{Branches: []string{"main", "release/*"}, Tags: []string{"v*"}}, false},
+		{"skip directives", &api.Triggers{Skip: []string{"[no deploy]"}}, false},
+		{"empty pattern", &api.Triggers{Branches: []string{""}}, true},
+		{"invalid pattern", &api.Triggers{Tags: []string{"v[1"}}, true},
+		{"empty skip directive", &api.Triggers{Skip: []string{" "}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTriggers(tt.triggers); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTriggers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchesBranchTriggers(t *testing.T) {
+	var triggers = &api.Triggers{Branches: []string{"main", "release/*"}}
+	tests := []struct {
+		name     string
+		triggers *api.Triggers
+		branch   string
+		want     bool
+	}{
+		{"no triggers, deployed branch", nil, "main", true},
+		{"no triggers, other branch", nil, "dev", false},
+		{"no branch triggers", &api.Triggers{Tags: []string{"v*"}}, "dev", false},
+		{"exact match", triggers, "main", true},
+		{"pattern match", triggers, "release/1.2", true},
+		{"pattern does not match nested branch", triggers, "release/1.2/hotfix", false},
+		{"no match", triggers, "dev", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchesBranchTriggers(tt.triggers, "main", tt.branch))
+		})
+	}
+}
+
+func TestMatchesTagTriggers(t *testing.T) {
+	var triggers = &api.Triggers{Tags: []string{"v*.*.*"}}
+	assert.True(t, MatchesTagTriggers(triggers, "v1.2.3"))
+	assert.False(t, MatchesTagTriggers(triggers, "v1.2"))
+	assert.False(t, MatchesTagTriggers(&api.Triggers{}, "v1.2.3"))
+	assert.False(t, MatchesTagTriggers(nil, "v1.2.3"))
+}
+
+func TestGetSkipDirective(t *testing.T) {
+	tests := []struct {
+		name     string
+		triggers *api.Triggers
+		message  string
+		want     string
+		wantSkip bool
+	}{
+		{"no directive", nil, "fix typo", "", false},
+		{"default directive", nil, "fix typo\n\n[skip deploy]", "[skip deploy]", true},
+		{"case insensitive", nil, "fix typo [Skip Deploy]", "[skip deploy]", true},
+		{"custom directive", &api.Triggers{Skip: []string{"[no ci]", "[wip]"}}, "[WIP] fix typo", "[wip]", true},
+		{"custom directives replace default", &api.Triggers{Skip: []string{"[no ci]"}}, "fix typo [skip deploy]", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skip := GetSkipDirective(tt.triggers, tt.message)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantSkip, skip)
+		})
+	}
+}
@@ -7,12 +7,23 @@ import (
 // x-event-key header values
 var (
 	BitbucketPushHeader = "repo:push"
+
+	BitbucketPullCreatedHeader   = "pullrequest:created"
+	BitbucketPullUpdatedHeader   = "pullrequest:updated"
+	BitbucketPullFulfilledHeader = "pullrequest:fulfilled"
+	BitbucketPullRejectedHeader  = "pullrequest:rejected"
 )
 
 func parseBitbucketEvent(rawJSON map[string]interface{}, event string) (Payload, error) {
 	switch event {
 	case BitbucketPushHeader:
 		return parseBitbucketPushEvent(rawJSON), nil
+	case BitbucketPullCreatedHeader:
+		return parseBitbucketPullEvent(rawJSON, PullRequestOpened), nil
+	case BitbucketPullUpdatedHeader:
+		return parseBitbucketPullEvent(rawJSON, PullRequestSynchronized), nil
+	case BitbucketPullFulfilledHeader, BitbucketPullRejectedHeader:
+		return parseBitbucketPullEvent(rawJSON, PullRequestClosed), nil
 	default:
 		return nil, errors.New("unsupported Bitbucket event")
 	}
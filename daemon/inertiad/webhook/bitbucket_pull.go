@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Implements Payload interface
+// See bitbucket_test.go for an example request body
+type bitbucketPullEvent struct {
+	fullName string
+	pr       PullRequest
+}
+
+func parseBitbucketPullEvent(rawJSON map[string]interface{}, action PullRequestAction) bitbucketPullEvent {
+	// Extract pull request details
+	pull := rawJSON["pullrequest"].(map[string]interface{})
+	number := pull["id"].(float64)
+	title, _ := pull["title"].(string)
+	source := pull["source"].(map[string]interface{})
+	destination := pull["destination"].(map[string]interface{})
+	var commit string
+	if c, ok := source["commit"].(map[string]interface{}); ok {
+		commit, _ = c["hash"].(string)
+	}
+
+	// Extract repo details -- full name is retrieved
+	repo := rawJSON["repository"].(map[string]interface{})
+	fullName := repo["full_name"].(string)
+	var fromFork = true
+	if sourceRepo, ok := source["repository"].(map[string]interface{}); ok {
+		fromFork = sourceRepo["full_name"] != fullName
+	}
+
+	return bitbucketPullEvent{
+		fullName: fullName,
+		pr: PullRequest{
+			Number:     int(number),
+			Action:     action,
+			Title:      title,
+			HeadBranch: source["branch"].(map[string]interface{})["name"].(string),
+			HeadCommit: commit,
+			BaseBranch: destination["branch"].(map[string]interface{})["name"].(string),
+			FromFork:   fromFork,
+		},
+	}
+}
+
+// GetSource returns the source of the webhook
+func (b bitbucketPullEvent) GetSource() string {
+	return BitBucket
+}
+
+// GetEventType returns the event type of the webhook
+func (b bitbucketPullEvent) GetEventType() EventType {
+	return PullEvent
+}
+
+// GetRepoName returns the full repo name
+// full name takes the form [user]/[repo]
+func (b bitbucketPullEvent) GetRepoName() string {
+	return strings.Split(b.fullName, "/")[1]
+}
+
+// GetRef returns the full ref of the pull request's source branch
+func (b bitbucketPullEvent) GetRef() string {
+	return fmt.Sprintf("refs/heads/%s", b.pr.HeadBranch)
+}
+
+// GetGitURL returns the git clone URL
+// Ex. https://ubclaunchpad@bitbucket.org/ubclaunchpad/inertia.git
+func (b bitbucketPullEvent) GetGitURL() string {
+	user := strings.Split(b.fullName, "/")[0]
+	return fmt.Sprintf("https://%s@bitbucket.org/%s.git", user, b.fullName)
+}
+
+// GetSSHURL returns the ssh URL
+// Ex. git@bitbucket.org:ubclaunchpad/inertia.git
+func (b bitbucketPullEvent) GetSSHURL() string {
+	return "git@bitbucket.org:" + b.fullName + ".git"
+}
+
+// GetRefType returns the type of the head ref, which is always a branch
+func (b bitbucketPullEvent) GetRefType() RefType {
+	return BranchRef
+}
+
+// GetCommitMessage always returns an empty message, since pull request
+// payloads do not include commit messages
+func (b bitbucketPullEvent) GetCommitMessage() string {
+	return ""
+}
+
+// GetChangedFiles always reports that changed files are unknown, since pull
+// request payloads do not list changed files
+func (b bitbucketPullEvent) GetChangedFiles() ([]string, bool) {
+	return nil, false
+}
+
+// GetPullRequest returns the pull request
+func (b bitbucketPullEvent) GetPullRequest() (PullRequest, bool) {
+	return b.pr, true
+}
+
+// GetCommitRange always reports that no commit range is known
+func (b bitbucketPullEvent) GetCommitRange() (string, string, bool) {
+	return "", "", false
+}
+
+// IsDeleted always returns false, since pull request events do not delete refs
+func (b bitbucketPullEvent) IsDeleted() bool {
+	return false
+}
@@ -10,16 +10,46 @@ import (
 type bitbucketPushEvent struct {
 	eventType  EventType
 	branchName string
+	refType    RefType
 	fullName   string
+	message    string
+	before     string
+	after      string
+	deleted    bool
 }
 
 func parseBitbucketPushEvent(rawJSON map[string]interface{}) bitbucketPushEvent {
-	// Extract push details - branch name is retrieved
-	push := rawJSON["push"].(map[string]interface{})
-	changes := push["changes"].([]interface{})
-	changesObj := changes[0].(map[string]interface{})
-	new := changesObj["new"].(map[string]interface{})
-	branchName := new["name"].(string)
+	// Extract push details - branch name is retrieved. The new state of the
+	// ref is null if the ref was deleted, in which case only its old state is
+	// given.
+	var changesObj map[string]interface{}
+	if push, ok := rawJSON["push"].(map[string]interface{}); ok {
+		if changes, _ := push["changes"].([]interface{}); len(changes) > 0 {
+			changesObj, _ = changes[0].(map[string]interface{})
+		}
+	}
+	new, _ := changesObj["new"].(map[string]interface{})
+	var deleted = new == nil
+	var ref = new
+	if deleted {
+		ref, _ = changesObj["old"].(map[string]interface{})
+	}
+	branchName, _ := ref["name"].(string)
+	var refType = BranchRef
+	if ref["type"] == "tag" {
+		refType = TagRef
+	}
+	var message, after string
+	if target, ok := new["target"].(map[string]interface{}); ok {
+		message, _ = target["message"].(string)
+		after, _ = target["hash"].(string)
+	}
+	var before string
+	if old, ok := changesObj["old"].(map[string]interface{}); ok {
+		if target, ok := old["target"].(map[string]interface{}); ok {
+			before, _ = target["hash"].(string)
+		}
+	}
 
 	// Extract repo details -- full name is retrieved
 	repo := rawJSON["repository"].(map[string]interface{})
@@ -27,7 +57,12 @@ func parseBitbucketPushEvent(rawJSON map[string]interface{}) bitbucketPushEvent
 	return bitbucketPushEvent{
 		eventType:  PushEvent,
 		branchName: branchName,
+		refType:    refType,
 		fullName:   fullName,
+		message:    message,
+		before:     before,
+		after:      after,
+		deleted:    deleted,
 	}
 }
 
@@ -49,9 +84,20 @@ func (b bitbucketPushEvent) GetRepoName() string {
 
 // GetRef returns the full ref
 func (b bitbucketPushEvent) GetRef() string {
+	if b.refType == TagRef {
+		return fmt.Sprintf("refs/tags/%s", b.branchName)
+	}
 	return fmt.Sprintf("refs/heads/%s", b.branchName)
 }
 
+// GetRefType returns the type of the pushed ref
+func (b bitbucketPushEvent) GetRefType() RefType {
+	if b.refType == "" {
+		return BranchRef
+	}
+	return b.refType
+}
+
 // GetGitURL returns the git clone URL
 // Ex. https://ubclaunchpad@bitbucket.org/ubclaunchpad/inertia.git
 func (b bitbucketPushEvent) GetGitURL() string {
@@ -64,3 +110,32 @@ func (b bitbucketPushEvent) GetGitURL() string {
 func (b bitbucketPushEvent) GetSSHURL() string {
 	return "git@bitbucket.org:" + b.fullName + ".git"
 }
+
+// GetChangedFiles always reports that changed files are unknown, since
+// Bitbucket push payloads do not list the files changed by each commit - use
+// GetCommitRange to determine them instead
+func (b bitbucketPushEvent) GetChangedFiles() ([]string, bool) {
+	return nil, false
+}
+
+// GetCommitRange returns the commits the ref pointed to before and after the
+// push
+func (b bitbucketPushEvent) GetCommitRange() (string, string, bool) {
+	return commitRange(b.before, b.after)
+}
+
+// GetCommitMessage returns the message of the commit at the tip of the pushed
+// ref
+func (b bitbucketPushEvent) GetCommitMessage() string {
+	return b.message
+}
+
+// GetPullRequest always reports that no pull request is described
+func (b bitbucketPushEvent) GetPullRequest() (PullRequest, bool) {
+	return PullRequest{}, false
+}
+
+// IsDeleted returns true if the pushed ref was deleted
+func (b bitbucketPushEvent) IsDeleted() bool {
+	return b.deleted
+}
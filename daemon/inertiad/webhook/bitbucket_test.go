@@ -439,3 +439,29 @@ var bitbucketPushRawJSON = []byte(`
 	  "uuid": "{0d8c0652-f421-44cc-a58b-2f1c8c09fe9f}"
 	}
 }`)
+
+// Bitbucket Pull Request Event
+// see https://support.atlassian.com/bitbucket-cloud/docs/event-payloads/#Pull-request-events
+
+var bitbucketPullRawJSON = []byte(`
+{
+	"pullrequest": {
+	  "id": 42,
+	  "title": "Add landing page",
+	  "state": "OPEN",
+	  "source": {
+		"branch": { "name": "landing-page" },
+		"commit": { "hash": "f7da6e250682" },
+		"repository": { "full_name": "brian-nguyen/inertia-deploy-test" }
+	  },
+	  "destination": {
+		"branch": { "name": "master" },
+		"commit": { "hash": "0d1a26e67d8f" },
+		"repository": { "full_name": "brian-nguyen/inertia-deploy-test" }
+	  }
+	},
+	"repository": {
+	  "name": "inertia-deploy-test",
+	  "full_name": "brian-nguyen/inertia-deploy-test"
+	}
+}`)
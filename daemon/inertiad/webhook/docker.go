@@ -1,6 +1,12 @@
 package webhook
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
+
+// dockerHubPrefixes are registry prefixes that refer to Docker Hub
+var dockerHubPrefixes = []string{"docker.io/", "index.docker.io/", "registry.hub.docker.com/"}
 
 // DockerWebhook represents a push to DockerHub
 // see https://docs.docker.com/docker-hub/webhooks/
@@ -12,12 +18,21 @@ type DockerWebhook struct {
 
 // Extract DockerHub push details
 func parseDocker(rawJSON map[string]interface{}) (*DockerWebhook, error) {
-	pushData := rawJSON["push_data"].(map[string]interface{})
-	repo := rawJSON["repository"].(map[string]interface{})
+	pushData, ok := rawJSON["push_data"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid Docker webhook: missing push data")
+	}
+	repo, ok := rawJSON["repository"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid Docker webhook: missing repository")
+	}
 
-	pusher := pushData["pusher"].(string)
-	tag := pushData["tag"].(string)
-	repoName := repo["repo_name"].(string)
+	pusher, _ := pushData["pusher"].(string)
+	tag, _ := pushData["tag"].(string)
+	repoName, _ := repo["repo_name"].(string)
+	if tag == "" || repoName == "" {
+		return nil, errors.New("invalid Docker webhook: missing tag or repository name")
+	}
 
 	payload := &DockerWebhook{
 		pusher:   pusher,
@@ -51,3 +66,24 @@ func (d *DockerWebhook) GetName() string {
 func (d *DockerWebhook) GetOwner() string {
 	return strings.Split(d.repoName, "/")[0]
 }
+
+// MatchesImage checks if the pushed repository and tag correspond to the given
+// image, such as "ubclaunchpad/inertia:latest". Images without a tag are
+// treated as "latest", and images pinned to a digest never match.
+func (d *DockerWebhook) MatchesImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	for _, prefix := range dockerHubPrefixes {
+		image = strings.TrimPrefix(image, prefix)
+	}
+	var repo, tag = image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repo, tag = image[:i], image[i+1:]
+	}
+	if !strings.Contains(repo, "/") {
+		// official images are in the library namespace
+		repo = "library/" + repo
+	}
+	return repo == d.repoName && tag == d.tag
+}
@@ -10,7 +10,7 @@ import (
 var (
 	GithubPingHeader = "ping"
 	GithubPushHeader = "push"
-	// GithubPullHeader = "pull"
+	GithubPullHeader = "pull_request"
 )
 
 func parseGithubEvent(rawJSON map[string]interface{}, event string) (Payload, error) {
@@ -19,6 +19,8 @@ func parseGithubEvent(rawJSON map[string]interface{}, event string) (Payload, er
 		return githubPushEvent{eventType: PingEvent}, nil
 	case GithubPushHeader:
 		return parseGithubPushEvent(rawJSON), nil
+	case GithubPullHeader:
+		return parseGithubPullEvent(rawJSON), nil
 	default:
 		return nil, fmt.Errorf("unsupported Github event %s", event)
 	}
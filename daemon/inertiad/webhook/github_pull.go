@@ -0,0 +1,116 @@
+package webhook
+
+// Implements Payload interface
+// See github_test.go for an example request body
+type githubPullEvent struct {
+	name   string
+	gitURL string
+	sshURL string
+	pr     PullRequest
+}
+
+func parseGithubPullEvent(rawJSON map[string]interface{}) githubPullEvent {
+	// Extract pull request details
+	number := rawJSON["number"].(float64)
+	pull := rawJSON["pull_request"].(map[string]interface{})
+	title, _ := pull["title"].(string)
+	head := pull["head"].(map[string]interface{})
+	base := pull["base"].(map[string]interface{})
+
+	// Extract repo details
+	repo := rawJSON["repository"].(map[string]interface{})
+	name := repo["name"].(string)
+	gitURL := repo["clone_url"].(string)
+	sshURL := repo["ssh_url"].(string)
+
+	// The head repository is null if a fork has since been deleted
+	var fromFork = true
+	if headRepo, ok := head["repo"].(map[string]interface{}); ok {
+		fromFork = headRepo["full_name"] != repo["full_name"]
+	}
+
+	var action = PullRequestAction(rawJSON["action"].(string))
+	switch action {
+	case "opened", "reopened":
+		action = PullRequestOpened
+	case "synchronize":
+		action = PullRequestSynchronized
+	}
+
+	return githubPullEvent{
+		name:   name,
+		gitURL: gitURL,
+		sshURL: sshURL,
+		pr: PullRequest{
+			Number:     int(number),
+			Action:     action,
+			Title:      title,
+			HeadBranch: head["ref"].(string),
+			HeadCommit: head["sha"].(string),
+			BaseBranch: base["ref"].(string),
+			FromFork:   fromFork,
+		},
+	}
+}
+
+// GetSource returns the source of the webhook
+func (g githubPullEvent) GetSource() string {
+	return GitHub
+}
+
+// GetEventType returns the event type of the webhook
+func (g githubPullEvent) GetEventType() EventType {
+	return PullEvent
+}
+
+// GetRepoName returns the full repo name
+func (g githubPullEvent) GetRepoName() string {
+	return g.name
+}
+
+// GetRef returns the full ref of the pull request's head branch
+func (g githubPullEvent) GetRef() string {
+	return "refs/heads/" + g.pr.HeadBranch
+}
+
+// GetGitURL returns the git clone URL
+func (g githubPullEvent) GetGitURL() string {
+	return g.gitURL
+}
+
+// GetSSHURL returns the ssh URL
+func (g githubPullEvent) GetSSHURL() string {
+	return g.sshURL
+}
+
+// GetRefType returns the type of the head ref, which is always a branch
+func (g githubPullEvent) GetRefType() RefType {
+	return BranchRef
+}
+
+// GetCommitMessage always returns an empty message, since pull request
+// payloads do not include commit messages
+func (g githubPullEvent) GetCommitMessage() string {
+	return ""
+}
+
+// GetChangedFiles always reports that changed files are unknown, since pull
+// request payloads do not list changed files
+func (g githubPullEvent) GetChangedFiles() ([]string, bool) {
+	return nil, false
+}
+
+// GetPullRequest returns the pull request
+func (g githubPullEvent) GetPullRequest() (PullRequest, bool) {
+	return g.pr, true
+}
+
+// GetCommitRange always reports that no commit range is known
+func (g githubPullEvent) GetCommitRange() (string, string, bool) {
+	return "", "", false
+}
+
+// IsDeleted always returns false, since pull request events do not delete refs
+func (g githubPullEvent) IsDeleted() bool {
+	return false
+}
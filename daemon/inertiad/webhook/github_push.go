@@ -8,6 +8,11 @@ type githubPushEvent struct {
 	name      string
 	gitURL    string
 	sshURL    string
+	files     []string
+	before    string
+	after     string
+	message   string
+	deleted   bool
 }
 
 func parseGithubPushEvent(rawJSON map[string]interface{}) githubPushEvent {
@@ -21,12 +26,36 @@ func parseGithubPushEvent(rawJSON map[string]interface{}) githubPushEvent {
 	gitURL := repo["clone_url"].(string)
 	sshURL := repo["ssh_url"].(string)
 
+	// Pushes that don't include new commits, such as new branches, don't list
+	// any changes
+	var files []string
+	if commits, _ := rawJSON["commits"].([]interface{}); len(commits) > 0 {
+		files = getChangedFiles(commits)
+	}
+
+	// The head commit is null if the ref was deleted
+	before, _ := rawJSON["before"].(string)
+	after, _ := rawJSON["after"].(string)
+	deleted, _ := rawJSON["deleted"].(bool)
+	if after == nullCommit {
+		deleted = true
+	}
+	var message string
+	if head, ok := rawJSON["head_commit"].(map[string]interface{}); ok {
+		message, _ = head["message"].(string)
+	}
+
 	return githubPushEvent{
 		eventType: PushEvent,
 		ref:       ref,
 		name:      name,
 		gitURL:    gitURL,
 		sshURL:    sshURL,
+		files:     files,
+		before:    before,
+		after:     after,
+		message:   message,
+		deleted:   deleted,
 	}
 }
 
@@ -50,6 +79,11 @@ func (g githubPushEvent) GetRef() string {
 	return g.ref
 }
 
+// GetRefType returns the type of the pushed ref
+func (g githubPushEvent) GetRefType() RefType {
+	return getRefType(g.ref)
+}
+
 // GetGitURL returns the git clone URL
 func (g githubPushEvent) GetGitURL() string {
 	return g.gitURL
@@ -59,3 +93,29 @@ func (g githubPushEvent) GetGitURL() string {
 func (g githubPushEvent) GetSSHURL() string {
 	return g.sshURL
 }
+
+// GetChangedFiles returns the files changed by the pushed commits
+func (g githubPushEvent) GetChangedFiles() ([]string, bool) {
+	return g.files, g.files != nil
+}
+
+// GetCommitRange returns the commits the ref pointed to before and after the
+// push
+func (g githubPushEvent) GetCommitRange() (string, string, bool) {
+	return commitRange(g.before, g.after)
+}
+
+// GetCommitMessage returns the message of the head commit
+func (g githubPushEvent) GetCommitMessage() string {
+	return g.message
+}
+
+// GetPullRequest always reports that no pull request is described
+func (g githubPushEvent) GetPullRequest() (PullRequest, bool) {
+	return PullRequest{}, false
+}
+
+// IsDeleted returns true if the pushed ref was deleted
+func (g githubPushEvent) IsDeleted() bool {
+	return g.deleted
+}
@@ -168,3 +168,46 @@ var githubPushRawJSONStr = `
 var githubPushRawJSON = []byte(githubPushRawJSONStr)
 
 var githubPushFormEncoded = []byte(fmt.Sprintf("payload=%v", url.QueryEscape(githubPushRawJSONStr)))
+
+// Github Pull Request Event
+// see https://docs.github.com/en/developers/webhooks-and-events/webhook-events-and-payloads#pull_request
+
+var githubPullRawJSON = []byte(`
+{
+	"action": "synchronize",
+	"number": 42,
+	"pull_request": {
+	  "url": "https://api.github.com/repos/brian-nguyen/inertia-deploy-test/pulls/42",
+	  "html_url": "https://github.com/brian-nguyen/inertia-deploy-test/pull/42",
+	  "number": 42,
+	  "state": "open",
+	  "title": "Add landing page",
+	  "head": {
+		"label": "brian-nguyen:landing-page",
+		"ref": "landing-page",
+		"sha": "f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa",
+		"repo": {
+		  "name": "inertia-deploy-test",
+		  "full_name": "brian-nguyen/inertia-deploy-test"
+		}
+	  },
+	  "base": {
+		"label": "brian-nguyen:master",
+		"ref": "master",
+		"sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+		"repo": {
+		  "name": "inertia-deploy-test",
+		  "full_name": "brian-nguyen/inertia-deploy-test"
+		}
+	  },
+	  "merged": false
+	},
+	"repository": {
+	  "id": 133707414,
+	  "name": "inertia-deploy-test",
+	  "full_name": "brian-nguyen/inertia-deploy-test",
+	  "html_url": "https://github.com/brian-nguyen/inertia-deploy-test",
+	  "clone_url": "https://github.com/brian-nguyen/inertia-deploy-test.git",
+	  "ssh_url": "git@github.com:brian-nguyen/inertia-deploy-test.git"
+	}
+}`)
@@ -7,12 +7,16 @@ import (
 // x-gitlab-event header values
 var (
 	GitlabPushHeader = "Push Hook"
+	GitlabTagHeader  = "Tag Push Hook"
+	GitlabPullHeader = "Merge Request Hook"
 )
 
 func parseGitlabEvent(rawJSON map[string]interface{}, event string) (Payload, error) {
 	switch event {
-	case GitlabPushHeader:
+	case GitlabPushHeader, GitlabTagHeader:
 		return parseGitlabPushEvent(rawJSON), nil
+	case GitlabPullHeader:
+		return parseGitlabPullEvent(rawJSON), nil
 	default:
 		return nil, errors.New("unsupported Gitlab event")
 	}
@@ -0,0 +1,118 @@
+package webhook
+
+// Implements Payload interface
+// See gitlab_test.go for an example request body
+type gitlabPullEvent struct {
+	name   string
+	gitURL string
+	sshURL string
+	pr     PullRequest
+}
+
+func parseGitlabPullEvent(rawJSON map[string]interface{}) gitlabPullEvent {
+	// Extract merge request details
+	attrs := rawJSON["object_attributes"].(map[string]interface{})
+	number := attrs["iid"].(float64)
+	title, _ := attrs["title"].(string)
+	var commit string
+	if lastCommit, ok := attrs["last_commit"].(map[string]interface{}); ok {
+		commit, _ = lastCommit["id"].(string)
+	}
+
+	// Extract repo details - merge requests describe the target project
+	repo := rawJSON["project"].(map[string]interface{})
+	name := repo["name"].(string)
+	gitURL := repo["git_http_url"].(string)
+	sshURL := repo["git_ssh_url"].(string)
+
+	// Updates only include the previous head commit if new commits were
+	// pushed, as opposed to changes such as a new title
+	var action = PullRequestAction(attrs["action"].(string))
+	switch action {
+	case "open", "reopen":
+		action = PullRequestOpened
+	case "update":
+		if _, pushed := attrs["oldrev"]; pushed {
+			action = PullRequestSynchronized
+		}
+	case "close", "merge":
+		action = PullRequestClosed
+	}
+
+	return gitlabPullEvent{
+		name:   name,
+		gitURL: gitURL,
+		sshURL: sshURL,
+		pr: PullRequest{
+			Number:     int(number),
+			Action:     action,
+			Title:      title,
+			HeadBranch: attrs["source_branch"].(string),
+			HeadCommit: commit,
+			BaseBranch: attrs["target_branch"].(string),
+			FromFork:   attrs["source_project_id"] != attrs["target_project_id"],
+		},
+	}
+}
+
+// GetSource returns the source of the webhook
+func (g gitlabPullEvent) GetSource() string {
+	return GitLab
+}
+
+// GetEventType returns the event type of the webhook
+func (g gitlabPullEvent) GetEventType() EventType {
+	return PullEvent
+}
+
+// GetRepoName returns the repo name
+func (g gitlabPullEvent) GetRepoName() string {
+	return g.name
+}
+
+// GetRef returns the full ref of the merge request's source branch
+func (g gitlabPullEvent) GetRef() string {
+	return "refs/heads/" + g.pr.HeadBranch
+}
+
+// GetGitURL returns the git clone URL
+func (g gitlabPullEvent) GetGitURL() string {
+	return g.gitURL
+}
+
+// GetSSHURL returns the ssh URL
+func (g gitlabPullEvent) GetSSHURL() string {
+	return g.sshURL
+}
+
+// GetRefType returns the type of the head ref, which is always a branch
+func (g gitlabPullEvent) GetRefType() RefType {
+	return BranchRef
+}
+
+// GetCommitMessage always returns an empty message, since pull request
+// payloads do not include commit messages
+func (g gitlabPullEvent) GetCommitMessage() string {
+	return ""
+}
+
+// GetChangedFiles always reports that changed files are unknown, since merge
+// request payloads do not list changed files
+func (g gitlabPullEvent) GetChangedFiles() ([]string, bool) {
+	return nil, false
+}
+
+// GetPullRequest returns the merge request
+func (g gitlabPullEvent) GetPullRequest() (PullRequest, bool) {
+	return g.pr, true
+}
+
+// GetCommitRange always reports that no commit range is known
+func (g gitlabPullEvent) GetCommitRange() (string, string, bool) {
+	return "", "", false
+}
+
+// IsDeleted always returns false, since pull request events do not delete refs
+func (g gitlabPullEvent) IsDeleted() bool {
+	return false
+}
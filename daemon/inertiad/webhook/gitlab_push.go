@@ -8,6 +8,11 @@ type gitlabPushEvent struct {
 	name      string
 	gitURL    string
 	sshURL    string
+	files     []string
+	before    string
+	after     string
+	message   string
+	deleted   bool
 }
 
 func parseGitlabPushEvent(rawJSON map[string]interface{}) gitlabPushEvent {
@@ -19,12 +24,38 @@ func parseGitlabPushEvent(rawJSON map[string]interface{}) gitlabPushEvent {
 	gitURL := repo["git_http_url"].(string)
 	sshURL := repo["git_ssh_url"].(string)
 
+	// GitLab only lists up to 20 commits, so changes can only be determined
+	// if all commits are included
+	var files []string
+	commits, _ := rawJSON["commits"].([]interface{})
+	total, _ := rawJSON["total_commits_count"].(float64)
+	if len(commits) > 0 && len(commits) >= int(total) {
+		files = getChangedFiles(commits)
+	}
+
+	// Find the message of the commit that was checked out
+	var message string
+	for _, c := range commits {
+		if commit, ok := c.(map[string]interface{}); ok && commit["id"] == rawJSON["checkout_sha"] {
+			message, _ = commit["message"].(string)
+		}
+	}
+
+	// Deleted refs are pushed with a null commit
+	before, _ := rawJSON["before"].(string)
+	after, _ := rawJSON["after"].(string)
+
 	return gitlabPushEvent{
 		eventType: PushEvent,
 		ref:       ref,
 		name:      name,
 		gitURL:    gitURL,
 		sshURL:    sshURL,
+		files:     files,
+		before:    before,
+		after:     after,
+		message:   message,
+		deleted:   after == nullCommit,
 	}
 }
 
@@ -48,6 +79,11 @@ func (g gitlabPushEvent) GetRef() string {
 	return g.ref
 }
 
+// GetRefType returns the type of the pushed ref
+func (g gitlabPushEvent) GetRefType() RefType {
+	return getRefType(g.ref)
+}
+
 // GetGitURL returns the git clone URL
 func (g gitlabPushEvent) GetGitURL() string {
 	return g.gitURL
@@ -57,3 +93,29 @@ func (g gitlabPushEvent) GetGitURL() string {
 func (g gitlabPushEvent) GetSSHURL() string {
 	return g.sshURL
 }
+
+// GetChangedFiles returns the files changed by the pushed commits
+func (g gitlabPushEvent) GetChangedFiles() ([]string, bool) {
+	return g.files, g.files != nil
+}
+
+// GetCommitRange returns the commits the ref pointed to before and after the
+// push
+func (g gitlabPushEvent) GetCommitRange() (string, string, bool) {
+	return commitRange(g.before, g.after)
+}
+
+// GetCommitMessage returns the message of the checked out commit
+func (g gitlabPushEvent) GetCommitMessage() string {
+	return g.message
+}
+
+// GetPullRequest always reports that no pull request is described
+func (g gitlabPushEvent) GetPullRequest() (PullRequest, bool) {
+	return PullRequest{}, false
+}
+
+// IsDeleted returns true if the pushed ref was deleted
+func (g gitlabPushEvent) IsDeleted() bool {
+	return g.deleted
+}
@@ -93,3 +93,38 @@ var gitlabPushRawJSON = []byte(`
 	  "visibility_level": 20
 	}
 }`)
+
+// Gitlab Merge Request Event
+// see https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#merge-request-events
+
+var gitlabPullRawJSON = []byte(`
+{
+	"object_kind": "merge_request",
+	"event_type": "merge_request",
+	"project": {
+	  "id": 7185543,
+	  "name": "inertia-deploy-test",
+	  "web_url": "https://gitlab.com/brian-nguyen/inertia-deploy-test",
+	  "git_ssh_url": "git@gitlab.com:brian-nguyen/inertia-deploy-test.git",
+	  "git_http_url": "https://gitlab.com/brian-nguyen/inertia-deploy-test.git",
+	  "path_with_namespace": "brian-nguyen/inertia-deploy-test",
+	  "default_branch": "master"
+	},
+	"object_attributes": {
+	  "id": 99,
+	  "iid": 42,
+	  "title": "Add landing page",
+	  "state": "opened",
+	  "action": "update",
+	  "oldrev": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+	  "source_branch": "landing-page",
+	  "source_project_id": 7185543,
+	  "target_branch": "master",
+	  "target_project_id": 7185543,
+	  "last_commit": {
+		"id": "f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa",
+		"message": "Add landing page"
+	  },
+	  "url": "https://gitlab.com/brian-nguyen/inertia-deploy-test/merge_requests/42"
+	}
+}`)
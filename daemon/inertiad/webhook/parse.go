@@ -28,8 +28,85 @@ type Payload interface {
 	GetEventType() EventType
 	GetRepoName() string
 	GetRef() string
+	GetRefType() RefType
 	GetGitURL() string
 	GetSSHURL() string
+
+	// GetChangedFiles returns the paths of files added, modified, or removed
+	// by the pushed commits. If the payload does not provide a complete list
+	// of changed files, ok is false.
+	GetChangedFiles() (files []string, ok bool)
+
+	// GetCommitRange returns the commits at the tip of the pushed ref before
+	// and after the push, which can be used to work out changed files when
+	// the payload does not list them. If either commit is unknown, such as
+	// for new or deleted refs, ok is false.
+	GetCommitRange() (before, after string, ok bool)
+
+	// GetCommitMessage returns the message of the commit at the tip of the
+	// pushed ref, if it is known
+	GetCommitMessage() string
+
+	// GetPullRequest returns the pull request described by a pull request
+	// event. For other events, ok is false.
+	GetPullRequest() (pr PullRequest, ok bool)
+
+	// IsDeleted returns true if the event reports that the ref was deleted
+	IsDeleted() bool
+}
+
+// RefType denotes the kind of ref an event refers to
+type RefType string
+
+// Types of refs
+const (
+	BranchRef RefType = "branch"
+	TagRef    RefType = "tag"
+)
+
+// nullCommit is the commit hash GitHub and GitLab report as the tip of a
+// deleted ref
+const nullCommit = "0000000000000000000000000000000000000000"
+
+// commitRange reports the given commits as a range if both are known
+func commitRange(before, after string) (string, string, bool) {
+	if before == "" || after == "" || before == nullCommit || after == nullCommit {
+		return "", "", false
+	}
+	return before, after, true
+}
+
+// getRefType determines the type of the given full ref
+func getRefType(ref string) RefType {
+	if strings.HasPrefix(ref, "refs/tags/") {
+		return TagRef
+	}
+	return BranchRef
+}
+
+// PullRequestAction denotes what happened to a pull request
+type PullRequestAction string
+
+// Pull request actions that previews respond to - other actions, such as
+// edits to a pull request's title, are reported as-is
+const (
+	PullRequestOpened       PullRequestAction = "opened"
+	PullRequestSynchronized PullRequestAction = "synchronized"
+	PullRequestClosed       PullRequestAction = "closed"
+)
+
+// PullRequest describes a pull request, or a GitLab merge request
+type PullRequest struct {
+	Number     int
+	Action     PullRequestAction
+	Title      string
+	HeadBranch string
+	HeadCommit string
+	BaseBranch string
+
+	// FromFork is true if the head branch belongs to a different repository
+	// than the one the pull request was opened against
+	FromFork bool
 }
 
 // Parse takes in a webhook request and parses it into one of the supported types
@@ -119,3 +196,28 @@ func getPayloadBytes(host, contentType string, body []byte) ([]byte, error) {
 		return nil, errors.New("Unsupported webhook received")
 	}
 }
+
+// getChangedFiles collects the files added, modified, or removed by the given
+// commits, in the format used by GitHub and GitLab push payloads
+func getChangedFiles(commits []interface{}) []string {
+	var (
+		files = make([]string, 0)
+		seen  = make(map[string]bool)
+	)
+	for _, c := range commits {
+		commit, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"added", "modified", "removed"} {
+			paths, _ := commit[key].([]interface{})
+			for _, p := range paths {
+				if path, ok := p.(string); ok && !seen[path] {
+					seen[path] = true
+					files = append(files, path)
+				}
+			}
+		}
+	}
+	return files
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,7 +31,11 @@ func TestTypeAndParse(t *testing.T) {
 		{GitHub, "application/json", githubPushRawJSON, "x-github-event", GithubPushHeader, PushEvent},
 		{GitHub, "application/json", githubPushRawJSON, "x-github-event", GithubPingHeader, PingEvent},
 		{GitLab, "application/json", gitlabPushRawJSON, "x-gitlab-event", GitlabPushHeader, PushEvent},
+		{GitLab, "application/json", gitlabPushRawJSON, "x-gitlab-event", GitlabTagHeader, PushEvent},
 		{BitBucket, "application/json", bitbucketPushRawJSON, "x-event-key", BitbucketPushHeader, PushEvent},
+		{GitHub, "application/json", githubPullRawJSON, "x-github-event", GithubPullHeader, PullEvent},
+		{GitLab, "application/json", gitlabPullRawJSON, "x-gitlab-event", GitlabPullHeader, PullEvent},
+		{BitBucket, "application/json", bitbucketPullRawJSON, "x-event-key", BitbucketPullUpdatedHeader, PullEvent},
 	}
 	for _, tc := range testCases {
 		req := getMockRequest("/webhook", tc.contentType, tc.reqBody)
@@ -58,6 +63,21 @@ func TestTypeAndParse(t *testing.T) {
 		case PushEvent:
 			assert.Equal(t, "inertia-deploy-test", payload.GetRepoName())
 			assert.Equal(t, "refs/heads/master", payload.GetRef())
+			assert.Equal(t, BranchRef, payload.GetRefType())
+			assert.Contains(t, payload.GetCommitMessage(), "Local parse test")
+		case PullEvent:
+			assert.Equal(t, "inertia-deploy-test", payload.GetRepoName())
+			assert.Equal(t, "refs/heads/landing-page", payload.GetRef())
+			assert.Contains(t, payload.GetSSHURL(), "brian-nguyen/inertia-deploy-test.git")
+			pr, ok := payload.GetPullRequest()
+			assert.True(t, ok)
+			assert.Equal(t, 42, pr.Number)
+			assert.Equal(t, PullRequestSynchronized, pr.Action)
+			assert.Equal(t, "Add landing page", pr.Title)
+			assert.Equal(t, "landing-page", pr.HeadBranch)
+			assert.Equal(t, "master", pr.BaseBranch)
+			assert.True(t, strings.HasPrefix("f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa", pr.HeadCommit))
+			assert.False(t, pr.FromFork)
 		}
 	}
 }
@@ -72,4 +92,281 @@ func TestParseDocker(t *testing.T) {
 	assert.Equal(t, "ubclaunchpad/inertia", payload.GetRepoName())
 	assert.Equal(t, "inertia", payload.GetName())
 	assert.Equal(t, "ubclaunchpad", payload.GetOwner())
+
+	t.Run("invalid payload", func(t *testing.T) {
+		req := getMockRequest("/docker-webhook", "application/json", []byte(`{"push_data":{}}`))
+		_, err := ParseDocker(req)
+		assert.Error(t, err)
+	})
+}
+
+func TestDockerWebhook_MatchesImage(t *testing.T) {
+	var payload = &DockerWebhook{repoName: "ubclaunchpad/inertia", tag: "latest"}
+	var official = &DockerWebhook{repoName: "library/nginx", tag: "1.19"}
+	tests := []struct {
+		name    string
+		payload *DockerWebhook
+		image   string
+		want    bool
+	}{
+		{"repository and tag", payload, "ubclaunchpad/inertia:latest", true},
+		{"implicit latest tag", payload, "ubclaunchpad/inertia", true},
+		{"docker hub prefix", payload, "docker.io/ubclaunchpad/inertia:latest", true},
+		{"different tag", payload, "ubclaunchpad/inertia:v1.0.0", false},
+		{"different repository", payload, "ubclaunchpad/bobheadxi:latest", false},
+		{"other registry", payload, "ghcr.io/ubclaunchpad/inertia:latest", false},
+		{"digest", payload, "ubclaunchpad/inertia@sha256:abcde", false},
+		{"official image", official, "nginx:1.19", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.payload.MatchesImage(tt.image))
+		})
+	}
+}
+
+func TestPayload_GetChangedFiles(t *testing.T) {
+	var repo = map[string]interface{}{
+		"name": "inertia", "clone_url": "", "ssh_url": "", "git_http_url": "", "git_ssh_url": "",
+	}
+	var commits = []interface{}{
+		map[string]interface{}{
+			"added":    []interface{}{"services/api/main.go"},
+			"modified": []interface{}{"README.md"},
+			"removed":  []interface{}{},
+		},
+		map[string]interface{}{
+			"modified": []interface{}{"README.md"},
+			"removed":  []interface{}{"docs/old.md"},
+		},
+	}
+	tests := []struct {
+		name      string
+		payload   Payload
+		wantFiles []string
+		wantOK    bool
+	}{
+		{"github", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/heads/master", "repository": repo, "commits": commits,
+		}), []string{"services/api/main.go", "README.md", "docs/old.md"}, true},
+		{"github without commits", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/heads/master", "repository": repo, "commits": []interface{}{},
+		}), nil, false},
+		{"gitlab", parseGitlabPushEvent(map[string]interface{}{
+			"ref": "refs/heads/master", "repository": repo, "commits": commits,
+			"total_commits_count": float64(2),
+		}), []string{"services/api/main.go", "README.md", "docs/old.md"}, true},
+		{"gitlab with truncated commits", parseGitlabPushEvent(map[string]interface{}{
+			"ref": "refs/heads/master", "repository": repo, "commits": commits,
+			"total_commits_count": float64(25),
+		}), nil, false},
+		{"bitbucket", bitbucketPushEvent{}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, ok := tt.payload.GetChangedFiles()
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantFiles, files)
+		})
+	}
+}
+
+func TestPayload_GetPullRequest(t *testing.T) {
+	var repo = map[string]interface{}{
+		"name": "inertia", "full_name": "ubclaunchpad/inertia",
+		"clone_url": "", "ssh_url": "", "git_http_url": "", "git_ssh_url": "",
+	}
+	var githubPull = func(action string, headRepo interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"action": action, "number": float64(7), "repository": repo,
+			"pull_request": map[string]interface{}{
+				"head": map[string]interface{}{"ref": "feature", "sha": "abcde", "repo": headRepo},
+				"base": map[string]interface{}{"ref": "master"},
+			},
+		}
+	}
+	var gitlabPull = func(attrs map[string]interface{}) map[string]interface{} {
+		attrs["iid"] = float64(7)
+		attrs["source_branch"] = "feature"
+		attrs["target_branch"] = "master"
+		if _, ok := attrs["source_project_id"]; !ok {
+			attrs["source_project_id"] = float64(1)
+		}
+		attrs["target_project_id"] = float64(1)
+		return map[string]interface{}{"project": repo, "object_attributes": attrs}
+	}
+	tests := []struct {
+		name         string
+		payload      Payload
+		wantAction   PullRequestAction
+		wantFromFork bool
+	}{
+		{"github opened", parseGithubPullEvent(githubPull("opened", repo)), PullRequestOpened, false},
+		{"github reopened", parseGithubPullEvent(githubPull("reopened", repo)), PullRequestOpened, false},
+		{"github synchronize", parseGithubPullEvent(githubPull("synchronize", repo)), PullRequestSynchronized, false},
+		{"github closed", parseGithubPullEvent(githubPull("closed", repo)), PullRequestClosed, false},
+		{"github edited", parseGithubPullEvent(githubPull("edited", repo)), "edited", false},
+		{"github fork", parseGithubPullEvent(githubPull("opened",
+			map[string]interface{}{"full_name": "bobheadxi/inertia"})), PullRequestOpened, true},
+		{"github deleted fork", parseGithubPullEvent(githubPull("opened", nil)), PullRequestOpened, true},
+		{"gitlab open", parseGitlabPullEvent(gitlabPull(map[string]interface{}{"action": "open"})),
+			PullRequestOpened, false},
+		{"gitlab update with commits", parseGitlabPullEvent(gitlabPull(map[string]interface{}{"action": "update", "oldrev": "abcde"})),
+			PullRequestSynchronized, false},
+		{"gitlab update without commits", parseGitlabPullEvent(gitlabPull(map[string]interface{}{"action": "update"})),
+			"update", false},
+		{"gitlab merge", parseGitlabPullEvent(gitlabPull(map[string]interface{}{"action": "merge"})),
+			PullRequestClosed, false},
+		{"gitlab fork", parseGitlabPullEvent(gitlabPull(map[string]interface{}{"action": "open", "source_project_id": float64(2)})),
+			PullRequestOpened, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, ok := tt.payload.GetPullRequest()
+			assert.True(t, ok)
+			assert.Equal(t, PullEvent, tt.payload.GetEventType())
+			assert.Equal(t, 7, pr.Number)
+			assert.Equal(t, tt.wantAction, pr.Action)
+			assert.Equal(t, tt.wantFromFork, pr.FromFork)
+		})
+	}
+
+	// push events never describe pull requests
+	_, ok := githubPushEvent{}.GetPullRequest()
+	assert.False(t, ok)
+}
+
+func TestPayload_GetRefType(t *testing.T) {
+	var repo = map[string]interface{}{
+		"name": "inertia", "full_name": "ubclaunchpad/inertia",
+		"clone_url": "", "ssh_url": "", "git_http_url": "", "git_ssh_url": "",
+	}
+	var bitbucketPush = func(refType string) map[string]interface{} {
+		return map[string]interface{}{
+			"repository": repo,
+			"push": map[string]interface{}{"changes": []interface{}{
+				map[string]interface{}{"new": map[string]interface{}{
+					"type": refType, "name": "v1.2.0",
+					"target": map[string]interface{}{"message": "Release v1.2.0 [skip deploy]"},
+				}},
+			}},
+		}
+	}
+	tests := []struct {
+		name        string
+		payload     Payload
+		wantRef     string
+		wantRefType RefType
+		wantMessage string
+	}{
+		{"github branch", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/heads/v1.2.0", "repository": repo,
+			"head_commit": map[string]interface{}{"message": "Release v1.2.0"},
+		}), "refs/heads/v1.2.0", BranchRef, "Release v1.2.0"},
+		{"github tag", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/tags/v1.2.0", "repository": repo,
+			"head_commit": map[string]interface{}{"message": "Release v1.2.0"},
+		}), "refs/tags/v1.2.0", TagRef, "Release v1.2.0"},
+		{"github deleted tag", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/tags/v1.2.0", "repository": repo, "head_commit": nil,
+		}), "refs/tags/v1.2.0", TagRef, ""},
+		{"gitlab tag", parseGitlabPushEvent(map[string]interface{}{
+			"ref": "refs/tags/v1.2.0", "repository": repo, "checkout_sha": "abcde",
+			"commits": []interface{}{
+				map[string]interface{}{"id": "12345", "message": "Fix things"},
+				map[string]interface{}{"id": "abcde", "message": "Release v1.2.0"},
+			},
+		}), "refs/tags/v1.2.0", TagRef, "Release v1.2.0"},
+		{"bitbucket branch", parseBitbucketPushEvent(bitbucketPush("branch")),
+			"refs/heads/v1.2.0", BranchRef, "Release v1.2.0 [skip deploy]"},
+		{"bitbucket tag", parseBitbucketPushEvent(bitbucketPush("tag")),
+			"refs/tags/v1.2.0", TagRef, "Release v1.2.0 [skip deploy]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRef, tt.payload.GetRef())
+			assert.Equal(t, tt.wantRefType, tt.payload.GetRefType())
+			assert.Equal(t, tt.wantMessage, tt.payload.GetCommitMessage())
+		})
+	}
+}
+
+func TestPayload_IsDeleted(t *testing.T) {
+	var repo = map[string]interface{}{
+		"name": "inertia", "full_name": "ubclaunchpad/inertia",
+		"clone_url": "", "ssh_url": "", "git_http_url": "", "git_ssh_url": "",
+	}
+	var bitbucketPush = func(old, new interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"repository": repo,
+			"push": map[string]interface{}{"changes": []interface{}{
+				map[string]interface{}{"old": old, "new": new},
+			}},
+		}
+	}
+	var branch = map[string]interface{}{"type": "branch", "name": "feature/wow"}
+	tests := []struct {
+		name        string
+		payload     Payload
+		wantRef     string
+		wantDeleted bool
+	}{
+		{"github push", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/heads/feature/wow", "repository": repo,
+			"deleted": false, "after": "f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa",
+		}), "refs/heads/feature/wow", false},
+		{"github deleted branch", parseGithubPushEvent(map[string]interface{}{
+			"ref": "refs/heads/feature/wow", "repository": repo, "head_commit": nil,
+			"deleted": true, "after": nullCommit,
+		}), "refs/heads/feature/wow", true},
+		{"gitlab push", parseGitlabPushEvent(map[string]interface{}{
+			"ref": "refs/tags/v1.2.0", "repository": repo,
+			"after": "f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa",
+		}), "refs/tags/v1.2.0", false},
+		{"gitlab deleted tag", parseGitlabPushEvent(map[string]interface{}{
+			"ref": "refs/tags/v1.2.0", "repository": repo, "after": nullCommit,
+			"checkout_sha": nil, "commits": []interface{}{},
+		}), "refs/tags/v1.2.0", true},
+		{"bitbucket push", parseBitbucketPushEvent(bitbucketPush(nil, branch)),
+			"refs/heads/feature/wow", false},
+		{"bitbucket deleted branch", parseBitbucketPushEvent(bitbucketPush(branch, nil)),
+			"refs/heads/feature/wow", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRef, tt.payload.GetRef())
+			assert.Equal(t, tt.wantDeleted, tt.payload.IsDeleted())
+		})
+	}
+}
+
+func TestPayload_GetCommitRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		event      string
+		body       []byte
+		wantBefore string
+		wantAfter  string
+		wantOK     bool
+	}{
+		{"github new branch", GitHub, GithubPushHeader, githubPushRawJSON,
+			"", "", false},
+		{"gitlab", GitLab, GitlabPushHeader, gitlabPushRawJSON,
+			"782fc00feb08df381c7a7d94f52d32cf46fb4065", "f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa", true},
+		{"bitbucket", BitBucket, BitbucketPushHeader, bitbucketPushRawJSON,
+			"88d0d4becc199c8c2005faebca0fe3c446c88f50", "f7da6e2506829ef3ee8e3f1a2bfae534a5ab5dfa", true},
+		{"github pull", GitHub, GithubPullHeader, githubPullRawJSON,
+			"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := Parse(tt.host, tt.event, http.Header{"Content-Type": {"application/json"}}, tt.body)
+			assert.NoError(t, err)
+			before, after, ok := payload.GetCommitRange()
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantBefore, before)
+			assert.Equal(t, tt.wantAfter, after)
+		})
+	}
 }
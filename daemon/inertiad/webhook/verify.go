@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"crypto/subtle"
 	"errors"
 	"net/http"
 
@@ -11,6 +12,10 @@ const (
 	// Signatures
 	xHubSignatureHeader = "X-Hub-Signature"
 	gitlabTokenHeader   = "X-Gitlab-Token"
+
+	// DockerSecretParam is the query parameter used to provide the webhook
+	// secret in Docker webhooks, which do not support signatures or headers
+	DockerSecretParam = "secret"
 )
 
 // Verify ensures the payload's integrity and returns and error if anything
@@ -41,3 +46,13 @@ func Verify(host, key string, h http.Header, body []byte) (err error) {
 		return errors.New("unsupported type")
 	}
 }
+
+// VerifyDocker ensures the given Docker webhook request provides the webhook
+// secret
+func VerifyDocker(key string, r *http.Request) error {
+	secret := r.URL.Query().Get(DockerSecretParam)
+	if key == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(key)) != 1 {
+		return errors.New("invalid webhook secret")
+	}
+	return nil
+}
@@ -84,3 +84,28 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyDocker(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		key     string
+		wantErr bool
+	}{
+		{"valid secret", "?secret=" + testKey, testKey, false},
+		{"invalid secret", "?secret=wrong", testKey, true},
+		{"no secret", "", testKey, true},
+		{"no key set up", "?secret=", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/webhook/registry"+tt.query, nil)
+			assert.NoError(t, err)
+			if err := VerifyDocker(tt.key, req); tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}